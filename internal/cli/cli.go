@@ -1,48 +1,281 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/projectdiscovery/goflags"
 	"github.com/saeed0xf/seesv/internal/operations"
+	"golang.org/x/term"
 )
 
+// Exit statuses of -fail-if-empty and -fail-if-found, apart from the status 1 of errors
+const (
+	ExitEmpty = 4
+	ExitFound = 5
+)
+
+// ExitError is an error that ends the program with a specific exit status
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
 // Options represents the CLI configuration
 type Options struct {
-	File       string `flag:"file" cfgFlagName:"file" description:"CSV input file (required)"`
-	Select     string `flag:"select" cfgFlagName:"select" description:"SELECT columns (comma-separated)"`
-	Where      string `flag:"where" cfgFlagName:"where" description:"WHERE condition (SQL-like)"`
-	Update     string `flag:"update" cfgFlagName:"update" description:"UPDATE column values (col1=val1,col2=val2)"`
-	Delete     bool   `flag:"delete" cfgFlagName:"delete" description:"DELETE rows matching WHERE condition"`
-	Insert     string `flag:"insert" cfgFlagName:"insert" description:"INSERT new row (col1=val1,col2=val2)"`
-	Limit      int    `flag:"limit" cfgFlagName:"limit" description:"LIMIT number of rows returned"`
-	Order      string `flag:"order" cfgFlagName:"order" description:"ORDER BY column [asc|desc]"`
-	Columns    bool   `flag:"columns" cfgFlagName:"columns" description:"Show CSV column headers"`
-	Raw        bool   `flag:"raw" cfgFlagName:"raw" description:"Show only table values without column headers"`
-	Output     string `flag:"output" cfgFlagName:"output" description:"Output file to save results"`
-	Help       bool   `flag:"h" cfgFlagName:"help" description:"Show help message"`
+	Command    string                  // Command is the optional subcommand given before the flags (e.g. create)
+	Batch      []operations.BatchQuery // Batch holds the statements from -query and -query-file with their output files
+	WorkspaceDB *operations.Workspace  // WorkspaceDB is the open -workspace database, nil without one
+	TableFiles map[string]string       // TableFiles maps the -table names to their files
+	OutputTable string                 // OutputTable is the bare -table name that -format sqlite writes
+	ColumnWidths []int                 // ColumnWidths are the character widths of the -widths columns
+	Spooled    string                  // Spooled names the stdin or URL input that -file is a temporary copy of, which must not be rewritten
+	File       string                  `flag:"file" cfgFlagName:"file" description:"CSV input file or pattern, http(s) URL, s3:// or gs:// object (- reads stdin)"`
+	Query      goflags.StringSlice     `flag:"query" cfgFlagName:"query" description:"Full SQL SELECT statement (repeatable)"`
+	QueryFile  string                  `flag:"query-file" cfgFlagName:"query-file" description:"File of SQL statements separated by semicolons"`
+	Select     string                  `flag:"select" cfgFlagName:"select" description:"SELECT columns (comma-separated)"`
+	Where      string                  `flag:"where" cfgFlagName:"where" description:"WHERE condition (SQL-like)"`
+	Update     string                  `flag:"update" cfgFlagName:"update" description:"UPDATE column values (col1=val1,col2=val2)"`
+	Delete     bool                    `flag:"delete" cfgFlagName:"delete" description:"DELETE rows matching WHERE condition"`
+	Truncate   bool                    `flag:"truncate" cfgFlagName:"truncate" description:"DELETE every row of the file, keeping the header; requires -yes"`
+	Yes        bool                    `flag:"yes" cfgFlagName:"yes" description:"Confirm -truncate"`
+	DeleteRows string                  `flag:"delete-rows" cfgFlagName:"delete-rows" description:"DELETE rows by number and range (5,10,100-200), row 1 being the first after the header"`
+	Insert     goflags.StringSlice     `flag:"insert" cfgFlagName:"insert" description:"INSERT new rows (col1=val1,col2=val2;col1=val3,...), repeatable"`
+	InsertFrom string                  `flag:"insert-from" cfgFlagName:"insert-from" description:"INSERT the rows of another CSV file (columns matched by name or -map)"`
+	Map        string                  `flag:"map" cfgFlagName:"map" description:"Map source columns to target columns for -insert-from (src1:dst1,src2:dst2)"`
+	Coerce     string                  `flag:"coerce" cfgFlagName:"coerce" description:"Cells of -insert-from that do not fit the column type: strict, lossy or skip-row"`
+	IfAbsent   string                  `flag:"insert-if-absent" cfgFlagName:"insert-if-absent" description:"Key columns: -insert skips rows whose key values are already present"`
+	Upsert     string                  `flag:"upsert" cfgFlagName:"upsert" description:"Update the row matching the -key columns, or insert it (col1=val1,col2=val2)"`
+	JSONSum    bool                    `flag:"json-summary" cfgFlagName:"json-summary" description:"Print the outcome of INSERT, UPSERT, UPDATE, DELETE or COPY as a JSON object"`
+	Returning  bool                    `flag:"returning" cfgFlagName:"returning" description:"Print the rows DELETE removes or UPDATE changes (to -output if given)"`
+	Backup     bool                    `flag:"backup" cfgFlagName:"backup" description:"Copy the file to <file>.bak before INSERT, UPSERT, UPDATE, DELETE or COPY"`
+	Limit      int                     `flag:"limit" cfgFlagName:"limit" description:"LIMIT number of rows returned"`
+	Head       int                     `flag:"head" cfgFlagName:"head" description:"Load only the first N rows of the file"`
+	Tail       int                     `flag:"tail" cfgFlagName:"tail" description:"Load only the last N rows of the file"`
+	Lines      string                  `flag:"lines" cfgFlagName:"lines" description:"Print raw physical lines from-to with the header, without parsing"`
+	Group      string                  `flag:"group" cfgFlagName:"group" description:"GROUP BY columns (comma-separated)"`
+	Having     string                  `flag:"having" cfgFlagName:"having" description:"HAVING condition on grouped results"`
+	Order      string                  `flag:"order" cfgFlagName:"order" description:"ORDER BY col1 [asc|desc],col2 ..."`
+	PerGroup   int                     `flag:"top-per-group" cfgFlagName:"top-per-group" description:"Keep the first N rows of each -group in -order order"`
+	Sort       string                  `flag:"sort" cfgFlagName:"sort" description:"Sort the file in place by columns (col1 desc,col2 asc), requires -write"`
+	Reverse    bool                    `flag:"reverse" cfgFlagName:"reverse" description:"Reverse the row order of the file, requires -write"`
+	Rotate     int                     `flag:"rotate" cfgFlagName:"rotate" description:"Move the first N rows of the file to the end, requires -write"`
+	Reorder    string                  `flag:"reorder" cfgFlagName:"reorder" description:"Move these columns to the front of the output, or of the file with -write"`
+	Write      bool                    `flag:"write" cfgFlagName:"write" description:"Confirm rewriting the input file (used with -sort, -reverse, -rotate, -reorder)"`
+	FailEmpty  bool                    `flag:"fail-if-empty" cfgFlagName:"fail-if-empty" description:"Exit with status 4 when the query returns no rows"`
+	FailFound  bool                    `flag:"fail-if-found" cfgFlagName:"fail-if-found" description:"Exit with status 5 when the query returns any rows"`
+	Count      bool                    `flag:"count" cfgFlagName:"count" description:"Print only the number of matching rows"`
+	Columns    bool                    `flag:"columns" cfgFlagName:"columns" description:"Show CSV column headers"`
+	Describe   bool                    `flag:"describe" cfgFlagName:"describe" description:"Show column types, descriptions and tags"`
+	Mixed      bool                    `flag:"detect-mixed" cfgFlagName:"detect-mixed" description:"Report columns read as text because of a few values of another type"`
+	ForceType  string                  `flag:"force-type" cfgFlagName:"force-type" description:"Read these columns as the given type (col:int|float|string|bool,...)"`
+	Normalize  bool                    `flag:"normalize-headers" cfgFlagName:"normalize-headers" description:"Trim, lowercase and underscore header names so they can be referenced"`
+	Rename     string                  `flag:"rename" cfgFlagName:"rename" description:"Rename columns when the file is read (old1=new1,old2=new2)"`
+	Allowed    string                  `flag:"allowed" cfgFlagName:"allowed" description:"Only let INSERT and UPDATE write these values (col:v1|v2,...); annotate records them"`
+	Col        string                  `flag:"col" cfgFlagName:"col" description:"Column to annotate"`
+	Desc       string                  `flag:"desc" cfgFlagName:"desc" description:"Column description for annotate"`
+	Tags       string                  `flag:"tags" cfgFlagName:"tags" description:"Comma-separated column tags for annotate"`
+	Raw        bool                    `flag:"raw" cfgFlagName:"raw" description:"Show only table values without column headers"`
+	Sign       string                  `flag:"sign" cfgFlagName:"sign" description:"Sign the -output file with a PEM private key (writes .manifest.json and .sig)"`
+	Key        string                  `flag:"key" cfgFlagName:"key" description:"Key columns for -upsert (id or program,id); PEM public key for verify-bundle"`
+	Output     string                  `flag:"output" cfgFlagName:"output" description:"Output file to save results"`
+	NullString string                  `flag:"null-string" cfgFlagName:"null-string" description:"Text written for missing values in results, e.g. NULL (default: empty)"`
+	EmptyAsNull bool                   `flag:"empty-as-null" cfgFlagName:"empty-as-null" description:"Write empty values of results as -null-string too"`
+	Quote      string                  `flag:"quote" cfgFlagName:"quote" description:"Quoting of -raw output and -output files: minimal (default), always or never"`
+	CRLF       bool                    `flag:"crlf" cfgFlagName:"crlf" description:"End the lines of written files and -raw output with CRLF (rewrites otherwise keep the file's line endings)"`
+	Compress   bool                    `flag:"compress" cfgFlagName:"compress" description:"Gzip the -output file (also done for names ending in .gz; .zst uses zstd)"`
+	OnlyCols   string                  `flag:"only-cols" cfgFlagName:"only-cols" description:"Show only these output columns (comma-separated)"`
+	HideCols   string                  `flag:"hide-cols" cfgFlagName:"hide-cols" description:"Hide these output columns (comma-separated)"`
+	Format     string                  `flag:"format" cfgFlagName:"format" description:"Output layout: table, record, sqlite (-output database) or template"`
+	Vertical   bool                    `flag:"vertical" cfgFlagName:"vertical" description:"Print each row as a block of column: value lines (same as -format record)"`
+	Template   string                  `flag:"template" cfgFlagName:"template" description:"Go template that -format template renders each row through, or a file holding one"`
+	TableStyle string                  `flag:"table-style" cfgFlagName:"table-style" description:"Table borders: ascii, light, heavy, double, compact or borderless"`
+	MaxColWidth int                    `flag:"max-col-width" cfgFlagName:"max-col-width" description:"Widest a table column may be; longer values are cut short, or wrapped with -wrap"`
+	Wrap       bool                    `flag:"wrap" cfgFlagName:"wrap" description:"Wrap table cells wider than -max-col-width, and cells with line breaks, onto several lines"`
+	Totals     bool                    `flag:"totals" cfgFlagName:"totals" description:"Add a footer row to table output: the sum of each number column, the count of values in the others"`
+	NoColor    bool                    `flag:"no-color" cfgFlagName:"no-color" description:"Print tables without colors, which are used when stdout is a terminal"`
+	Wide       bool                    `flag:"wide" cfgFlagName:"wide" description:"Show records vertically when the table is wider than the terminal"`
+	Humanize   string                  `flag:"humanize" cfgFlagName:"humanize" description:"Abbreviate numbers in these columns in table output (col[:si|bytes],...)"`
+	Delimiter  string                  `flag:"delimiter" cfgFlagName:"delimiter" description:"Input delimiter (default: auto-detect)"`
+	TSV        bool                    `flag:"tsv" cfgFlagName:"tsv" description:"Read the input as tab separated (same as -delimiter tab)"`
+	OutDelimiter string                `flag:"out-delimiter" cfgFlagName:"out-delimiter" description:"Delimiter of -raw output and -output files, e.g. tab or pipe (default: comma)"`
+	AddSource  bool                    `flag:"add-source-file" cfgFlagName:"add-source-file" description:"Add a _source_file column naming the file of each row of a -file pattern"`
+	HTTPHeader goflags.StringSlice     `flag:"http-header" cfgFlagName:"http-header" description:"Header sent when -file is a URL (\"Authorization: Bearer ...\"), repeatable"`
+	HTTPTimeout time.Duration          `flag:"http-timeout" cfgFlagName:"http-timeout" description:"Give up downloading a -file URL or object after this long (default 5m)"`
+	MaxDownload goflags.Size           `flag:"max-download" cfgFlagName:"max-download" description:"Fail if a -file URL or object is larger than this, e.g. 500mb"`
+	SkipRows   int                     `flag:"skip-rows" cfgFlagName:"skip-rows" description:"Skip this many lines, such as a banner, at the start of the input"`
+	Comment    string                  `flag:"comment" cfgFlagName:"comment" description:"Skip input lines starting with this prefix, e.g. #"`
+	NoHeader   bool                    `flag:"no-header" cfgFlagName:"no-header" description:"Read the first row as data; columns are named c1,c2,... or by -names"`
+	QuoteChar  string                  `flag:"quote-char" cfgFlagName:"quote-char" description:"Quote character of the input, or none (detected by default)"`
+	EscapeChar string                  `flag:"escape-char" cfgFlagName:"escape-char" description:"Character that makes the next one literal, such as a backslash (quotes are doubled by default)"`
+	OnBadRows  string                  `flag:"on-bad-rows" cfgFlagName:"on-bad-rows" description:"Rows with the wrong number of fields: error (default), skip, pad or report"`
+	LazyQuotes bool                    `flag:"lazy-quotes" cfgFlagName:"lazy-quotes" description:"Read stray and unterminated quotes as text instead of failing"`
+	Encoding   string                  `flag:"encoding" cfgFlagName:"encoding" description:"Character encoding of the input: utf-8, utf-16, latin1, windows-1252, ... (detected by default)"`
+	Widths     string                  `flag:"widths" cfgFlagName:"widths" description:"Read fixed-width columns of these widths (10,4,20) or from a spec file of \"name width\" lines"`
+	Names      string                  `flag:"names" cfgFlagName:"names" description:"Column names of a file without a header row (implies -no-header)"`
+	NoSniff    bool                    `flag:"no-sniff" cfgFlagName:"no-sniff" description:"Disable delimiter/quote/header/encoding detection"`
+	AddColumn  string                  `flag:"add-column" cfgFlagName:"add-column" description:"Append a column to the file (name=default or name:expression, e.g. row_hash:sha256(*))"`
+	Verify     string                  `flag:"verify-hashes" cfgFlagName:"verify-hashes" description:"Check a hash column against the other columns (name[:expression])"`
+	CopyColumn string                  `flag:"copy-column" cfgFlagName:"copy-column" description:"COPY a column from another file (src.csv:col -> dst.csv:col)"`
+	On         string                  `flag:"on" cfgFlagName:"on" description:"Key column used to match rows between files (also accepted for the -upsert key)"`
+	Join       string                  `flag:"join" cfgFlagName:"join" description:"File whose -on keys filter the input rows (see -join-type)"`
+	JoinType   string                  `flag:"join-type" cfgFlagName:"join-type" description:"semi keeps rows with a match in -join, anti rows without one"`
+	Intersect  string                  `flag:"intersect" cfgFlagName:"intersect" description:"Keep distinct rows that also appear in this file"`
+	Except     string                  `flag:"except" cfgFlagName:"except" description:"Keep distinct rows that do not appear in this file"`
+	Migrations string                  `flag:"migrations" cfgFlagName:"migrations" description:"Directory of *.sql migration files for migrate"`
+	Against    string                  `flag:"against" cfgFlagName:"against" description:"Newer version of -file to compare with diff"`
+	License    string                  `flag:"license" cfgFlagName:"license" description:"SPDX license identifier recorded by package (e.g. CC-BY-4.0)"`
+	Header     string                  `flag:"header" cfgFlagName:"header" description:"Column names for an empty file (col1,col2,...)"`
+	Create     string                  `flag:"create" cfgFlagName:"create" description:"Create -file with these columns and optional types (id:int,name:string,...)"`
+	Locale     string                  `flag:"locale" cfgFlagName:"locale" description:"Number and date parsing profile (e.g. de-DE)"`
+	DateFormat string                  `flag:"date-formats" cfgFlagName:"date-formats" description:"Extra accepted date formats (e.g. DD.MM.YYYY,MM/DD/YYYY HH:mm)"`
+	Seed       int                     `flag:"seed" cfgFlagName:"seed" description:"Seed for RANDOM() and RANDOM_PICK() (reproducible output)"`
+	Hint       string                  `flag:"hint" cfgFlagName:"hint" description:"Strategy overrides (stream, no-stream; no-index, hash-join are accepted no-ops)"`
+	Workspace  string                  `flag:"workspace" cfgFlagName:"workspace" description:"SQLite database keeping -save-as results across runs; source files are only stored by -save-as"`
+	Tables     goflags.StringSlice     `flag:"table" cfgFlagName:"table" description:"Name a CSV file for FROM and JOIN in queries (name=path.csv), repeatable; a bare name is the -format sqlite table"`
+	SaveAs     string                  `flag:"save-as" cfgFlagName:"save-as" description:"Store the result as a table of -workspace"`
+	Check      bool                    `flag:"check" cfgFlagName:"check" description:"With fmt, report whether the file is canonical without rewriting it"`
+	Timeout    time.Duration           `flag:"timeout" cfgFlagName:"timeout" description:"Fail if the operation takes longer than this (e.g. 30s)"`
+	ShowVer    bool                    `flag:"version" cfgFlagName:"version" description:"Print version and build metadata as JSON"`
+	MaxScan    int                     `flag:"max-scan-rows" cfgFlagName:"max-scan-rows" description:"Fail if the input has more data rows than this"`
+	Estimate   bool                    `flag:"estimate" cfgFlagName:"estimate" description:"Print the estimated rows and bytes the query scans, without running it"`
+	Force      bool                    `flag:"force" cfgFlagName:"force" description:"Read inputs larger than 4 GiB in full"`
+	Downgrade  bool                    `flag:"allow-downgrade" cfgFlagName:"allow-downgrade" description:"Let self-update install a release that is not newer than this binary"`
+	Help       bool                    `flag:"h" cfgFlagName:"help" description:"Show help message"`
 }
 
 // Execute runs the CLI application
-func Execute() error {
+func Execute() (err error) {
 	opts := &Options{}
+
+	// A leading non-flag argument selects a subcommand
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		opts.Command = os.Args[1]
+		os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	}
 	
 	flagSet := goflags.NewFlagSet()
 	flagSet.SetDescription("")
 	
 	// Create flags with single dash - no groups for cleaner help
 	flagSet.StringVarP(&opts.File, "file", "f", "", "")
+	flagSet.StringSliceVar(&opts.Query, "query", nil, "", goflags.StringSliceOptions)
+	flagSet.StringVar(&opts.QueryFile, "query-file", "", "")
 	flagSet.StringVar(&opts.Select, "select", "", "")
 	flagSet.StringVar(&opts.Where, "where", "", "")
 	flagSet.StringVar(&opts.Update, "update", "", "")
 	flagSet.BoolVar(&opts.Delete, "delete", false, "")
-	flagSet.StringVar(&opts.Insert, "insert", "", "")
+	flagSet.StringVar(&opts.DeleteRows, "delete-rows", "", "")
+	flagSet.BoolVar(&opts.Truncate, "truncate", false, "")
+	flagSet.BoolVar(&opts.Yes, "yes", false, "")
+	flagSet.StringSliceVar(&opts.Insert, "insert", nil, "", goflags.StringSliceOptions)
+	flagSet.StringVar(&opts.InsertFrom, "insert-from", "", "")
+	flagSet.StringVar(&opts.Map, "map", "", "")
+	flagSet.StringVar(&opts.Coerce, "coerce", "", "")
+	flagSet.StringVar(&opts.IfAbsent, "insert-if-absent", "", "")
+	flagSet.StringVar(&opts.Upsert, "upsert", "", "")
+	flagSet.BoolVar(&opts.JSONSum, "json-summary", false, "")
+	flagSet.BoolVar(&opts.Backup, "backup", false, "")
+	flagSet.BoolVar(&opts.Returning, "returning", false, "")
 	flagSet.IntVar(&opts.Limit, "limit", 0, "")
+	flagSet.IntVar(&opts.Head, "head", 0, "")
+	flagSet.IntVar(&opts.Tail, "tail", 0, "")
+	flagSet.StringVar(&opts.Lines, "lines", "", "")
+	flagSet.StringVar(&opts.Group, "group", "", "")
+	flagSet.StringVar(&opts.Having, "having", "", "")
 	flagSet.StringVar(&opts.Order, "order", "", "")
+	flagSet.IntVar(&opts.PerGroup, "top-per-group", 0, "")
+	flagSet.StringVar(&opts.Sort, "sort", "", "")
+	flagSet.BoolVar(&opts.Reverse, "reverse", false, "")
+	flagSet.IntVar(&opts.Rotate, "rotate", 0, "")
+	flagSet.StringVar(&opts.Reorder, "reorder", "", "")
+	flagSet.BoolVar(&opts.Write, "write", false, "")
+	flagSet.BoolVar(&opts.FailEmpty, "fail-if-empty", false, "")
+	flagSet.BoolVar(&opts.FailFound, "fail-if-found", false, "")
+	flagSet.BoolVar(&opts.Count, "count", false, "")
 	flagSet.BoolVar(&opts.Columns, "columns", false, "")
+	flagSet.BoolVar(&opts.Describe, "describe", false, "")
+	flagSet.BoolVar(&opts.Mixed, "detect-mixed", false, "")
+	flagSet.StringVar(&opts.ForceType, "force-type", "", "")
+	flagSet.BoolVar(&opts.Normalize, "normalize-headers", false, "")
+	flagSet.StringVar(&opts.Rename, "rename", "", "")
+	flagSet.StringVar(&opts.Allowed, "allowed", "", "")
+	flagSet.StringVar(&opts.Col, "col", "", "")
+	flagSet.StringVar(&opts.Desc, "desc", "", "")
+	flagSet.StringVar(&opts.Tags, "tags", "", "")
 	flagSet.BoolVar(&opts.Raw, "raw", false, "")
 	flagSet.StringVarP(&opts.Output, "output", "o", "", "")
+	flagSet.BoolVar(&opts.Compress, "compress", false, "")
+	flagSet.BoolVar(&opts.CRLF, "crlf", false, "")
+	flagSet.StringVar(&opts.Quote, "quote", "", "")
+	flagSet.StringVar(&opts.NullString, "null-string", "", "")
+	flagSet.BoolVar(&opts.EmptyAsNull, "empty-as-null", false, "")
+	flagSet.StringVar(&opts.Sign, "sign", "", "")
+	flagSet.StringVar(&opts.Key, "key", "", "")
+	flagSet.StringVar(&opts.Humanize, "humanize", "", "")
+	flagSet.StringVar(&opts.OnlyCols, "only-cols", "", "")
+	flagSet.StringVar(&opts.HideCols, "hide-cols", "", "")
+	flagSet.StringVar(&opts.Format, "format", "", "")
+	flagSet.StringVar(&opts.Template, "template", "", "")
+	flagSet.BoolVar(&opts.Vertical, "vertical", false, "")
+	flagSet.StringVar(&opts.TableStyle, "table-style", "", "")
+	flagSet.IntVar(&opts.MaxColWidth, "max-col-width", 0, "")
+	flagSet.BoolVar(&opts.Wrap, "wrap", false, "")
+	flagSet.BoolVar(&opts.Totals, "totals", false, "")
+	flagSet.BoolVar(&opts.NoColor, "no-color", false, "")
+	flagSet.BoolVar(&opts.Wide, "wide", false, "")
+	flagSet.StringVar(&opts.Delimiter, "delimiter", "", "")
+	flagSet.BoolVar(&opts.TSV, "tsv", false, "")
+	flagSet.StringVar(&opts.OutDelimiter, "out-delimiter", "", "")
+	flagSet.BoolVar(&opts.AddSource, "add-source-file", false, "")
+	flagSet.StringSliceVar(&opts.HTTPHeader, "http-header", nil, "", goflags.StringSliceOptions)
+	flagSet.DurationVar(&opts.HTTPTimeout, "http-timeout", 5*time.Minute, "")
+	flagSet.SizeVar(&opts.MaxDownload, "max-download", "", "")
+	flagSet.IntVar(&opts.SkipRows, "skip-rows", 0, "")
+	flagSet.StringVar(&opts.Comment, "comment", "", "")
+	flagSet.BoolVar(&opts.NoHeader, "no-header", false, "")
+	flagSet.StringVar(&opts.QuoteChar, "quote-char", "", "")
+	flagSet.StringVar(&opts.EscapeChar, "escape-char", "", "")
+	flagSet.BoolVar(&opts.LazyQuotes, "lazy-quotes", false, "")
+	flagSet.StringVar(&opts.OnBadRows, "on-bad-rows", "", "")
+	flagSet.StringVar(&opts.Encoding, "encoding", "", "")
+	flagSet.StringVar(&opts.Widths, "widths", "", "")
+	flagSet.StringVar(&opts.Names, "names", "", "")
+	flagSet.BoolVar(&opts.NoSniff, "no-sniff", false, "")
+	flagSet.StringVar(&opts.Header, "header", "", "")
+	flagSet.StringVar(&opts.Create, "create", "", "")
+	flagSet.StringVar(&opts.Locale, "locale", "", "")
+	flagSet.StringVar(&opts.AddColumn, "add-column", "", "")
+	flagSet.StringVar(&opts.Verify, "verify-hashes", "", "")
+	flagSet.StringVar(&opts.CopyColumn, "copy-column", "", "")
+	flagSet.StringVar(&opts.On, "on", "", "")
+	flagSet.StringVar(&opts.Join, "join", "", "")
+	flagSet.StringVar(&opts.JoinType, "join-type", "", "")
+	flagSet.StringVar(&opts.Intersect, "intersect", "", "")
+	flagSet.StringVar(&opts.Except, "except", "", "")
+	flagSet.StringVarP(&opts.Migrations, "migrations", "m", "", "")
+	flagSet.StringVar(&opts.Against, "against", "", "")
+	flagSet.StringVar(&opts.License, "license", "", "")
+	flagSet.StringVar(&opts.DateFormat, "date-formats", "", "")
+	flagSet.IntVar(&opts.Seed, "seed", 0, "")
+	flagSet.StringVar(&opts.Hint, "hint", "", "")
+	flagSet.StringVar(&opts.Workspace, "workspace", "", "")
+	flagSet.StringSliceVar(&opts.Tables, "table", nil, "", goflags.StringSliceOptions)
+	flagSet.StringVar(&opts.SaveAs, "save-as", "", "")
+	flagSet.BoolVar(&opts.Check, "check", false, "")
+	flagSet.DurationVar(&opts.Timeout, "timeout", 0, "")
+	flagSet.IntVar(&opts.MaxScan, "max-scan-rows", 0, "")
+	flagSet.BoolVar(&opts.Estimate, "estimate", false, "")
+	flagSet.BoolVar(&opts.Force, "force", false, "")
+	flagSet.BoolVar(&opts.Downgrade, "allow-downgrade", false, "")
+	flagSet.BoolVar(&opts.ShowVer, "version", false, "")
 	flagSet.BoolVarP(&opts.Help, "help", "h", false, "")
 
 	// Parse flags
@@ -51,7 +284,7 @@ func Execute() error {
 	}
 
 	// Check if no arguments were provided (just the command name)
-	if len(os.Args) == 1 {
+	if len(os.Args) == 1 && opts.Command == "" {
 		ShowUsage(flagSet)
 		return nil
 	}
@@ -62,14 +295,409 @@ func Execute() error {
 		return nil
 	}
 
-	// Validate required flags
-	if opts.File == "" {
+	// version and self-update concern the binary itself, not a file
+	switch {
+	case opts.ShowVer || opts.Command == "version":
+		return printVersion()
+	case opts.Command == "self-update":
+		return selfUpdate(opts.Downgrade)
+	}
+
+	// -create is the create command with its schema given inline
+	if opts.Create != "" {
+		if (opts.Command != "" && opts.Command != "create") || opts.Header != "" {
+			return fmt.Errorf("-create cannot be combined with -header or another command")
+		}
+		opts.Command, opts.Header = "create", opts.Create
+	}
+
+	// -tsv is shorthand for a tab delimiter
+	if opts.TSV {
+		if opts.Delimiter != "" {
+			return fmt.Errorf("-tsv sets the delimiter to tab and cannot be combined with -delimiter")
+		}
+		opts.Delimiter = "tab"
+	}
+
+	if opts.Encoding != "" {
+		if _, err := operations.ParseEncoding(opts.Encoding); err != nil {
+			return err
+		}
+	}
+	if _, err := operations.ParseBadRowsMode(opts.OnBadRows); err != nil {
+		return err
+	}
+	for _, char := range []string{opts.QuoteChar, opts.EscapeChar} {
+		if char != "" {
+			if _, err := operations.ParseQuoteChar(char); err != nil {
+				return err
+			}
+		}
+	}
+
+	// -widths reads fixed-width columns, named by the spec file when it lists names
+	if opts.Widths != "" {
+		if opts.Delimiter != "" {
+			return fmt.Errorf("-widths splits lines by position and cannot be combined with -delimiter or -tsv")
+		}
+		widths, names, err := operations.ParseWidths(opts.Widths)
+		if err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			if opts.Names != "" {
+				return fmt.Errorf("-names cannot rename columns that the -widths file %s already names", opts.Widths)
+			}
+			opts.Names = strings.Join(names, ",")
+		}
+		opts.ColumnWidths = widths
+	}
+
+	// A copy specification may name the destination file itself
+	if opts.File == "" && opts.CopyColumn != "" {
+		if spec, err := operations.ParseCopyColumnSpec(opts.CopyColumn); err == nil {
+			opts.File = spec.DestFile
+		}
+	}
+
+	// -table names files for FROM and JOIN, ahead of workspace tables and files
+	tables, outputTable, err := operations.ParseTableFiles(opts.Tables)
+	if err != nil {
+		return err
+	}
+	opts.TableFiles = tables
+	opts.OutputTable = outputTable
+
+	// Workspace tables are found by FROM and -file, and -save-as writes the result into one
+	if opts.Workspace != "" {
+		ws, err := operations.OpenWorkspace(opts.Workspace)
+		if err != nil {
+			return err
+		}
+		opts.WorkspaceDB = ws
+		// Tables changed by this run are stored back, even when it fails after a write
+		defer func() {
+			if closeErr := ws.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		if opts.File != "" {
+			if _, err := os.Stat(opts.File); os.IsNotExist(err) {
+				if path, err := ws.ResolveTablePath(opts.File); err == nil {
+					opts.File = path
+				}
+			}
+		}
+	}
+	if opts.Compress && (opts.Output == "" || opts.SaveAs != "") {
+		return fmt.Errorf("-compress applies to the -output file and cannot be used without one or with -save-as")
+	}
+	if opts.SaveAs != "" {
+		if opts.Output != "" || opts.Raw {
+			return fmt.Errorf("-save-as writes the result with its header and cannot be used with -output or -raw")
+		}
+		if style, _ := operations.ParseQuoteStyle(opts.Quote); opts.OutDelimiter != "" || opts.Template != "" || style == operations.QuoteNever {
+			return fmt.Errorf("-save-as stores the result as plain CSV and cannot be used with -out-delimiter, -quote never or -template")
+		}
+		path, err := opts.WorkspaceDB.TablePath(opts.SaveAs)
+		if err != nil {
+			return err
+		}
+		opts.Output = path
+	}
+
+	batch, err := batchQueries(opts)
+	if err != nil {
+		return err
+	}
+	opts.Batch = batch
+	if opts.SaveAs != "" && len(batch) > 1 {
+		return fmt.Errorf("-save-as stores a single result and cannot be used with several queries")
+	}
+
+	// A query may name its input file in the FROM clause
+	if opts.File == "" && len(opts.Batch) > 0 {
+		_, stmt, err := operations.ParseQuery(opts.Batch[0].SQL)
+		if err != nil {
+			return fmt.Errorf("query error: %v", err)
+		}
+		if stmt.From.Name != "" {
+			path, err := operations.ResolveTable(opts.TableFiles, opts.WorkspaceDB, stmt.From.Name)
+			if err != nil {
+				return err
+			}
+			opts.File = path
+		}
+	}
+
+	// -file -, or no -file at the end of a pipe, reads the input from stdin
+	if opts.File == "-" || (opts.File == "" && opts.Command == "" && !term.IsTerminal(int(os.Stdin.Fd()))) {
+		path, err := operations.SpoolInput(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %v", err)
+		}
+		defer os.Remove(path)
+		// Nothing piped in, as when run from a script without a terminal, still needs -file
+		if info, err := os.Stat(path); opts.File == "-" || (err == nil && info.Size() > 0) {
+			opts.File, opts.Spooled = path, "stdin"
+		}
+	}
+
+	// HTTP(S) URLs and S3 or Cloud Storage objects are downloaded first and then read like a file
+	if operations.IsURL(opts.File) || operations.IsObjectURL(opts.File) {
+		remote := operations.RemoteOptions{
+			Timeout:  opts.HTTPTimeout,
+			MaxBytes: int64(opts.MaxDownload),
+			Headers:  opts.HTTPHeader,
+		}
+		fetch := operations.FetchURL
+		if operations.IsObjectURL(opts.File) {
+			fetch = operations.FetchObject
+		}
+		path, err := fetch(opts.File, remote)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		opts.File, opts.Spooled = path, opts.File
+	}
+	// A file pattern reads every matching file as one table; the combined copy is plain CSV
+	// with a header, so the options that described the parts are not applied to it again
+	if operations.IsGlob(opts.File) {
+		parts := &operations.CSVOperations{
+			Delimiter:  opts.Delimiter,
+			NoSniff:    opts.NoSniff,
+			NoHeader:   opts.NoHeader,
+			SkipRows:   opts.SkipRows,
+			Comment:    opts.Comment,
+			Widths:     opts.ColumnWidths,
+			Encoding:   opts.Encoding,
+			QuoteChar:  opts.QuoteChar,
+			EscapeChar: opts.EscapeChar,
+			LazyQuotes: opts.LazyQuotes,
+			OnBadRows:  opts.OnBadRows,
+		}
+		if opts.Names != "" {
+			parts.Names = parts.ParseColumns(opts.Names)
+		}
+		path, err := parts.ConcatFiles(opts.File, opts.AddSource)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		opts.File, opts.Spooled = path, opts.File
+		opts.Delimiter, opts.NoSniff, opts.NoHeader, opts.Names, opts.SkipRows, opts.Comment = "", false, false, "", 0, ""
+		opts.ColumnWidths, opts.Encoding = nil, ""
+		opts.QuoteChar, opts.EscapeChar, opts.LazyQuotes, opts.OnBadRows = "", "", false, ""
+	} else if opts.AddSource {
+		return fmt.Errorf("-add-source-file applies to a -file pattern such as \"logs/*.csv\"")
+	}
+	if opts.Spooled != "" {
+		switch opts.Command {
+		case "create", "annotate", "fmt", "migrate":
+			return fmt.Errorf("%s changes the input file and cannot read %s", opts.Command, opts.Spooled)
+		}
+	}
+
+	// Validate required flags (tables lists the workspace and reads no file)
+	if opts.File == "" && opts.Command != "tables" {
 		ShowUsage(flagSet)
 		fmt.Fprintln(os.Stderr, "missing required flag: -file")
 		os.Exit(1)
 	}
 
-	return runSeeCSV(opts)
+	if opts.Timeout < 0 || opts.MaxScan < 0 || opts.SkipRows < 0 {
+		return fmt.Errorf("-timeout, -max-scan-rows and -skip-rows must not be negative")
+	}
+	return withTimeout(opts.Timeout, func() error { return run(opts) })
+}
+
+// withTimeout runs fn and gives up with an error once timeout has passed (no limit when 0).
+// A write already under way is allowed to finish, and no further writes start.
+func withTimeout(timeout time.Duration, fn func() error) error {
+	if timeout == 0 {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		operations.StopWrites()
+		return fmt.Errorf("operation timed out after %s (-timeout)", timeout)
+	}
+}
+
+// run executes the subcommand or the flag-driven operation, then signs the export if asked
+func run(opts *Options) error {
+	if opts.Command != "" {
+		return runCommand(opts)
+	}
+
+	if opts.Sign != "" && opts.Output == "" {
+		return fmt.Errorf("-sign signs the exported file and requires -output")
+	}
+	if opts.Sign != "" && len(opts.Batch) > 1 {
+		return fmt.Errorf("-sign signs a single exported file and cannot be used with several queries")
+	}
+	if opts.Sign != "" {
+		if err := signableOutput(opts); err != nil {
+			return err
+		}
+	}
+	if err := runSeeCSV(opts); err != nil {
+		return err
+	}
+
+	// Sign the export once it has been written
+	if opts.Sign != "" {
+		return operations.SignBundle(opts.Output, !opts.Raw, opts.Sign)
+	}
+	return nil
+}
+
+// signableOutput refuses -sign for exports whose rows verify-bundle cannot count, which reads
+// the file as plain comma separated CSV. It runs before the query, so nothing is written.
+func signableOutput(opts *Options) error {
+	format, _ := operations.ParseOutputFormat(opts.Format)
+	name := strings.ToLower(opts.Output)
+	switch {
+	case format == operations.FormatSQLite || format == operations.FormatTemplate:
+		return fmt.Errorf("-sign signs CSV exports and cannot be used with -format %s", format)
+	case opts.Compress || strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".zst"):
+		return fmt.Errorf("-sign signs CSV exports and cannot be used with a compressed -output; compress the signed file afterwards")
+	case strings.HasSuffix(name, ".har") || strings.HasSuffix(name, ".xml"):
+		return fmt.Errorf("-sign signs CSV exports and cannot be used with a HAR or Burp XML -output")
+	}
+	if delim, err := operations.ParseDelimiter(opts.OutDelimiter); opts.OutDelimiter != "" && (err != nil || delim != ',') {
+		return fmt.Errorf("-sign signs comma separated exports and cannot be used with -out-delimiter %s", opts.OutDelimiter)
+	}
+	if style, _ := operations.ParseQuoteStyle(opts.Quote); style == operations.QuoteNever {
+		return fmt.Errorf("-sign signs CSV exports and cannot be used with -quote never, whose rows cannot be counted reliably")
+	}
+	return nil
+}
+
+// batchQueries collects the statements of -query and -query-file. With several -query
+// statements, -output lists one target per query, separated by commas (empty for stdout).
+func batchQueries(opts *Options) ([]operations.BatchQuery, error) {
+	var batch []operations.BatchQuery
+	switch {
+	case len(opts.Query) == 1:
+		batch = append(batch, operations.BatchQuery{SQL: opts.Query[0], Output: opts.Output})
+	case len(opts.Query) > 1:
+		outputs := make([]string, len(opts.Query))
+		if opts.Output != "" {
+			outputs = strings.Split(opts.Output, ",")
+			if len(outputs) != len(opts.Query) {
+				return nil, fmt.Errorf("-output lists %d files for %d queries; give one per query, separated by commas", len(outputs), len(opts.Query))
+			}
+		}
+		for i, query := range opts.Query {
+			batch = append(batch, operations.BatchQuery{SQL: query, Output: strings.TrimSpace(outputs[i])})
+		}
+	}
+
+	if opts.QueryFile != "" {
+		queries, err := operations.ParseQueryFile(opts.QueryFile)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, queries...)
+	}
+	return batch, nil
+}
+
+// runCommand dispatches subcommands such as "create"
+func runCommand(opts *Options) error {
+	ops := &operations.CSVOperations{
+		FilePath: opts.File,
+		Delimiter: opts.Delimiter,
+		NoSniff: opts.NoSniff,
+		NoHeader: opts.NoHeader,
+		Widths: opts.ColumnWidths,
+		Encoding: opts.Encoding,
+		QuoteChar: opts.QuoteChar,
+		EscapeChar: opts.EscapeChar,
+		LazyQuotes: opts.LazyQuotes,
+		OnBadRows: opts.OnBadRows,
+		SkipRows: opts.SkipRows,
+		Comment: opts.Comment,
+		Locale: opts.Locale,
+		RawOutput: opts.Raw,
+		OutputFile: opts.Output,
+		Compress: opts.Compress,
+		CRLF: opts.CRLF,
+		MaxScanRows: opts.MaxScan,
+		Normalize: opts.Normalize,
+		Workspace: opts.WorkspaceDB,
+	}
+	if opts.Names != "" {
+		ops.Names = ops.ParseColumns(opts.Names)
+	}
+
+	if (opts.SkipRows > 0 || opts.Comment != "") && (opts.Command == "fmt" || opts.Command == "migrate") {
+		return fmt.Errorf("-skip-rows and -comment only apply to reading; %s would drop the skipped lines", opts.Command)
+	}
+	if (opts.Command == "fmt" || opts.Command == "migrate") && operations.IsCompressed(opts.File) {
+		return fmt.Errorf("%s is compressed and is only read; decompress it to %s it", opts.File, opts.Command)
+	}
+
+	switch opts.Command {
+	case "create":
+		return ops.Create(opts.Header)
+	case "annotate":
+		if err := ops.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize CSV operations: %v", err)
+		}
+		return ops.Annotate(opts.Col, opts.Desc, opts.Tags, opts.Allowed)
+	case "fmt":
+		return ops.FormatFile(opts.Order, opts.Check)
+	case "diff":
+		if opts.Against == "" {
+			return fmt.Errorf("diff requires -against with the file to compare to")
+		}
+		if err := ops.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize CSV operations: %v", err)
+		}
+		return ops.DiffColumns(opts.Against, opts.On)
+	case "migrate":
+		if opts.Migrations == "" {
+			return fmt.Errorf("migrate requires -m with the directory of migration files")
+		}
+		return ops.Migrate(opts.Migrations)
+	case "package":
+		if opts.Output == "" {
+			return fmt.Errorf("package requires -o with the output directory")
+		}
+		if err := ops.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize CSV operations: %v", err)
+		}
+		dateFormats, err := operations.ParseDateFormats(opts.DateFormat)
+		if err != nil {
+			return err
+		}
+		ops.DateFormats = dateFormats
+		return ops.Package(opts.Output, opts.License)
+	case "tables":
+		return ops.ListWorkspace()
+	case "analyze":
+		return ops.Analyze()
+	case "examples":
+		if err := ops.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize CSV operations: %v", err)
+		}
+		return ops.Examples()
+	case "verify-bundle":
+		if opts.Key == "" {
+			return fmt.Errorf("verify-bundle requires -key with the signer's public key")
+		}
+		return operations.VerifyBundle(opts.File, opts.Key)
+	default:
+		return fmt.Errorf("unknown command: %s", opts.Command)
+	}
 }
 
 // ShowUsage displays help information
@@ -78,38 +706,153 @@ func ShowUsage(flagSet *goflags.FlagSet) {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Printf("  %s [flags]\n", os.Args[0])
+	fmt.Printf("  %s <command> [flags]\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Printf("   %-20s %s\n", "create", "Create a new CSV file with the columns given by -header (name or name:type)")
+	fmt.Printf("   %-20s %s\n", "fmt", "Rewrite a file in canonical form (-order to sort rows, -check for CI)")
+	fmt.Printf("   %-20s %s\n", "diff", "Summarize per-column changes from -file to -against (match rows with -on)")
+	fmt.Printf("   %-20s %s\n", "migrate", "Apply pending schema migrations from -m dir/ and record them in the sidecar")
+	fmt.Printf("   %-20s %s\n", "package", "Write the file and a Frictionless datapackage.json to -o dir/ (-license)")
+	fmt.Printf("   %-20s %s\n", "verify-bundle", "Check a signed export (-file) against its manifest and signature (-key)")
+	fmt.Printf("   %-20s %s\n", "tables", "List the tables stored in -workspace")
+	fmt.Printf("   %-20s %s\n", "examples", "Print example commands built from the columns of -file")
+	fmt.Printf("   %-20s %s\n", "version", "Print version, commit, build date and Go version as JSON (also -version)")
+	fmt.Printf("   %-20s %s\n", "self-update", "Replace this binary with the latest release after checking its SHA-256")
+	fmt.Printf("   %-20s %s\n", "analyze", "Record the row count and size of -file in the sidecar for cost estimates")
+	fmt.Printf("   %-20s %s\n", "annotate", "Attach a description (-desc), tags (-tags) or allowed values (-allowed) to columns")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println()
 	
 	// Input flags
 	fmt.Println("INPUT:")
-	fmt.Printf("   %-20s %s\n", "-file, -f", "CSV input file (required)")
+	fmt.Printf("   %-20s %s\n", "-file, -f", "CSV input file or pattern, http(s) URL, s3:// or gs:// object (- reads stdin)")
+	fmt.Printf("   %-20s %s\n", "-delimiter", "Input delimiter, e.g. ';' or tab (default: auto-detect)")
+	fmt.Printf("   %-20s %s\n", "-tsv", "Read the input as tab separated (same as -delimiter tab)")
+	fmt.Printf("   %-20s %s\n", "-out-delimiter", "Delimiter of -raw output and -output files, e.g. tab or pipe (default: comma)")
+	fmt.Printf("   %-20s %s\n", "-add-source-file", "Add a _source_file column naming the file of each row of a -file pattern")
+	fmt.Printf("   %-20s %s\n", "-http-header", "Header sent when -file is a URL (\"Authorization: Bearer ...\"), repeatable")
+	fmt.Printf("   %-20s %s\n", "-http-timeout", "Give up downloading a -file URL or object after this long (default 5m)")
+	fmt.Printf("   %-20s %s\n", "-max-download", "Fail if a -file URL or object is larger than this, e.g. 500mb")
+	fmt.Printf("   %-20s %s\n", "-skip-rows", "Skip this many lines, such as a banner, at the start of the input")
+	fmt.Printf("   %-20s %s\n", "-comment", "Skip input lines starting with this prefix, e.g. '#'")
+	fmt.Printf("   %-20s %s\n", "-no-header", "Read the first row as data; columns are named c1,c2,... or by -names")
+	fmt.Printf("   %-20s %s\n", "-quote-char", "Quote character of the input, or none (detected by default)")
+	fmt.Printf("   %-20s %s\n", "-escape-char", "Character that makes the next one literal, such as a backslash (quotes are doubled by default)")
+	fmt.Printf("   %-20s %s\n", "-on-bad-rows", "Rows with the wrong number of fields: error (default), skip, pad or report")
+	fmt.Printf("   %-20s %s\n", "-lazy-quotes", "Read stray and unterminated quotes as text instead of failing")
+	fmt.Printf("   %-20s %s\n", "-encoding", "Character encoding of the input: utf-8, utf-16, latin1, windows-1252, ... (detected by default)")
+	fmt.Printf("   %-20s %s\n", "-widths", "Read fixed-width columns of these widths (10,4,20) or from a spec file of \"name width\" lines")
+	fmt.Printf("   %-20s %s\n", "-names", "Column names of a file without a header row (id,name,...), implies -no-header")
+	fmt.Printf("   %-20s %s\n", "-no-sniff", "Disable delimiter/quote/header/encoding detection")
+	fmt.Printf("   %-20s %s\n", "-header", "Column names for an empty file (col1,col2,...)")
+	fmt.Printf("   %-20s %s\n", "-date-formats", "Extra date formats for comparisons and date functions, e.g. DD.MM.YYYY")
+	fmt.Printf("   %-20s %s\n", "-force-type", "Read columns as a type, other values as NULL (col:int|float|string|bool,...)")
+	fmt.Printf("   %-20s %s\n", "-normalize-headers", "Refer to \" Max Severity \" as max_severity; output keeps the file's names")
+	fmt.Printf("   %-20s %s\n", "-rename", "Rename columns when the file is read (old1=new1,...); written back only by writes")
+	fmt.Printf("   %-20s %s\n", "-locale", "Number and date parsing profile, e.g. de-DE (1.234,5 and 31.12.2024)")
 	fmt.Println()
 	
 	// Operation flags  
 	fmt.Println("OPERATIONS:")
+	fmt.Printf("   %-20s %s\n", "-query", "Full SQL SELECT statement (FROM may name the CSV file); repeat for a batch")
+	fmt.Printf("   %-20s %s\n", "-query-file", "Run the ;-separated statements of a file (-- output: path sets each target)")
 	fmt.Printf("   %-20s %s\n", "-select", "SELECT columns (comma-separated)")
-	fmt.Printf("   %-20s %s\n", "-insert", "INSERT new row (col1=val1,col2=val2)")
+	fmt.Printf("   %-20s %s\n", "-insert", "INSERT new rows (col1=val1,col2=val2;col1=val3,...), repeatable")
+	fmt.Printf("   %-20s %s\n", "-insert-from", "INSERT the rows of another CSV file, columns matched by name or -map")
+	fmt.Printf("   %-20s %s\n", "-map", "Map -insert-from source columns to target columns (src1:dst1,src2:dst2)")
+	fmt.Printf("   %-20s %s\n", "-coerce", "Cells of -insert-from that do not fit: strict, lossy (default) or skip-row")
+	fmt.Printf("   %-20s %s\n", "-insert-if-absent", "Key columns: -insert skips rows whose key values are already present")
+	fmt.Printf("   %-20s %s\n", "-upsert", "Update the row matching the -key columns, or insert it (col1=val1,...)")
 	fmt.Printf("   %-20s %s\n", "-update", "UPDATE column values (col1=val1,col2=val2)")
 	fmt.Printf("   %-20s %s\n", "-delete", "DELETE rows matching WHERE condition")
+	fmt.Printf("   %-20s %s\n", "-truncate", "DELETE every row, keeping the header (requires -yes)")
+	fmt.Printf("   %-20s %s\n", "-yes", "Confirm -truncate")
+	fmt.Printf("   %-20s %s\n", "-delete-rows", "DELETE rows by position (5,10,100-200); row 1 is the first after the header")
+	fmt.Printf("   %-20s %s\n", "-create", "CREATE -file with a header and typed schema (id:int,name:string,created_at:date)")
+	fmt.Printf("   %-20s %s\n", "-json-summary", "Print the outcome of a write as JSON (operation, file, matched, modified, ...)")
+	fmt.Printf("   %-20s %s\n", "-returning", "Print the rows DELETE removes or UPDATE changes (new values), or save them to -output")
+	fmt.Printf("   %-20s %s\n", "-backup", "Copy the file to <file>.bak before a write")
+	fmt.Printf("   %-20s %s\n", "-sort", "SORT the file in place (col1 desc,col2 asc), requires -write")
+	fmt.Printf("   %-20s %s\n", "-reverse", "REVERSE the row order of the file, requires -write")
+	fmt.Printf("   %-20s %s\n", "-rotate", "ROTATE the file: move the first N rows to the end, requires -write")
+	fmt.Printf("   %-20s %s\n", "-reorder", "REORDER columns: listed ones first in output, or in the file with -write")
+	fmt.Printf("   %-20s %s\n", "-add-column", "ADD a column to the file (name=default, or name:expression such as row_hash:sha256(*))")
+	fmt.Printf("   %-20s %s\n", "-verify-hashes", "VERIFY a hash column against the other columns (name[:expression])")
+	fmt.Printf("   %-20s %s\n", "-copy-column", "COPY a column from another file (src.csv:col -> dst.csv:col)")
 	fmt.Println()
 	
 	// Query modifiers
 	fmt.Println("QUERY MODIFIERS:")
 	fmt.Printf("   %-20s %s\n", "-where", "WHERE condition (SQL-like)")
-	fmt.Printf("   %-20s %s\n", "-order", "ORDER BY column [asc|desc]")
+	fmt.Printf("   %-20s %s\n", "-group", "GROUP BY columns (comma-separated)")
+	fmt.Printf("   %-20s %s\n", "-having", "HAVING condition on grouped results")
+	fmt.Printf("   %-20s %s\n", "-order", "ORDER BY col1 [asc|desc],col2 ...")
 	fmt.Printf("   %-20s %s\n", "-limit", "LIMIT number of rows returned")
+	fmt.Printf("   %-20s %s\n", "-top-per-group", "Keep the first N rows of each -group in -order order (top N per group)")
+	fmt.Printf("   %-20s %s\n", "-head", "Read only the first N rows of the file (fast on huge files)")
+	fmt.Printf("   %-20s %s\n", "-tail", "Read only the last N rows of the file (fast on huge files)")
+	fmt.Printf("   %-20s %s\n", "-lines", "Print raw lines from-to (e.g. 1000-1100) with the header, without parsing")
+	fmt.Printf("   %-20s %s\n", "-on", "Key column used to match rows between files (also the -upsert key, like -key)")
+	fmt.Printf("   %-20s %s\n", "-join", "File to match rows against on the -on key (id or id=other_id)")
+	fmt.Printf("   %-20s %s\n", "-join-type", "semi: keep rows with a match in -join; anti: keep rows without one")
+	fmt.Printf("   %-20s %s\n", "-intersect", "Keep distinct rows that also appear in this file (columns matched by name)")
+	fmt.Printf("   %-20s %s\n", "-except", "Keep distinct rows that do not appear in this file")
+	fmt.Printf("   %-20s %s\n", "-migrations, -m", "Directory of *.sql migration files for migrate")
+	fmt.Printf("   %-20s %s\n", "-against", "Newer version of -file to compare with diff")
+	fmt.Printf("   %-20s %s\n", "-col", "Column to annotate")
+	fmt.Printf("   %-20s %s\n", "-desc", "Column description for annotate")
+	fmt.Printf("   %-20s %s\n", "-tags", "Comma-separated column tags for annotate")
+	fmt.Printf("   %-20s %s\n", "-allowed", "Values INSERT and UPDATE may write (col:v1|v2,...); annotate saves them")
+	fmt.Printf("   %-20s %s\n", "-write", "Confirm rewriting the input file with -sort, -reverse, -rotate or -reorder")
+	fmt.Printf("   %-20s %s\n", "-check", "With fmt, fail if the file is not canonical instead of rewriting it")
+	fmt.Printf("   %-20s %s\n", "-seed", "Seed for RANDOM() and RANDOM_PICK() so results are reproducible")
+	fmt.Printf("   %-20s %s\n", "-hint", "Strategy overrides: stream, no-stream (no-index, hash-join accepted as no-ops)")
+	fmt.Printf("   %-20s %s\n", "-timeout", "Fail if the operation takes longer than this, e.g. 30s or 2m")
+	fmt.Printf("   %-20s %s\n", "-max-scan-rows", "Fail if the input has more data rows than this")
+	fmt.Printf("   %-20s %s\n", "-estimate", "Print the rows and bytes the query would scan, without running it")
+	fmt.Printf("   %-20s %s\n", "-force", "Read inputs over 4 GiB in full (above 512 MiB a warning is printed)")
+	fmt.Printf("   %-20s %s\n", "-allow-downgrade", "Let self-update install the latest release even when it is not newer")
 	fmt.Println()
 	
 	// Output flags
 	fmt.Println("OUTPUT:")
 	fmt.Printf("   %-20s %s\n", "-columns", "Show CSV column headers")
+	fmt.Printf("   %-20s %s\n", "-count", "Print only the number of (matching) rows; fast without -where")
+	fmt.Printf("   %-20s %s\n", "-fail-if-empty", "Exit with status 4 when the query returns no rows (for monitoring scripts)")
+	fmt.Printf("   %-20s %s\n", "-fail-if-found", "Exit with status 5 when the query returns any rows")
+	fmt.Printf("   %-20s %s\n", "-describe", "Show column types, descriptions and tags")
+	fmt.Printf("   %-20s %s\n", "-detect-mixed", "Report columns read as text because of values like N/A, with the rows")
 	fmt.Printf("   %-20s %s\n", "-raw", "Show only table values without column headers")
-	fmt.Printf("   %-20s %s\n", "-output, -o", "Output file to save results")
+	fmt.Printf("   %-20s %s\n", "-output, -o", "Output file to save results (.har and .xml write HAR and Burp XML)")
+	fmt.Printf("   %-20s %s\n", "-null-string", "Text written for missing values in results, e.g. NULL (default: empty)")
+	fmt.Printf("   %-20s %s\n", "-empty-as-null", "Write empty values of results as -null-string too")
+	fmt.Printf("   %-20s %s\n", "-quote", "Quoting of -raw output and -output files: minimal (default), always or never")
+	fmt.Printf("   %-20s %s\n", "-crlf", "End the lines of written files and -raw output with CRLF (rewrites otherwise keep the file's)")
+	fmt.Printf("   %-20s %s\n", "-compress", "Gzip the -output file (also done for names ending in .gz; .zst uses zstd)")
+	fmt.Printf("   %-20s %s\n", "-sign", "Sign the -output file with a PEM private key (.manifest.json and .sig)")
+	fmt.Printf("   %-20s %s\n", "-key", "Key columns for -upsert (or -on); PEM public key file for verify-bundle")
+	fmt.Printf("   %-20s %s\n", "-license", "SPDX license identifier recorded by package, e.g. CC-BY-4.0")
+	fmt.Printf("   %-20s %s\n", "-only-cols", "Show only these output columns (comma-separated)")
+	fmt.Printf("   %-20s %s\n", "-hide-cols", "Hide these output columns (comma-separated)")
+	fmt.Printf("   %-20s %s\n", "-format", "Output layout: table (default), record (one \"column: value\" per line), sqlite (-output database) or template")
+	fmt.Printf("   %-20s %s\n", "-vertical", "Print each row as a block of \"column: value\" lines (same as -format record)")
+	fmt.Printf("   %-20s %s\n", "-template", "Go template for -format template, e.g. '{{.id}} => {{.name}}', or a template file")
+	fmt.Printf("   %-20s %s\n", "-table-style", "Table borders: ascii, light, heavy, double, compact (default) or borderless")
+	fmt.Printf("   %-20s %s\n", "-max-col-width", "Widest a table column may be; longer values are cut short with …, or wrapped with -wrap")
+	fmt.Printf("   %-20s %s\n", "-wrap", "Wrap long table cells, and cells with line breaks, onto several lines")
+	fmt.Printf("   %-20s %s\n", "-totals", "Add a footer row to table output: the sum of each number column, the count of values in the others")
+	fmt.Printf("   %-20s %s\n", "-no-color", "Print tables without colors (also NO_COLOR); colors are only used on a terminal")
+	fmt.Printf("   %-20s %s\n", "-wide", "Show records vertically when the table is wider than the terminal")
+	fmt.Printf("   %-20s %s\n", "-workspace", "SQLite database that keeps -save-as results across runs; FROM and -file find them by name")
+	fmt.Printf("   %-20s %s\n", "-table", "Name a CSV file for FROM and JOIN in queries (name=path.csv), repeatable; a bare name is the -format sqlite table")
+	fmt.Printf("   %-20s %s\n", "-save-as", "Store the result as a table of -workspace for later queries")
+	fmt.Printf("   %-20s %s\n", "-humanize", "Show numbers as 1.5M or 1.46 MiB in table output (col[:si|bytes],...)")
 	fmt.Println()
 	
 	// Misc flags
+	fmt.Printf("   %-20s %s\n", "-version", "Print version and build metadata as JSON")
 	fmt.Printf("   %-20s %s\n", "-h, -help", "Show help message")
 	fmt.Println()
 	
@@ -122,9 +865,10 @@ func ShowUsage(flagSet *goflags.FlagSet) {
 	// fmt.Printf("  %s -file tests/scope.csv -select \"identifier,asset_type\" -raw\n", "csvql")
 }
 
-func runSeeCSV(opts *Options) error {
-	// Validate that file exists
-	if _, err := os.Stat(opts.File); os.IsNotExist(err) {
+func runSeeCSV(opts *Options) (err error) {
+	// Validate that file exists (INSERT with -header may create it)
+	bootstrap := len(opts.Insert) > 0 && opts.Header != ""
+	if _, err := os.Stat(opts.File); os.IsNotExist(err) && !bootstrap {
 		return fmt.Errorf("file does not exist: %s", opts.File)
 	}
 
@@ -133,25 +877,430 @@ func runSeeCSV(opts *Options) error {
 		FilePath: opts.File,
 		RawOutput: opts.Raw,
 		OutputFile: opts.Output,
+		Delimiter: opts.Delimiter,
+		NoSniff: opts.NoSniff,
+		NoHeader: opts.NoHeader,
+		Widths: opts.ColumnWidths,
+		Encoding: opts.Encoding,
+		QuoteChar: opts.QuoteChar,
+		EscapeChar: opts.EscapeChar,
+		LazyQuotes: opts.LazyQuotes,
+		OnBadRows: opts.OnBadRows,
+		SkipRows: opts.SkipRows,
+		Comment: opts.Comment,
+		Header: opts.Header,
+		Locale: opts.Locale,
+		Wide: opts.Wide,
+		Seed: int64(opts.Seed),
+		MaxScanRows: opts.MaxScan,
+		Normalize: opts.Normalize,
+		Returning: opts.Returning,
+		Workspace: opts.WorkspaceDB,
+		Tables: opts.TableFiles,
+		Compress: opts.Compress,
+		CRLF: opts.CRLF,
+		NullString: opts.NullString,
+		EmptyAsNull: opts.EmptyAsNull,
+	}
+	if opts.Names != "" {
+		ops.Names = ops.ParseColumns(opts.Names)
+	}
+
+	// The number of result rows becomes the exit status once the query has run
+	if opts.FailEmpty || opts.FailFound {
+		defer func() {
+			if err == nil {
+				err = resultStatus(opts, ops.ResultRows)
+			}
+		}()
+	}
+
+	// Humanized columns only affect table output, never saved files
+	humanize, err := operations.ParseHumanizeSpec(opts.Humanize)
+	if err != nil {
+		return err
+	}
+	ops.Humanize = humanize
+
+	format, err := operations.ParseOutputFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+	// -vertical is shorthand for the record layout, like psql's \x
+	if opts.Vertical {
+		if opts.Format != "" && format != operations.FormatRecord {
+			return fmt.Errorf("-vertical prints records and cannot be combined with -format %s", format)
+		}
+		format = operations.FormatRecord
+	}
+	ops.Format = format
+	ops.SQLiteTable = opts.OutputTable
+	if format == operations.FormatSQLite && (opts.Output == "" || opts.SaveAs != "" || opts.Raw || opts.Compress) {
+		return fmt.Errorf("-format sqlite writes a table of the -output database, such as -output results.db, and cannot be used with -save-as, -raw or -compress")
+	}
+	if (format == operations.FormatTemplate) != (opts.Template != "") {
+		return fmt.Errorf("-format template and -template go together, e.g. -format template -template '{{.id}} => {{.name}}'")
+	}
+	if format == operations.FormatTemplate {
+		if opts.Raw {
+			return fmt.Errorf("-format template prints only the rendered rows and cannot be used with -raw")
+		}
+		if ops.Template, err = operations.ParseTemplate(opts.Template); err != nil {
+			return err
+		}
+		// Like -raw, the rendered rows are printed without row counts
+		ops.RawOutput = true
+	}
+	if opts.OutDelimiter != "" {
+		if format == operations.FormatSQLite || format == operations.FormatTemplate {
+			return fmt.Errorf("-out-delimiter applies to delimited output and cannot be used with -format sqlite or template")
+		}
+		if ops.OutDelimiter, err = operations.ParseDelimiter(opts.OutDelimiter); err != nil {
+			return err
+		}
+	}
+	if ops.QuoteStyle, err = operations.ParseQuoteStyle(opts.Quote); err != nil {
+		return err
+	}
+	if opts.OutputTable != "" && format != operations.FormatSQLite {
+		return fmt.Errorf("-table %s names the table -format sqlite writes; use -table name=path.csv to name a file for queries", opts.OutputTable)
+	}
+
+	tableStyle, err := operations.ParseTableStyle(opts.TableStyle)
+	if err != nil {
+		return err
+	}
+	ops.TableStyle = tableStyle
+	if opts.MaxColWidth < 0 {
+		return fmt.Errorf("invalid -max-col-width: %d (expected a positive number of characters)", opts.MaxColWidth)
+	}
+	ops.MaxColWidth = opts.MaxColWidth
+	ops.Wrap = opts.Wrap
+	ops.Totals = opts.Totals
+	// Colors are for people at a terminal; pipes and redirected output stay plain
+	ops.Color = !opts.NoColor && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+
+	dateFormats, err := operations.ParseDateFormats(opts.DateFormat)
+	if err != nil {
+		return err
+	}
+	ops.DateFormats = dateFormats
+
+	hints, err := operations.ParseHints(opts.Hint)
+	if err != nil {
+		return err
+	}
+	ops.Hints = hints
+
+	// Operations that rewrite the input file need all of it, read as is
+	modifies := len(opts.Insert) > 0 || opts.InsertFrom != "" || opts.Upsert != "" || opts.Update != "" || opts.Delete || opts.DeleteRows != "" || opts.Truncate || opts.CopyColumn != ""
+
+	if opts.Spooled != "" && (modifies || opts.Write || opts.AddColumn != "") {
+		return fmt.Errorf("%s is read into a temporary copy and cannot be rewritten; save it to a file to change it", opts.Spooled)
+	}
+	if (opts.SkipRows > 0 || opts.Comment != "") && (modifies || opts.Write || opts.AddColumn != "") {
+		return fmt.Errorf("-skip-rows and -comment only apply to reading; a rewrite would drop the skipped lines")
+	}
+	if mode, _ := operations.ParseBadRowsMode(opts.OnBadRows); (mode == operations.BadRowsSkip || mode == operations.BadRowsReport) && (modifies || opts.Write || opts.AddColumn != "") {
+		return fmt.Errorf("-on-bad-rows %s only applies to reading; a rewrite would drop the bad rows (use pad to repair them)", mode)
+	}
+	if (modifies || opts.Write || opts.AddColumn != "") && operations.IsCompressed(opts.File) {
+		return fmt.Errorf("%s is compressed and is only read; decompress it to change it", opts.File)
+	}
+	if (opts.FailEmpty || opts.FailFound) && modifies {
+		return fmt.Errorf("-fail-if-empty and -fail-if-found check query results and cannot be combined with INSERT, UPSERT, UPDATE, DELETE or COPY")
+	}
+	if opts.DeleteRows != "" && (opts.Delete || opts.Where != "") {
+		return fmt.Errorf("-delete-rows selects rows by position and cannot be combined with -delete or -where")
+	}
+	if opts.Returning && opts.Update == "" && !opts.Delete && opts.DeleteRows == "" && !opts.Truncate {
+		return fmt.Errorf("-returning applies to UPDATE and DELETE")
+	}
+	if opts.Returning && opts.JSONSum {
+		return fmt.Errorf("-returning and -json-summary both print to stdout and cannot be used together")
+	}
+	if opts.Truncate && !opts.Yes {
+		return fmt.Errorf("-truncate deletes every row of %s; add -yes to confirm", opts.File)
+	}
+	if opts.Truncate && (opts.Delete || opts.DeleteRows != "" || opts.Where != "") {
+		return fmt.Errorf("-truncate deletes every row and cannot be combined with -delete, -delete-rows or -where")
+	}
+	if opts.FailEmpty && opts.FailFound {
+		return fmt.Errorf("-fail-if-empty and -fail-if-found cannot be used together")
+	}
+	if (opts.JSONSum || opts.Backup) && !modifies {
+		return fmt.Errorf("-json-summary and -backup apply to INSERT, UPSERT, UPDATE, DELETE or COPY")
+	}
+
+	// Forced types change how values are read, so files are never rewritten with them
+	forceTypes, err := operations.ParseForceTypes(opts.ForceType)
+	if err != nil {
+		return err
+	}
+	if len(forceTypes) > 0 && modifies {
+		return fmt.Errorf("-force-type changes how values are read and cannot be combined with INSERT, UPSERT, UPDATE, DELETE or COPY")
 	}
+	ops.ForceTypes = forceTypes
 
-	// Initialize the operations
-	if err := ops.Initialize(); err != nil {
+	renames, err := operations.ParseRenames(opts.Rename)
+	if err != nil {
+		return err
+	}
+	ops.Renames = renames
+
+	allowed, err := operations.ParseAllowedValues(opts.Allowed)
+	if err != nil {
+		return err
+	}
+	ops.Allowed = allowed
+
+	// Column presets apply to every printed result
+	if opts.OnlyCols != "" {
+		ops.OnlyCols = ops.ParseColumns(opts.OnlyCols)
+	}
+	if opts.HideCols != "" {
+		ops.HideCols = ops.ParseColumns(opts.HideCols)
+	}
+	if opts.Reorder != "" && !opts.Write {
+		ops.Reorder = ops.ParseColumns(opts.Reorder)
+	}
+
+	// Reads of the whole file are estimated first, as they may take hours on huge inputs
+	if opts.Head == 0 && opts.Tail == 0 && opts.Lines == "" {
+		if opts.Estimate {
+			limit := opts.Limit
+			if opts.Count {
+				limit = 1
+			}
+			return ops.PrintScanEstimate(limit)
+		}
+		if err := ops.CheckScanCost(opts.Force); err != nil {
+			return err
+		}
+	}
+
+	// Counting all rows only needs a scan for newlines
+	filtered := opts.Where != "" || opts.Join != "" || opts.Intersect != "" || opts.Except != ""
+	if opts.Count && !filtered && opts.Head == 0 && opts.Tail == 0 && !ops.Hints[operations.HintNoStream] {
+		count, err := ops.CountRows()
+		if err != nil {
+			return err
+		}
+		fmt.Println(count)
+		ops.ResultRows = count
+		return nil
+	}
+
+	// Line ranges are copied straight from the file without loading it
+	if opts.Lines != "" {
+		from, to, err := operations.ParseLineRange(opts.Lines)
+		if err != nil {
+			return err
+		}
+		return ops.PrintLines(from, to)
+	}
+
+	// Whole-file transforms read the file themselves, so they run before it is loaded
+	if opts.Sort != "" || opts.Reverse || opts.Rotate != 0 || (opts.Reorder != "" && opts.Write) {
+		if !opts.Write {
+			return fmt.Errorf("this operation rewrites %s; add -write to confirm (use -order to sort query results)", opts.File)
+		}
+		switch {
+		case opts.Sort != "":
+			return ops.SortFile(opts.Sort)
+		case opts.Reverse:
+			return ops.ReverseFile()
+		case opts.Reorder != "":
+			return ops.ReorderFile(ops.ParseColumns(opts.Reorder))
+		default:
+			return ops.RotateFile(opts.Rotate)
+		}
+	}
+
+	// Computed columns and hash checks work on the raw text of the file
+	if opts.AddColumn != "" {
+		return ops.AddColumn(opts.AddColumn)
+	}
+	if opts.Verify != "" {
+		return ops.VerifyHashes(opts.Verify)
+	}
+
+	// Read-only queries load only the columns they use, which saves memory on wide files
+	if opts.Command == "" && !modifies && !opts.Write && !opts.Columns && !opts.Describe && !opts.Mixed && opts.Join == "" && opts.Intersect == "" && opts.Except == "" {
+		switch {
+		case len(opts.Batch) == 1:
+			ops.Project = operations.QueryColumns(opts.Batch[0].SQL)
+		case len(opts.Batch) == 0 && opts.Select != "":
+			ops.Project = operations.SelectColumns(opts.Select, opts.Where, opts.Group, opts.Having, opts.Order)
+		}
+	}
+
+	// Initialize the operations, reading only a window of rows for -head/-tail
+	switch {
+	case opts.Head > 0 || opts.Tail > 0:
+		if modifies {
+			return fmt.Errorf("-head and -tail only read part of the file and cannot be combined with INSERT, UPSERT, UPDATE, DELETE or COPY")
+		}
+		if opts.Head > 0 {
+			err = ops.InitializeHead(opts.Head)
+		} else {
+			err = ops.InitializeTail(opts.Tail)
+		}
+	default:
+		err = ops.Initialize()
+	}
+	if err != nil {
 		return fmt.Errorf("failed to initialize CSV operations: %v", err)
 	}
 
+	// Semi and anti joins narrow the loaded rows before the query runs
+	if opts.Join != "" || opts.JoinType != "" {
+		if opts.Join == "" {
+			return fmt.Errorf("-join-type requires -join with the file to match against")
+		}
+		if modifies {
+			return fmt.Errorf("-join filters query results and cannot be combined with INSERT, UPSERT, UPDATE, DELETE or COPY")
+		}
+		if err := ops.ApplyJoinFilter(opts.Join, opts.JoinType, opts.On); err != nil {
+			return err
+		}
+	}
+
+	// INTERSECT and EXCEPT compare whole rows with another file
+	if opts.Intersect != "" || opts.Except != "" {
+		if modifies {
+			return fmt.Errorf("-intersect and -except filter query results and cannot be combined with INSERT, UPSERT, UPDATE, DELETE or COPY")
+		}
+		if opts.Intersect != "" {
+			if err := ops.ApplySetFilter(opts.Intersect, "INTERSECT"); err != nil {
+				return err
+			}
+		}
+		if opts.Except != "" {
+			if err := ops.ApplySetFilter(opts.Except, "EXCEPT"); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Rewrites are timed, and backed up first when asked, for -json-summary
+	if modifies {
+		backup := ""
+		if opts.Backup {
+			if backup, err = ops.BackupFile(); err != nil {
+				return err
+			}
+		}
+		if opts.JSONSum {
+			ops.JSONSummary = true
+			start := time.Now()
+			defer func() {
+				if err == nil && ops.Mutation != nil {
+					ops.Mutation.Duration = float64(time.Since(start).Microseconds()) / 1000
+					ops.Mutation.Backup = backup
+					err = printJSON(ops.Mutation)
+				}
+			}()
+		}
+	}
+
 	// Handle different operations based on flags
 	switch {
 	case opts.Columns:
 		return ops.ShowColumns()
-	case opts.Insert != "":
-		return ops.Insert(opts.Insert)
+	case opts.Describe:
+		return ops.Describe()
+	case opts.Mixed:
+		return ops.DetectMixed()
+	case opts.Count:
+		filteredDF, err := ops.ApplyWhereCondition(ops.DataFrame, opts.Where)
+		if err != nil {
+			return fmt.Errorf("WHERE condition error: %v", err)
+		}
+		fmt.Println(filteredDF.Nrow())
+		ops.ResultRows = filteredDF.Nrow()
+		return nil
+	case len(opts.Batch) > 0:
+		return ops.RunBatch(opts.Batch)
+	case opts.IfAbsent != "" && len(opts.Insert) == 0:
+		return fmt.Errorf("-insert-if-absent names the key columns of -insert rows and requires -insert")
+	case len(opts.Insert) > 0 && opts.IfAbsent != "":
+		return ops.InsertIfAbsent(strings.Join(opts.Insert, ";"), ops.ParseColumns(opts.IfAbsent))
+	case len(opts.Insert) > 0:
+		// Repeated -insert flags add their rows in one rewrite, like rows separated by semicolons
+		return ops.Insert(strings.Join(opts.Insert, ";"))
+	case opts.InsertFrom != "":
+		mapping, err := operations.ParseColumnMapping(opts.Map)
+		if err != nil {
+			return err
+		}
+		policy, err := operations.ParseCoercePolicy(opts.Coerce)
+		if err != nil {
+			return err
+		}
+		// Unmatched source columns are asked for when someone is at the terminal, and are
+		// an error in scripts
+		interactive := term.IsTerminal(int(os.Stdin.Fd())) && !opts.JSONSum
+		return ops.InsertFromCSV(opts.InsertFrom, mapping, interactive, policy)
+	case opts.Upsert != "":
+		// -key names the key columns; -on is accepted too, as for the other keyed operations
+		if opts.Key != "" && opts.On != "" && opts.Key != opts.On {
+			return fmt.Errorf("-upsert takes its key columns from -key or -on, not both")
+		}
+		spec := opts.Key
+		if spec == "" {
+			spec = opts.On
+		}
+		var keys []string
+		if spec != "" {
+			keys = ops.ParseColumns(spec)
+		}
+		return ops.Upsert(opts.Upsert, keys)
 	case opts.Update != "":
 		return ops.Update(opts.Update, opts.Where)
 	case opts.Delete:
 		return ops.Delete(opts.Where)
+	case opts.DeleteRows != "":
+		rows, err := operations.ParseRowNumbers(opts.DeleteRows)
+		if err != nil {
+			return err
+		}
+		return ops.DeleteByRowNumbers(rows)
+	case opts.Truncate:
+		return ops.DeleteAll()
+	case opts.CopyColumn != "":
+		spec, err := operations.ParseCopyColumnSpec(opts.CopyColumn)
+		if err != nil {
+			return err
+		}
+		return ops.CopyColumn(spec, opts.On)
+	case opts.PerGroup > 0:
+		if opts.Having != "" {
+			return fmt.Errorf("-top-per-group picks rows, not groups, and cannot be used with -having")
+		}
+		return ops.SelectTopPerGroup(opts.Select, opts.Where, opts.Group, opts.Order, opts.PerGroup, opts.Limit)
 	default:
 		// Default to SELECT operation
-		return ops.Select(opts.Select, opts.Where, opts.Order, opts.Limit)
+		return ops.Select(opts.Select, opts.Where, opts.Group, opts.Having, opts.Order, opts.Limit)
+	}
+}
+
+// resultStatus turns the number of result rows into the error of -fail-if-empty or
+// -fail-if-found, or nil when the check passes
+func resultStatus(opts *Options, rows int) error {
+	switch {
+	case opts.FailEmpty && rows == 0:
+		return &ExitError{Code: ExitEmpty, Message: "no rows returned (-fail-if-empty)"}
+	case opts.FailFound && rows > 0:
+		return &ExitError{Code: ExitFound, Message: fmt.Sprintf("%d rows returned (-fail-if-found)", rows)}
 	}
-}
\ No newline at end of file
+	return nil
+}
+
+// printJSON writes v to stdout as one line of JSON
+func printJSON(v interface{}) error {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// releaseURL is the GitHub API endpoint of the latest published release
+const releaseURL = "https://api.github.com/repos/saeed0x1/seesv/releases/latest"
+
+// checksumsAsset is the release file listing the SHA-256 of every binary, as written by sha256sum
+const checksumsAsset = "checksums.txt"
+
+// updateClient bounds every request of self-update, downloads included
+var updateClient = &http.Client{Timeout: 5 * time.Minute}
+
+// release is the part of a GitHub release that self-update reads
+type release struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// releaseAsset returns the name of the release binary for this platform, e.g. seesv_linux_amd64
+func releaseAsset() string {
+	name := "seesv_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// selfUpdate replaces the running binary with the latest release for this platform. The
+// download is checked against the release's checksums.txt before anything is replaced, and
+// the new binary is moved into place in one rename so an interrupted update leaves the old one.
+// Only a newer release is installed unless downgrade is set.
+func selfUpdate(downgrade bool) error {
+	current := buildInfo().Version
+	latest, err := latestRelease()
+	if err != nil {
+		return err
+	}
+	if !downgrade {
+		latestVersion, ok := parseVersion(latest.TagName)
+		if !ok {
+			return fmt.Errorf("latest release %s is not a semantic version; use -allow-downgrade to install it anyway", latest.TagName)
+		}
+		currentVersion, ok := parseVersion(current)
+		if !ok {
+			return fmt.Errorf("this build (%s) has no release version to compare with %s; use -allow-downgrade to install it anyway", current, latest.TagName)
+		}
+		switch order := compareVersions(latestVersion, currentVersion); {
+		case order == 0:
+			fmt.Printf("seesv %s is the latest release\n", current)
+			return nil
+		case order < 0:
+			fmt.Printf("seesv %s is newer than the latest release %s; use -allow-downgrade to install it anyway\n", current, latest.TagName)
+			return nil
+		}
+	}
+
+	urls := make(map[string]string)
+	for _, asset := range latest.Assets {
+		urls[asset.Name] = asset.URL
+	}
+	name := releaseAsset()
+	if urls[name] == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s (%s)", latest.TagName, runtime.GOOS, runtime.GOARCH, name)
+	}
+	if urls[checksumsAsset] == "" {
+		return fmt.Errorf("release %s has no %s; refusing to install an unverified binary", latest.TagName, checksumsAsset)
+	}
+	expected, err := releaseChecksum(urls[checksumsAsset], name)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to locate the running binary: %v", err)
+	}
+
+	// The download goes next to the binary so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".seesv-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create update file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	sum, err := download(urls[name], tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %v", name, err)
+	}
+	if sum != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s; the binary was not replaced", name, expected, sum)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make update executable: %v", err)
+	}
+
+	// Windows cannot replace a running binary, but it can rename it out of the way
+	if runtime.GOOS == "windows" {
+		os.Remove(exe + ".old")
+		if err := os.Rename(exe, exe+".old"); err != nil {
+			return fmt.Errorf("failed to replace %s: %v", exe, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		if runtime.GOOS == "windows" {
+			os.Rename(exe+".old", exe)
+		}
+		return fmt.Errorf("failed to replace %s: %v", exe, err)
+	}
+
+	fmt.Printf("Updated %s from %s to %s (sha256 %s)\n", exe, current, latest.TagName, sum)
+	return nil
+}
+
+// version is a parsed semantic version such as v1.4.0 or 2.0.0-rc.1
+type version struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseVersion parses a semantic version with an optional leading v and build metadata.
+// Missing minor and patch numbers count as 0, so v1.4 is v1.4.0.
+func parseVersion(text string) (version, bool) {
+	var v version
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	if i := strings.IndexByte(text, '+'); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.IndexByte(text, '-'); i >= 0 {
+		v.prerelease = strings.Split(text[i+1:], ".")
+		text = text[:i]
+	}
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	for _, id := range v.prerelease {
+		if id == "" {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, the same as or newer than b, with
+// semantic versioning precedence: a prerelease comes before its release, and numeric
+// prerelease identifiers are compared as numbers and before text ones
+func compareVersions(a, b version) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			return compareInts(a.core[i], b.core[i])
+		}
+	}
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		x, y := a.prerelease[i], b.prerelease[i]
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				return compareInts(xn, yn)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return compareInts(len(a.prerelease), len(b.prerelease))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// latestRelease fetches the latest release from the GitHub API
+func latestRelease() (release, error) {
+	var latest release
+	req, err := http.NewRequest(http.MethodGet, releaseURL, nil)
+	if err != nil {
+		return latest, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "seesv/"+buildInfo().Version)
+	resp, err := updateClient.Do(req)
+	if err != nil {
+		return latest, fmt.Errorf("failed to check for updates: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return latest, fmt.Errorf("failed to check for updates: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return latest, fmt.Errorf("failed to read release information: %v", err)
+	}
+	if latest.TagName == "" {
+		return latest, fmt.Errorf("failed to read release information: no tag name")
+	}
+	return latest, nil
+}
+
+// releaseChecksum downloads a sha256sum listing and returns the checksum given for name
+func releaseChecksum(url, name string) (string, error) {
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %v", checksumsAsset, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", checksumsAsset, resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Binary-mode listings mark the file name with a leading *
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", checksumsAsset, err)
+	}
+	return "", fmt.Errorf("%s does not list %s; refusing to install an unverified binary", checksumsAsset, name)
+}
+
+// download writes the body at url to w and returns its SHA-256 in hex
+func download(url string, w io.Writer) (string, error) {
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", resp.Status)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), resp.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// ParseColumnSpec splits a "name:expression" column specification, e.g. "row_hash:sha256(*)".
+// When the expression is omitted, defaultExpr is used instead.
+func ParseColumnSpec(spec, defaultExpr string) (string, sqlparser.Expr, error) {
+	name, exprText, found := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !found {
+		exprText = defaultExpr
+	}
+	if name == "" || strings.TrimSpace(exprText) == "" {
+		return "", nil, fmt.Errorf("invalid column specification: %s (expected name:expression)", spec)
+	}
+
+	expr, err := sqlparser.ParseExpr(exprText)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid column expression '%s': %v", exprText, err)
+	}
+	return name, expr, nil
+}
+
+// ParseAddColumnSpec splits an -add-column specification. "name:expression" computes each
+// row's value, while "name=value" fills every row with the same value, as in "verified=false"
+// or "source='import'". Whichever separator comes first decides the form.
+func ParseAddColumnSpec(spec string) (string, sqlparser.Expr, error) {
+	sep := strings.IndexAny(spec, ":=")
+	if sep < 0 || spec[sep] == ':' {
+		return ParseColumnSpec(spec, "")
+	}
+	name, value := strings.TrimSpace(spec[:sep]), strings.TrimSpace(spec[sep+1:])
+	if name == "" {
+		return "", nil, fmt.Errorf("invalid column specification: %s (expected name=value or name:expression)", spec)
+	}
+	// Constants are read as in ADD COLUMN ... DEFAULT, and anything else as plain text
+	if constant, ok := defaultValue(value); ok {
+		value = constant
+	}
+	return name, &sqlparser.Literal{Kind: sqlparser.StringLiteral, Value: value}, nil
+}
+
+// AddColumn appends a column to the file from a "name:expression" or "name=value" spec and
+// rewrites it. Expressions see the values as written in the file, so sha256(*) hashes the raw text.
+func (ops *CSVOperations) AddColumn(spec string) error {
+	name, expr, err := ParseAddColumnSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	records, dialect, err := ops.readRawRecords()
+	if err != nil {
+		return err
+	}
+	if indexOf(records[0], name) >= 0 {
+		return fmt.Errorf("column '%s' already exists in CSV", name)
+	}
+
+	values, err := ops.evalRecords(records, expr)
+	if err != nil {
+		return fmt.Errorf("ADD COLUMN error: %v", err)
+	}
+	records[0] = append(records[0], name)
+	for i, value := range values {
+		records[i+1] = append(records[i+1], value)
+	}
+	if err := ops.writeRecords(records, dialect); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully added column '%s' to %d rows\n", name, len(values))
+	return nil
+}
+
+// VerifyHashes recomputes a hash column from a "name[:expression]" spec (sha256(*) by default)
+// over the other columns and reports rows whose stored value no longer matches
+func (ops *CSVOperations) VerifyHashes(spec string) error {
+	name, expr, err := ParseColumnSpec(spec, "sha256(*)")
+	if err != nil {
+		return err
+	}
+
+	records, _, err := ops.readRawRecords()
+	if err != nil {
+		return err
+	}
+	idx := indexOf(records[0], name)
+	if idx < 0 {
+		return fmt.Errorf("column '%s' does not exist in CSV", name)
+	}
+
+	// The hash covers every column except the one that stores it
+	others := make([][]string, len(records))
+	for i, record := range records {
+		row := append([]string{}, record[:min(idx, len(record))]...)
+		if idx+1 < len(record) {
+			row = append(row, record[idx+1:]...)
+		}
+		others[i] = row
+	}
+	values, err := ops.evalRecords(others, expr)
+	if err != nil {
+		return fmt.Errorf("VERIFY error: %v", err)
+	}
+
+	failed := 0
+	for i, value := range values {
+		stored := ""
+		if idx < len(records[i+1]) {
+			stored = records[i+1][idx]
+		}
+		if !strings.EqualFold(stored, value) {
+			fmt.Printf("row %d: %s mismatch\n", i+1, name)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed hash verification", failed, len(values))
+	}
+
+	fmt.Printf("All %d rows verified\n", len(values))
+	return nil
+}
+
+// readRawRecords reads the file as text records (header first), without type inference
+func (ops *CSVOperations) readRawRecords() ([][]string, Dialect, error) {
+	data, err := os.ReadFile(ops.FilePath)
+	if err != nil {
+		return nil, Dialect{}, fmt.Errorf("failed to open file: %v", err)
+	}
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return nil, dialect, err
+	}
+	if len(records) == 0 {
+		return nil, dialect, fmt.Errorf("file is empty: %s", ops.FilePath)
+	}
+	return records, dialect, nil
+}
+
+// evalRecords evaluates expr for each data row of records, over the values as text
+func (ops *CSVOperations) evalRecords(records [][]string, expr sqlparser.Expr) ([]string, error) {
+	df := NewStringDataFrame(records[0], records[1:])
+	evaluator := ops.evaluator(df)
+	values := make([]string, len(records)-1)
+	for i := range values {
+		value, err := evaluator.Eval(expr, i)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = formatValue(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,83 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Modes accepted by -on-bad-rows for rows with more or fewer fields than the header
+const (
+	BadRowsError  = "error"  // BadRowsError fails on the first bad row (the default)
+	BadRowsSkip   = "skip"   // BadRowsSkip leaves bad rows out and says how many there were
+	BadRowsPad    = "pad"    // BadRowsPad fills short rows with empty cells; long rows are still an error
+	BadRowsReport = "report" // BadRowsReport leaves bad rows out and lists each one
+)
+
+// ParseBadRowsMode checks an -on-bad-rows mode
+func ParseBadRowsMode(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		return BadRowsError, nil
+	case BadRowsError, BadRowsSkip, BadRowsPad, BadRowsReport:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid -on-bad-rows mode: %s (use %s, %s, %s or %s)", mode, BadRowsError, BadRowsSkip, BadRowsPad, BadRowsReport)
+}
+
+// toleratesBadRows reports whether -on-bad-rows reads rows with the wrong number of fields,
+// which the streaming readers would reject
+func (ops *CSVOperations) toleratesBadRows() bool {
+	mode, err := ParseBadRowsMode(ops.OnBadRows)
+	return err != nil || mode != BadRowsError
+}
+
+// checkRowWidths applies the -on-bad-rows mode to the data rows of records, header first,
+// whose number of fields differs from the header's. Rows are numbered from 1, the first after
+// the header, and notes about skipped or padded rows go to stderr. Pad never drops fields, so
+// it fails on a long row: a rewrite would lose its extra cells.
+func (ops *CSVOperations) checkRowWidths(records [][]string, delimiter rune) ([][]string, error) {
+	if len(records) == 0 {
+		return records, nil
+	}
+	mode, err := ParseBadRowsMode(ops.OnBadRows)
+	if err != nil {
+		return nil, err
+	}
+	width := len(records[0])
+
+	kept := records[:1]
+	short, long := 0, 0
+	for i, record := range records[1:] {
+		if len(record) == width {
+			kept = append(kept, record)
+			continue
+		}
+		if len(record) < width {
+			short++
+		} else {
+			long++
+		}
+
+		switch {
+		case mode == BadRowsError:
+			return nil, fmt.Errorf("row %d has %d fields, but the header has %d (use -on-bad-rows skip, pad or report to read the file anyway)", i+1, len(record), width)
+		case mode == BadRowsPad && len(record) > width:
+			return nil, fmt.Errorf("row %d has %d fields, more than the %d of the header; -on-bad-rows pad only fills short rows (use skip or report to leave long rows out)", i+1, len(record), width)
+		case mode == BadRowsPad:
+			kept = append(kept, append(record, make([]string, width-len(record))...))
+		case mode == BadRowsReport:
+			fmt.Fprintf(os.Stderr, "Bad row %d: %d fields instead of %d: %s\n", i+1, len(record), width, strings.Join(record, string(delimiter)))
+		}
+	}
+
+	switch {
+	case short+long == 0:
+	case mode == BadRowsPad:
+		fmt.Fprintf(os.Stderr, "Warning: padded %d short rows with empty cells to the %d columns of the header\n", short, width)
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d rows with the wrong number of fields (%d short, %d long)\n", short+long, short, long)
+	}
+	return kept, nil
+}
@@ -1,47 +1,417 @@
 package operations
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
+	"math/rand"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/go-gota/gota/dataframe"
 	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
 )
 
 // CSVOperations handles all CSV-related operations
 type CSVOperations struct {
-	FilePath   string
-	DataFrame  dataframe.DataFrame
-	Headers    []string
-	RawOutput  bool
-	OutputFile string
+	FilePath    string
+	DataFrame   dataframe.DataFrame
+	Headers     []string
+	RawOutput   bool
+	OutputFile  string
+	Delimiter   string                  // Delimiter overrides the sniffed delimiter when set
+	OutDelimiter rune                   // OutDelimiter separates the fields of -raw output and -output files (comma when zero)
+	NoSniff     bool                    // NoSniff disables dialect detection and assumes plain CSV
+	SkipRows    int                     // SkipRows drops this many lines, such as a banner, from the start of the input
+	Comment     string                  // Comment drops input lines starting with this prefix, such as #
+	NoHeader    bool                    // NoHeader reads the first row as data, overriding header detection
+	Names       []string                // Names are the column names of a file without a header row (c1, c2, ... by default)
+	QuoteChar   string                  // QuoteChar overrides the sniffed quote character; "none" reads quotes as text
+	EscapeChar  string                  // EscapeChar is a character such as \ that makes the next one literal
+	OnBadRows   string                  // OnBadRows handles rows with the wrong number of fields: error (default), skip, pad or report
+	LazyQuotes  bool                    // LazyQuotes reads stray and unterminated quotes as text instead of failing
+	Encoding    string                  // Encoding overrides the detected character encoding when set (see ParseEncoding)
+	Widths      []int                   // Widths splits each line into columns of this many characters instead of at a delimiter
+	Dialect     Dialect                 // Dialect is the detected (or overridden) layout of the input file
+	Header      string                  // Header supplies comma-separated column names when the input file is empty
+	Locale      string                  // Locale selects regional number and date parsing (e.g. de-DE)
+	Humanize    map[string]HumanizeUnit // Humanize abbreviates numbers in these columns in table output
+	OnlyCols    []string                // OnlyCols limits output to these columns when set
+	HideCols    []string                // HideCols removes these columns from output
+	Reorder     []string                // Reorder moves these columns to the front of output, in this order
+	Wide        bool                    // Wide switches to vertical records when a table is wider than the terminal
+	Format      string                  // Format selects the output layout: table (default), record, sqlite or template
+	SQLiteTable string                  // SQLiteTable is the table -format sqlite writes (DefaultSQLiteTable when empty)
+	Template    *template.Template      // Template renders each row for -format template (see ParseTemplate)
+	Hints       map[string]bool         // Hints overrides strategy choices such as streaming (see ParseHints)
+	Seed        int64                   // Seed makes RANDOM() and RANDOM_PICK() reproducible when non-zero
+	DateFormats []string                // DateFormats are extra Go time layouts tried before DefaultDateFormats
+	TableStyle  string                  // TableStyle names the border preset for table output (see ParseTableStyle)
+	MaxColWidth int                     // MaxColWidth caps the width of table columns, which fit their content when zero
+	NullString  string                  // NullString is written for missing values in results (empty by default)
+	EmptyAsNull bool                    // EmptyAsNull writes empty values of results as NullString too
+	QuoteStyle  string                  // QuoteStyle quotes the values of results written as CSV (see ParseQuoteStyle)
+	CRLF        bool                    // CRLF ends the lines of results, created files and rewrites with \r\n
+	Compress    bool                    // Compress gzips -output files whose name does not already ask for gzip or zstd
+	Totals      bool                    // Totals adds a footer row to table output with the sum of each number column (see totalsRow)
+	Wrap        bool                    // Wrap continues cells wider than their column on the next lines instead of cutting them short
+	Color       bool                    // Color styles table and record output with ANSI escapes (see colors.go)
+	MaxScanRows int                     // MaxScanRows fails reads of files with more data rows than this when non-zero
+	ForceTypes  map[string]series.Type  // ForceTypes overrides the inferred type of these columns (see ParseForceTypes)
+	Normalize   bool                    // Normalize trims, lowercases and underscores header names at load (see normalizeHeaders)
+	Renames     map[string]string       // Renames gives columns new names at load (see ParseRenames)
+	Allowed     map[string][]string     // Allowed restricts the values written to these columns (see ParseAllowedValues)
+	ResultRows  int                     // ResultRows counts the rows of every result printed or saved by PrintDataFrame
+	JSONSummary bool                    // JSONSummary replaces the messages of rewrites with Mutation, printed by the caller
+	Returning   bool                    // Returning prints the rows DELETE removes and UPDATE changes
+	Mutation    *MutationSummary        // Mutation is the outcome of the last rewrite of the input file (see reportMutation)
+	Workspace   *Workspace              // Workspace holds the tables FROM finds before files, nil without -workspace
+	Project     []string                // Project loads only these columns, for read-only queries that use no others
+	Tables      map[string]string       // Tables are the -table names that FROM and JOIN find before workspace tables and files
+	headerNames map[string]string       // headerNames maps normalized header names to the names in the file
+	highlight   []string                // highlight are the columns the last WHERE condition tested, colored in Color output
+	aggregates  []string                // aggregates are the result columns that print rounded to two decimals (see displayText)
+	domains     map[string][]string     // domains caches the allowed values of each column (see loadColumnRules)
+	declared    map[string]string       // declared caches the types declared for columns by create
+	random      *rand.Rand
 }
 
 // Initialize loads the CSV file and prepares the dataframe
 func (ops *CSVOperations) Initialize() error {
-	file, err := os.Open(ops.FilePath)
-	if err != nil {
+	data, err := readInput(ops.FilePath)
+	if err != nil && !(os.IsNotExist(err) && ops.Header != "") {
 		return fmt.Errorf("failed to open file: %v", err)
 	}
-	defer file.Close()
 
-	// Load CSV into DataFrame
-	df := dataframe.ReadCSV(file)
-	if df.Err != nil {
-		return fmt.Errorf("failed to read CSV: %v", df.Err)
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return err
+	}
+	ops.Dialect = dialect
+
+	// Empty files have no columns unless bootstrapped with -header
+	if len(records) == 0 {
+		if ops.Header != "" {
+			ops.Headers = ops.ParseColumns(ops.Header)
+			ops.DataFrame = ops.CreateEmptyDataFrame()
+		}
+		return nil
+	}
+
+	// Load records into DataFrame
+	records = ops.projectRecords(records)
+	options, err := ops.loadOptions(records[0])
+	if err != nil {
+		return err
+	}
+	df, err := RecordsToDataFrame(records, options...)
+	if err != nil {
+		return err
 	}
 
 	ops.DataFrame = df
 	ops.Headers = df.Names()
-	return nil
+	return ops.checkRenames()
+}
+
+// ReadRecords decodes and splits raw file contents using the detected (or overridden) dialect.
+// Files without a header row get synthetic column names prepended.
+func (ops *CSVOperations) ReadRecords(data []byte) ([][]string, Dialect, error) {
+	dialect, err := ops.DetectDialect(data)
+	if err != nil {
+		return nil, dialect, err
+	}
+
+	text, err := decodeBytes(data, dialect.Encoding)
+	if err != nil {
+		return nil, dialect, fmt.Errorf("failed to decode file: %v", err)
+	}
+	text = ops.skipLines(text)
+
+	var records [][]string
+	switch dialect.Source {
+	case "":
+		// Parsing stops just past -max-scan-rows, so oversized files fail without being parsed whole
+		limit := ops.scanRecordLimit(dialect)
+		if records, err = parseRecords(text, dialect, limit); err != nil {
+			return nil, dialect, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		if limit > 0 && len(records) > limit {
+			return nil, dialect, ops.checkScanRows(ops.MaxScanRows + 1)
+		}
+	case SourceFixedWidth:
+		records = fixedWidthRecords(text, ops.Widths)
+	default:
+		// Captures and scanner outputs become one row per request, port or finding
+		records, err := sourceRecords(text, dialect.Source)
+		if err != nil {
+			return nil, dialect, err
+		}
+		return records, dialect, ops.checkScanRows(len(records) - 1)
+	}
+
+	// Give headerless files synthetic column names
+	if !dialect.HasHeader && len(records) > 0 {
+		names, err := ops.headerlessNames(len(records[0]))
+		if err != nil {
+			return nil, dialect, err
+		}
+		records = append([][]string{names}, records...)
+	}
+	if len(records) > 0 {
+		if records[0], err = ops.readHeader(records[0]); err != nil {
+			return nil, dialect, err
+		}
+	}
+	if records, err = ops.checkRowWidths(records, dialect.Delimiter); err != nil {
+		return nil, dialect, err
+	}
+	if err := ops.checkScanRows(len(records) - 1); err != nil {
+		return nil, dialect, err
+	}
+
+	// Convert regional numbers and dates (e.g. 1.234,5 and 31.12.2024) before type inference
+	if ops.Locale != "" {
+		locale, err := ParseLocale(ops.Locale)
+		if err != nil {
+			return nil, dialect, err
+		}
+		records = locale.NormalizeRecords(records)
+	}
+
+	return records, dialect, nil
+}
+
+// DetectDialect sniffs delimiter, quoting, header and encoding from the start of the data
+// (unless disabled), then applies the -encoding, -widths, -delimiter, quoting, -no-header and
+// -names overrides
+func (ops *CSVOperations) DetectDialect(data []byte) (Dialect, error) {
+	dialect := DefaultDialect()
+	if !ops.NoSniff {
+		sample := data
+		if len(sample) > SniffSampleSize {
+			sample = sample[:SniffSampleSize]
+		}
+		dialect = Sniff(sample)
+	}
+
+	// A forced encoding replaces the detected one, which the other guesses were made in
+	encoding := dialect.Encoding
+	if ops.Encoding != "" {
+		forced, err := ParseEncoding(ops.Encoding)
+		if err != nil {
+			return dialect, err
+		}
+		switch {
+		case forced == "utf-16" && encoding == "utf-16be":
+		case forced == "utf-16":
+			encoding = "utf-16le"
+		case forced == "utf-8" && encoding == "utf-8-bom":
+			// The byte order mark is still dropped
+		default:
+			encoding = forced
+		}
+	}
+
+	// Guesses made in another encoding, or thrown off by banner and comment lines, are made
+	// again on the decoded text
+	if !ops.NoSniff && (encoding != dialect.Encoding || ops.skipsLines()) {
+		sample := data
+		if len(sample) > SniffSampleSize {
+			sample = sample[:SniffSampleSize]
+		}
+		if text, err := decodeBytes(sample, encoding); err == nil {
+			if source := detectSource(text); source != "" {
+				dialect = Dialect{Delimiter: ',', Quote: '"', HasHeader: true, Source: source}
+			} else {
+				dialect = Sniff([]byte(ops.skipLines(text)))
+			}
+		}
+	}
+	dialect.Encoding = encoding
+	// Rewrites keep the line endings of the file, judged by its first line break, unless -crlf
+	// asks for CRLF
+	dialect.CRLF = ops.CRLF || hasCRLF(data, encoding)
+
+	// Fixed-width lines have no delimiter to sniff, and start with a header unless told otherwise
+	if len(ops.Widths) > 0 {
+		dialect = Dialect{Delimiter: ',', Quote: '"', HasHeader: true, Encoding: dialect.Encoding, Source: SourceFixedWidth}
+	}
+	if ops.Delimiter != "" {
+		delim, err := ParseDelimiter(ops.Delimiter)
+		if err != nil {
+			return dialect, err
+		}
+		dialect.Delimiter = delim
+	}
+	if ops.QuoteChar != "" {
+		quote, err := ParseQuoteChar(ops.QuoteChar)
+		if err != nil {
+			return dialect, err
+		}
+		dialect.Quote = quote
+	}
+	if ops.EscapeChar != "" {
+		escape, err := ParseQuoteChar(ops.EscapeChar)
+		if err != nil {
+			return dialect, err
+		}
+		dialect.Escape = escape
+		// An escape equal to the quote is the usual doubled quote
+		if dialect.Escape == dialect.Quote {
+			dialect.Escape = 0
+		}
+	}
+	dialect.LazyQuotes = ops.LazyQuotes
+	if dialect.Quote != 0 && dialect.Quote == dialect.Delimiter {
+		return dialect, fmt.Errorf("the quote character cannot also be the delimiter (%q)", dialect.Quote)
+	}
+	if ops.NoHeader || len(ops.Names) > 0 {
+		dialect.HasHeader = false
+	}
+	return dialect, nil
+}
+
+// LoadFile reads another CSV file (e.g. a source for copy or join) with the same dialect options as the input
+func (ops *CSVOperations) LoadFile(path string) (dataframe.DataFrame, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return dataframe.DataFrame{}, fmt.Errorf("failed to open file: %v", err)
+	}
+
+	records, _, err := ops.ReadRecords(data)
+	if err != nil {
+		return dataframe.DataFrame{}, err
+	}
+	if len(records) == 0 {
+		return dataframe.DataFrame{}, fmt.Errorf("file is empty: %s", path)
+	}
+
+	return RecordsToDataFrame(records)
+}
+
+// RecordsToDataFrame builds a dataframe from a header row followed by data rows
+func RecordsToDataFrame(records [][]string, options ...dataframe.LoadOption) (dataframe.DataFrame, error) {
+	// Header-only files load as an empty table with the same columns
+	if len(records) == 1 {
+		return NewStringDataFrame(records[0], nil), nil
+	}
+
+	df := dataframe.LoadRecords(records, options...)
+	if df.Err != nil {
+		return df, fmt.Errorf("failed to read CSV: %v", df.Err)
+	}
+	return df, nil
+}
+
+// NewStringDataFrame builds a dataframe of string columns from row-oriented values
+func NewStringDataFrame(headers []string, rows [][]string) dataframe.DataFrame {
+	seriesList := make([]series.Series, len(headers))
+	for j, header := range headers {
+		columnData := make([]string, len(rows))
+		for i, row := range rows {
+			if j < len(row) {
+				columnData[i] = row[j]
+			}
+		}
+		seriesList[j] = series.New(columnData, series.String, header)
+	}
+	return dataframe.New(seriesList...)
+}
+
+// CellText renders a cell the way it is printed and written to files. NULL is an empty cell,
+// rather than gota's "NaN", and floats keep their own digits instead of six decimals.
+func CellText(elem series.Element) string {
+	if elem.IsNA() {
+		return ""
+	}
+	if elem.Type() == series.Float {
+		return strconv.FormatFloat(elem.Float(), 'f', -1, 64)
+	}
+	return elem.String()
+}
+
+// displayText renders a cell for stdout: as outputText, except that aggregate results are
+// rounded to two decimals like FormatAggregateValue
+func (ops *CSVOperations) displayText(df dataframe.DataFrame, row, col int) string {
+	elem := df.Elem(row, col)
+	if elem.Type() == series.Float && !elem.IsNA() && slices.Contains(ops.aggregates, df.Names()[col]) {
+		return FormatAggregateValue(elem.Float())
+	}
+	return ops.outputText(elem)
+}
+
+// outputText renders a cell of a result: as CellText, except that missing values, and empty
+// ones with -empty-as-null, are written as -null-string. Rewrites of the input use CellText.
+func (ops *CSVOperations) outputText(elem series.Element) string {
+	text := CellText(elem)
+	if elem.IsNA() || (ops.EmptyAsNull && text == "") {
+		return ops.NullString
+	}
+	return text
+}
+
+// RowValues returns the values of a dataframe row formatted as strings
+func RowValues(df dataframe.DataFrame, rowIndex int) []string {
+	values := make([]string, df.Ncol())
+	for j := range values {
+		values[j] = CellText(df.Elem(rowIndex, j))
+	}
+	return values
+}
+
+// dataFrameRecords returns the header and rows of a dataframe as CellText strings
+func dataFrameRecords(df dataframe.DataFrame) [][]string {
+	records := make([][]string, 0, df.Nrow()+1)
+	records = append(records, df.Names())
+	for i := 0; i < df.Nrow(); i++ {
+		records = append(records, RowValues(df, i))
+	}
+	return records
+}
+
+// outputRecords returns the header and rows of a result as outputText strings
+func (ops *CSVOperations) outputRecords(df dataframe.DataFrame) [][]string {
+	records := make([][]string, 0, df.Nrow()+1)
+	records = append(records, df.Names())
+	for i := 0; i < df.Nrow(); i++ {
+		row := make([]string, df.Ncol())
+		for j := range row {
+			row[j] = ops.outputText(df.Elem(i, j))
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// IsEmpty reports whether the input file has no columns at all
+func (ops *CSVOperations) IsEmpty() bool {
+	return len(ops.Headers) == 0
 }
 
 // ShowColumns displays all column headers
 func (ops *CSVOperations) ShowColumns() error {
+	if ops.IsEmpty() {
+		fmt.Println("No columns: the CSV file is empty.")
+		return nil
+	}
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
 	fmt.Println("Columns in CSV file:")
 	for i, col := range ops.Headers {
-		fmt.Printf("%d: %s\n", i+1, col)
+		// Normalized names are listed with the header they were read from
+		orig := ops.originalHeader(col)
+		if orig != col {
+			fmt.Printf("%d: %s (%q)%s\n", i+1, col, orig, columnAnnotation(meta, orig))
+			continue
+		}
+		fmt.Printf("%d: %s%s\n", i+1, col, columnAnnotation(meta, col))
 	}
 	return nil
 }
@@ -76,12 +446,76 @@ func (ops *CSVOperations) ParseColumns(colStr string) []string {
 
 // ApplyWhereCondition filters the dataframe based on WHERE condition
 func (ops *CSVOperations) ApplyWhereCondition(df dataframe.DataFrame, whereCondition string) (dataframe.DataFrame, error) {
+	ops.highlight = nil
 	if whereCondition == "" {
 		return df, nil
 	}
 
-	// Parse simple WHERE conditions like "age > 30", "name = 'John'", etc.
-	return ops.parseAndApplyFilter(df, whereCondition)
+	// Conditions the SQL parser cannot read (e.g. unquoted values like *.example.com)
+	// fall back to the simple "column op value" splitter
+	expr, err := sqlparser.ParseExpr(whereCondition)
+	if err != nil {
+		return ops.parseAndApplyFilter(df, whereCondition)
+	}
+	// Colored output highlights the columns the condition tests
+	ops.highlight, _ = exprColumns(expr)
+
+	// Compare against values written the same way the loaded data was normalized
+	if ops.Locale != "" {
+		locale, err := ParseLocale(ops.Locale)
+		if err != nil {
+			return df, err
+		}
+		locale.normalizeLiterals(expr)
+	}
+
+	// Simple comparisons keep using gota's typed filters, except against dates, which the
+	// evaluator compares chronologically rather than as text
+	evaluator := ops.evaluator(df)
+	if err := ops.materializeSubqueries(evaluator, expr); err != nil {
+		return df, err
+	}
+	if column, operator, value, ok := simpleComparison(expr); ok {
+		if _, isDate := evaluator.parseDate(value); !isDate || indexOf(df.Names(), column) < 0 || df.Col(column).Type() != series.String {
+			return ops.applyComparison(df, column, operator, value)
+		}
+	}
+
+	// Compound conditions (AND/OR/NOT, parentheses, expressions) are evaluated row by row
+	return FilterExpr(evaluator, expr)
+}
+
+// simpleComparison matches "column op value" expressions, returning their parts
+func simpleComparison(expr sqlparser.Expr) (string, string, string, bool) {
+	binary, ok := expr.(*sqlparser.BinaryExpr)
+	if !ok {
+		return "", "", "", false
+	}
+	switch binary.Op {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		return "", "", "", false
+	}
+
+	col, ok := binary.Left.(*sqlparser.ColumnRef)
+	if !ok || col.Table != "" {
+		return "", "", "", false
+	}
+
+	switch right := binary.Right.(type) {
+	case *sqlparser.Literal:
+		if right.Kind == sqlparser.NullLiteral {
+			return "", "", "", false
+		}
+		return col.Name, binary.Op, right.Value, true
+	case *sqlparser.ColumnRef:
+		// Bare words are compared as text, as in "status = active"
+		if right.Table != "" {
+			return "", "", "", false
+		}
+		return col.Name, binary.Op, right.Name, true
+	}
+	return "", "", "", false
 }
 
 // parseAndApplyFilter parses and applies filter conditions
@@ -110,9 +544,22 @@ func (ops *CSVOperations) parseAndApplyFilter(df dataframe.DataFrame, condition
 		return df, fmt.Errorf("invalid WHERE condition: %s", condition)
 	}
 
-	// Validate column exists
-	if err := ops.ValidateColumns([]string{column}); err != nil {
-		return df, err
+	return ops.applyComparison(df, column, operator, value)
+}
+
+// applyComparison filters the dataframe with a single typed comparison
+func (ops *CSVOperations) applyComparison(df dataframe.DataFrame, column, operator, value string) (dataframe.DataFrame, error) {
+	// Validate column exists in the dataframe being filtered
+	if indexOf(df.Names(), column) < 0 {
+		return df, fmt.Errorf("column '%s' does not exist in CSV", column)
+	}
+
+	if ops.Locale != "" {
+		locale, err := ParseLocale(ops.Locale)
+		if err != nil {
+			return df, err
+		}
+		value = locale.NormalizeValue(value)
 	}
 
 	// Apply filter based on operator
@@ -134,39 +581,69 @@ func (ops *CSVOperations) parseAndApplyFilter(df dataframe.DataFrame, condition
 	}
 }
 
-// ApplyOrderBy sorts the dataframe
-func (ops *CSVOperations) ApplyOrderBy(df dataframe.DataFrame, orderBy string) (dataframe.DataFrame, error) {
-	if orderBy == "" {
-		return df, nil
-	}
-
+// splitOrderBy splits an ORDER BY clause "column [asc|desc]" into the column and whether it
+// sorts in descending order. The column may be an aggregate with spaces, as in SUM(price * qty).
+func splitOrderBy(orderBy string) (string, bool, error) {
+	orderBy = strings.TrimSpace(orderBy)
 	parts := strings.Fields(orderBy)
 	if len(parts) == 0 {
-		return df, fmt.Errorf("empty ORDER BY clause")
+		return "", false, fmt.Errorf("empty ORDER BY clause")
+	}
+	if len(parts) == 1 {
+		return orderBy, false, nil
 	}
 
-	column := parts[0]
-	ascending := true
+	last := parts[len(parts)-1]
+	switch strings.ToLower(last) {
+	case "asc":
+		return strings.TrimSpace(strings.TrimSuffix(orderBy, last)), false, nil
+	case "desc":
+		return strings.TrimSpace(strings.TrimSuffix(orderBy, last)), true, nil
+	}
+	if len(parts) == 2 && !strings.Contains(parts[0], "(") {
+		return "", false, fmt.Errorf("invalid ORDER BY direction: %s (use 'asc' or 'desc')", last)
+	}
+	return orderBy, false, nil
+}
 
-	if len(parts) > 1 {
-		direction := strings.ToLower(parts[1])
-		if direction == "desc" {
-			ascending = false
-		} else if direction != "asc" {
-			return df, fmt.Errorf("invalid ORDER BY direction: %s (use 'asc' or 'desc')", parts[1])
+// orderByKeys splits an ORDER BY list "col1 [asc|desc], col2 ..." into its sort keys. Commas
+// inside aggregates such as SUM_IF(amount, region = 'EU') do not separate keys.
+func orderByKeys(orderBy string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range splitTopLevel(orderBy) {
+		column, descending, err := splitOrderBy(part)
+		if err != nil {
+			return nil, err
 		}
+		keys = append(keys, SortKey{Column: column, Desc: descending})
 	}
+	return keys, nil
+}
 
-	// Validate column exists
-	if err := ops.ValidateColumns([]string{column}); err != nil {
+// ApplyOrderBy sorts the dataframe by each key of the ORDER BY list in turn
+func (ops *CSVOperations) ApplyOrderBy(df dataframe.DataFrame, orderBy string) (dataframe.DataFrame, error) {
+	if orderBy == "" {
+		return df, nil
+	}
+
+	keys, err := orderByKeys(orderBy)
+	if err != nil {
 		return df, err
 	}
 
-	if ascending {
-		return df.Arrange(dataframe.Sort(column)), nil
-	} else {
-		return df.Arrange(dataframe.RevSort(column)), nil
+	orders := make([]dataframe.Order, len(keys))
+	for i, key := range keys {
+		// Validate column exists (derived columns only exist in the result being sorted)
+		if indexOf(df.Names(), key.Column) < 0 {
+			return df, fmt.Errorf("column '%s' does not exist in CSV", key.Column)
+		}
+		if key.Desc {
+			orders[i] = dataframe.RevSort(key.Column)
+		} else {
+			orders[i] = dataframe.Sort(key.Column)
+		}
 	}
+	return df.Arrange(orders...), nil
 }
 
 // ApplyLimit limits the number of rows
@@ -181,27 +658,53 @@ func (ops *CSVOperations) ApplyLimit(df dataframe.DataFrame, limit int) datafram
 	return df.Subset(indices)
 }
 
-// PrintDataFrame prints the dataframe in a formatted table or saves to file
-func (ops *CSVOperations) PrintDataFrame(df dataframe.DataFrame) {
+// PrintDataFrame prints the dataframe in a formatted table or saves to file. Only saving,
+// writing raw output and rendering a -template can fail.
+func (ops *CSVOperations) PrintDataFrame(df dataframe.DataFrame) error {
+	df = ops.withOriginalHeaders(ops.VisibleColumns(df))
+	ops.ResultRows += df.Nrow()
+
 	// If output file is specified, save to file instead of printing
 	if ops.OutputFile != "" {
-		if ops.RawOutput {
+		if ops.Format == FormatSQLite {
+			// -format sqlite writes a table of a SQLite database
+			err := ops.SaveSQLite(df, ops.OutputFile)
+			if err != nil {
+				return fmt.Errorf("failed to save results: %v", err)
+			}
+			fmt.Printf("Results saved to: %s (table %s)\n", ops.OutputFile, ops.sqliteTable())
+			return nil
+		} else if ops.Format == FormatTemplate {
+			// -format template writes the rendered rows
+			err := ops.saveTemplate(df, ops.OutputFile)
+			if err != nil {
+				return fmt.Errorf("failed to save results: %v", err)
+			}
+		} else if format := captureExportFormat(strings.TrimSuffix(strings.TrimSuffix(ops.OutputFile, ".gz"), ".zst")); format != "" {
+			// .har and .xml files are written as HAR and Burp Suite XML captures
+			err := ops.SaveCapture(df, ops.OutputFile, format)
+			if err != nil {
+				return fmt.Errorf("failed to save results: %v", err)
+			}
+		} else if ops.RawOutput {
 			// For raw output, save as CSV without headers
 			err := ops.SaveDataFrameToFile(df, ops.OutputFile, false)
 			if err != nil {
-				fmt.Printf("Error saving to file: %v\n", err)
-				return
+				return fmt.Errorf("failed to save results: %v", err)
 			}
 		} else {
 			// For formatted output, save as CSV with headers
 			err := ops.SaveDataFrameToFile(df, ops.OutputFile, true)
 			if err != nil {
-				fmt.Printf("Error saving to file: %v\n", err)
-				return
+				return fmt.Errorf("failed to save results: %v", err)
 			}
 		}
-		fmt.Printf("Results saved to: %s\n", ops.OutputFile)
-		return
+		fmt.Printf("Results saved to: %s\n", ops.Workspace.Describe(ops.OutputFile))
+		return nil
+	}
+
+	if ops.Format == FormatTemplate {
+		return ops.writeTemplate(os.Stdout, df, false)
 	}
 
 	// Original stdout printing logic
@@ -209,80 +712,103 @@ func (ops *CSVOperations) PrintDataFrame(df dataframe.DataFrame) {
 		if !ops.RawOutput {
 			fmt.Println("No rows to display.")
 		}
-		return
+		return nil
+	}
+
+	// Long or wide rows are easier to read one record at a time
+	if !ops.RawOutput && (ops.Format == FormatRecord || (ops.Wide && ops.tableStyle().Width(ops.columnWidths(ops.tableCells(df))) > TerminalWidth())) {
+		ops.PrintVertical(df)
+		return nil
 	}
 
-	headers := df.Names()
-	
 	if !ops.RawOutput {
-		// Print headers
-		for i, header := range headers {
-			if i > 0 {
-				fmt.Print(" | ")
-			}
-			fmt.Printf("%-15s", header)
-		}
-		fmt.Println()
-		
-		// Print separator line
-		for i := range headers {
-			if i > 0 {
-				fmt.Print("-+-")
-			}
-			fmt.Print(strings.Repeat("-", 15))
-		}
-		fmt.Println()
+		ops.PrintTable(df)
+		return nil
 	}
 
-	// Print data rows
-	for i := 0; i < df.Nrow(); i++ {
-		for j := 0; j < df.Ncol(); j++ {
-			if j > 0 {
-				if ops.RawOutput {
-					fmt.Print(",")
-				} else {
-					fmt.Print(" | ")
-				}
-			}
-			val := df.Elem(i, j)
-			if ops.RawOutput {
-				fmt.Printf("%v", val)
-			} else {
-				fmt.Printf("%-15s", fmt.Sprintf("%v", val))
-			}
+	// Raw output is CSV (or -out-delimiter) without headers
+	rows := make([][]string, df.Nrow())
+	for i := range rows {
+		rows[i] = make([]string, df.Ncol())
+		for j := range rows[i] {
+			rows[i][j] = ops.displayText(df, i, j)
 		}
-		fmt.Println()
 	}
+	return ops.writeResults(os.Stdout, rows)
 }
 
 // SaveDataFrameToFile saves the dataframe to a file with options for headers
 func (ops *CSVOperations) SaveDataFrameToFile(df dataframe.DataFrame, filename string, includeHeaders bool) error {
-	file, err := os.Create(filename)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	file, err := ops.createOutput(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return err
 	}
-	defer file.Close()
 
+	records := ops.outputRecords(df)
 	if !includeHeaders {
 		// Write only data rows without headers
-		for i := 0; i < df.Nrow(); i++ {
-			for j := 0; j < df.Ncol(); j++ {
-				if j > 0 {
-					fmt.Fprint(file, ",")
-				}
-				val := df.Elem(i, j)
-				fmt.Fprintf(file, "%v", val)
-			}
-			fmt.Fprintln(file)
-		}
-		return nil
+		records = records[1:]
+	}
+	if err := ops.writeResults(file, records); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write CSV: %v", err)
 	}
+	return file.Close()
+}
 
-	// Write with headers (default CSV format)
-	return df.WriteCSV(file)
+// outDelimiter returns the field separator of results, which -out-delimiter sets apart from
+// the input's delimiter
+func (ops *CSVOperations) outDelimiter() rune {
+	if ops.OutDelimiter == 0 {
+		return ','
+	}
+	return ops.OutDelimiter
 }
 
-// SaveDataFrameToCSV saves the dataframe back to CSV (backward compatibility)
+// SaveDataFrameToCSV saves the dataframe back to CSV, keeping the source file's dialect
 func (ops *CSVOperations) SaveDataFrameToCSV(df dataframe.DataFrame, filename string) error {
-	return ops.SaveDataFrameToFile(df, filename, true)
-}
\ No newline at end of file
+	if err := sourceWriteError(filename, ops.Dialect); err != nil {
+		return err
+	}
+	records := dataFrameRecords(ops.withOriginalHeaders(df))
+	// Headerless files got synthetic column names on read; a file without a dialect, such as
+	// one bootstrapped with -header, keeps its header
+	if ops.Dialect.Delimiter != 0 && !ops.Dialect.HasHeader && len(records) > 0 {
+		records = records[1:]
+	}
+	return writeEncoded(filename, records, ops.Dialect)
+}
+
+// writeEncoded writes records to filename with the dialect's delimiter and encoding, so a
+// rewrite keeps a latin1 file latin1 and a UTF-8 file's byte order mark
+func writeEncoded(filename string, records [][]string, dialect Dialect) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if dialect.Delimiter != 0 {
+		writer.Comma = dialect.Delimiter
+	}
+	writer.UseCRLF = dialect.CRLF
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	data, err := encodeBytes(buf.String(), dialect.Encoding)
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return file.Close()
+}
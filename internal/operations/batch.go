@@ -0,0 +1,116 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BatchQuery is one statement of a batch and the file its result is saved to ("" for stdout)
+type BatchQuery struct {
+	SQL    string
+	Output string
+}
+
+// ParseQueryFile reads SQL statements separated by semicolons. A "-- output: path" comment
+// line sets the output file of the statement that follows; other "--" comment lines are ignored.
+func ParseQueryFile(path string) ([]BatchQuery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %v", err)
+	}
+
+	var queries []BatchQuery
+	output := ""
+	for _, statement := range splitStatements(string(data)) {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if !strings.HasPrefix(trimmed, "--") {
+				lines = append(lines, line)
+				continue
+			}
+			comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "--"))
+			if len(comment) >= 7 && strings.EqualFold(comment[:7], "output:") {
+				output = strings.TrimSpace(comment[7:])
+			}
+		}
+		sql := strings.TrimSpace(strings.Join(lines, "\n"))
+		if sql == "" {
+			continue
+		}
+		queries = append(queries, BatchQuery{SQL: sql, Output: output})
+		output = ""
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("query file %s contains no statements", path)
+	}
+	return queries, nil
+}
+
+// splitStatements splits SQL text on semicolons outside quotes and "--" comments
+func splitStatements(text string) []string {
+	var statements []string
+	var quote rune
+	comment := false
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case comment:
+			comment = r != '\n'
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			comment = true
+		case r == ';':
+			statements = append(statements, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	return append(statements, string(runes[start:]))
+}
+
+// RunBatch executes queries one after another against the file loaded once by Initialize.
+// Each result goes to the query's own output file, or to stdout under a "-- query N" heading.
+func (ops *CSVOperations) RunBatch(queries []BatchQuery) error {
+	outputFile := ops.OutputFile
+	defer func() { ops.OutputFile = outputFile }()
+
+	for i, query := range queries {
+		// Every statement reads the shared load, so its (first) FROM may only name the input file
+		_, stmt, err := ParseQuery(query.SQL)
+		if err != nil {
+			return fmt.Errorf("query %d: query error: %v", i+1, err)
+		}
+		if stmt.From.Name != "" {
+			path, err := ops.resolveTable(stmt.From.Name)
+			if err != nil {
+				return fmt.Errorf("query %d: %v", i+1, err)
+			}
+			if filepath.Clean(path) != filepath.Clean(ops.FilePath) {
+				return fmt.Errorf("query %d: batch queries read %s only, but FROM names %s", i+1, ops.FilePath, path)
+			}
+		}
+
+		ops.OutputFile = query.Output
+		if len(queries) > 1 && query.Output == "" && !ops.RawOutput {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("-- query %d: %s\n", i+1, query.SQL)
+		}
+		if err := ops.Query(query.SQL); err != nil {
+			if len(queries) > 1 {
+				return fmt.Errorf("query %d: %v", i+1, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package operations
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Manifest describes a signed export: which file, its SHA-256 and how many data rows it holds
+type Manifest struct {
+	File      string `json:"file"`
+	SHA256    string `json:"sha256"`
+	Rows      int    `json:"rows"`
+	Header    bool   `json:"header"`
+	Algorithm string `json:"algorithm"`
+	Created   string `json:"created"`
+}
+
+// ManifestPath returns the manifest written next to a signed file, e.g. out.csv.manifest.json
+func ManifestPath(path string) string {
+	return path + ".manifest.json"
+}
+
+// SignaturePath returns the detached signature of a file's manifest, e.g. out.csv.sig
+func SignaturePath(path string) string {
+	return path + ".sig"
+}
+
+// SignBundle writes a manifest for an exported file and signs it with the PEM private key
+// (Ed25519, RSA or ECDSA) at keyPath. The signature covers the manifest, which pins the file hash.
+func SignBundle(path string, header bool, keyPath string) error {
+	signer, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	sum, rows, err := fileDigest(path, header)
+	if err != nil {
+		return err
+	}
+	manifest := Manifest{
+		File:      filepath.Base(path),
+		SHA256:    sum,
+		Rows:      rows,
+		Header:    header,
+		Algorithm: signatureAlgorithm(signer.Public()),
+		Created:   time.Now().UTC().Format(time.RFC3339),
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	data = append(data, '\n')
+
+	digest, opts := signingInput(data, signer.Public())
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return fmt.Errorf("failed to sign manifest: %v", err)
+	}
+
+	if err := os.WriteFile(ManifestPath(path), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := os.WriteFile(SignaturePath(path), []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %v", err)
+	}
+
+	fmt.Printf("Signed %s (%d rows): %s, %s\n", path, rows, ManifestPath(path), SignaturePath(path))
+	return nil
+}
+
+// VerifyBundle checks the manifest signature with the PEM public key at keyPath, then checks
+// that the file still matches the hash and row count recorded in the manifest
+func VerifyBundle(path, keyPath string) error {
+	publicKey, err := loadPublicKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(ManifestPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %v", err)
+	}
+	encoded, err := os.ReadFile(SignaturePath(path))
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %v", err)
+	}
+	if !verifySignature(publicKey, data, signature) {
+		return fmt.Errorf("signature verification failed: %s was not signed by this key or was modified", ManifestPath(path))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	sum, rows, err := fileDigest(path, manifest.Header)
+	if err != nil {
+		return err
+	}
+	if sum != manifest.SHA256 {
+		return fmt.Errorf("hash mismatch: %s has changed since it was signed", path)
+	}
+	if rows != manifest.Rows {
+		return fmt.Errorf("row count mismatch: manifest has %d rows, file has %d", manifest.Rows, rows)
+	}
+
+	fmt.Printf("Verified %s: signature valid, %d rows, sha256 %s\n", path, rows, sum)
+	return nil
+}
+
+// fileDigest returns the hex SHA-256 of a file and the number of CSV data rows in it
+func fileDigest(path string, header bool) (string, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	reader := csv.NewReader(bufio.NewReader(io.TeeReader(file, hash)))
+	reader.FieldsPerRecord = -1
+	rows := 0
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		rows++
+	}
+	// Drain anything the CSV reader did not consume so the hash covers the whole file
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", 0, fmt.Errorf("failed to read file: %v", err)
+	}
+	if header && rows > 0 {
+		rows--
+	}
+	return hex.EncodeToString(hash.Sum(nil)), rows, nil
+}
+
+// loadPrivateKey reads a PEM-encoded PKCS#8, PKCS#1 or SEC 1 private key
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+				return nil, fmt.Errorf("unsupported private key in %s", path)
+			}
+		}
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key in %s", path)
+	}
+	return signer, nil
+}
+
+// loadPublicKey reads a PEM-encoded public key, or takes the public half of a private key
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(block.Type, "PRIVATE") {
+		signer, err := loadPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return signer.Public(), nil
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key in %s", path)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
+
+// signatureAlgorithm names the scheme used for a key
+func signatureAlgorithm(key crypto.PublicKey) string {
+	switch key.(type) {
+	case ed25519.PublicKey:
+		return "ed25519"
+	case *rsa.PublicKey:
+		return "rsa-pkcs1v15-sha256"
+	case *ecdsa.PublicKey:
+		return "ecdsa-sha256"
+	}
+	return "unknown"
+}
+
+// signingInput returns what is passed to Sign: Ed25519 signs the message itself,
+// RSA and ECDSA sign its SHA-256 digest
+func signingInput(message []byte, key crypto.PublicKey) ([]byte, crypto.SignerOpts) {
+	if _, ok := key.(ed25519.PublicKey); ok {
+		return message, crypto.Hash(0)
+	}
+	sum := sha256.Sum256(message)
+	return sum[:], crypto.SHA256
+}
+
+func verifySignature(key crypto.PublicKey, message, signature []byte) bool {
+	digest, _ := signingInput(message, key)
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, message, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest, signature)
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package operations
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// captureColumns are the columns of a capture read as a table, one row per request.
+// Timings are in milliseconds and empty when the capture does not record them.
+var captureColumns = []string{
+	"started", "method", "url", "scheme", "host", "port", "path", "query", "status", "length", "mime",
+	"time_ms", "blocked_ms", "dns_ms", "connect_ms", "ssl_ms", "send_ms", "wait_ms", "receive_ms",
+}
+
+// burpTimeLayout is the format of the <time> element of Burp Suite exports
+const burpTimeLayout = "Mon Jan 02 15:04:05 MST 2006"
+
+// harFile is an HTTP Archive; only the fields seesv reads or writes are declared
+type harFile struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Cookies     []harPair `json:"cookies"`
+	Headers     []harPair `json:"headers"`
+	QueryString []harPair `json:"queryString"`
+	HeadersSize int       `json:"headersSize"`
+	BodySize    int       `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Cookies     []harPair  `json:"cookies"`
+	Headers     []harPair  `json:"headers"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int        `json:"bodySize"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// harTimings are pointers so that timings missing from a capture stay empty
+type harTimings struct {
+	Blocked *float64 `json:"blocked"`
+	DNS     *float64 `json:"dns"`
+	Connect *float64 `json:"connect"`
+	SSL     *float64 `json:"ssl"`
+	Send    *float64 `json:"send"`
+	Wait    *float64 `json:"wait"`
+	Receive *float64 `json:"receive"`
+}
+
+// burpItems is a Burp Suite XML export
+type burpItems struct {
+	XMLName     xml.Name   `xml:"items"`
+	BurpVersion string     `xml:"burpVersion,attr"`
+	ExportTime  string     `xml:"exportTime,attr"`
+	Items       []burpItem `xml:"item"`
+}
+
+type burpItem struct {
+	Time           string   `xml:"time"`
+	URL            string   `xml:"url"`
+	Host           burpHost `xml:"host"`
+	Port           string   `xml:"port"`
+	Protocol       string   `xml:"protocol"`
+	Method         string   `xml:"method"`
+	Path           string   `xml:"path"`
+	Extension      string   `xml:"extension"`
+	Request        burpBody `xml:"request"`
+	Status         string   `xml:"status"`
+	ResponseLength string   `xml:"responselength"`
+	MimeType       string   `xml:"mimetype"`
+	Response       burpBody `xml:"response"`
+	Comment        string   `xml:"comment"`
+}
+
+type burpHost struct {
+	IP   string `xml:"ip,attr"`
+	Name string `xml:",chardata"`
+}
+
+type burpBody struct {
+	Base64 string `xml:"base64,attr"`
+	Data   string `xml:",chardata"`
+}
+
+// harRecords reads an HTTP Archive as records, header first, with one row per request
+func harRecords(text string) ([][]string, error) {
+	var har harFile
+	if err := json.Unmarshal([]byte(text), &har); err != nil {
+		return nil, fmt.Errorf("failed to read HAR: %v", err)
+	}
+	records := [][]string{captureColumns}
+	for _, entry := range har.Log.Entries {
+		records = append(records, harRow(entry))
+	}
+	return records, nil
+}
+
+// burpRecords reads a Burp Suite XML export as records, header first, with one row per item
+func burpRecords(text string) ([][]string, error) {
+	var burp burpItems
+	if err := xml.Unmarshal([]byte(text), &burp); err != nil {
+		return nil, fmt.Errorf("failed to read Burp Suite XML: %v", err)
+	}
+	records := [][]string{captureColumns}
+	for _, item := range burp.Items {
+		records = append(records, burpRow(item))
+	}
+	return records, nil
+}
+
+// harRow flattens a HAR entry into a row of captureColumns
+func harRow(entry harEntry) []string {
+	scheme, host, port, path, query := splitURL(entry.Request.URL)
+	length := ""
+	if entry.Response.Content.Size >= 0 {
+		length = strconv.Itoa(entry.Response.Content.Size)
+	}
+	status := ""
+	if entry.Response.Status > 0 {
+		status = strconv.Itoa(entry.Response.Status)
+	}
+	timings := entry.Timings
+	return []string{
+		entry.StartedDateTime, entry.Request.Method, entry.Request.URL, scheme, host, port, path, query,
+		status, length, entry.Response.Content.MimeType, formatValue(entry.Time),
+		harTiming(timings.Blocked), harTiming(timings.DNS), harTiming(timings.Connect), harTiming(timings.SSL),
+		harTiming(timings.Send), harTiming(timings.Wait), harTiming(timings.Receive),
+	}
+}
+
+// harTiming formats a HAR timing; -1 means the phase does not apply
+func harTiming(value *float64) string {
+	if value == nil || *value < 0 {
+		return ""
+	}
+	return formatValue(*value)
+}
+
+// burpRow flattens a Burp Suite item into a row of captureColumns; Burp records no timings
+func burpRow(item burpItem) []string {
+	started := item.Time
+	if parsed, err := time.Parse(burpTimeLayout, item.Time); err == nil {
+		started = parsed.UTC().Format(time.RFC3339)
+	}
+	path, query, _ := strings.Cut(item.Path, "?")
+	row := []string{
+		started, item.Method, item.URL, item.Protocol, item.Host.Name, item.Port, path, query,
+		item.Status, item.ResponseLength, item.MimeType,
+	}
+	for len(row) < len(captureColumns) {
+		row = append(row, "")
+	}
+	return row
+}
+
+// splitURL returns the scheme, host, port (defaulted from the scheme), path and query of a URL
+func splitURL(raw string) (string, string, string, string, string) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", "", raw, ""
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	return u.Scheme, u.Hostname(), port, u.EscapedPath(), u.RawQuery
+}
+
+// captureExportFormat returns the capture format written for an output file: HAR for .har
+// and Burp Suite XML for .xml; other names are written as CSV
+func captureExportFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".har":
+		return SourceHAR
+	case ".xml":
+		return SourceBurp
+	}
+	return ""
+}
+
+// SaveCapture writes the rows of df as requests in a HAR or Burp Suite XML file. Columns are
+// matched by the names of captureColumns; the URL is taken from url or built from scheme,
+// host, port, path and query. Headers and bodies are not part of the table, so they are left empty.
+func (ops *CSVOperations) SaveCapture(df dataframe.DataFrame, filename, format string) error {
+	names := df.Names()
+	if indexOf(names, "url") < 0 && indexOf(names, "host") < 0 {
+		return fmt.Errorf("%s export needs a url or host column", sourceNames[format])
+	}
+	evaluator := ops.evaluator(df)
+	cell := func(row int, name string) string {
+		if col := indexOf(names, name); col >= 0 {
+			return formatValue(evaluator.cellValue(row, col))
+		}
+		return ""
+	}
+	number := func(row int, name string, missing float64) float64 {
+		if value, err := strconv.ParseFloat(cell(row, name), 64); err == nil {
+			return value
+		}
+		return missing
+	}
+	timing := func(row int, name string, missing float64) *float64 {
+		value := number(row, name, missing)
+		return &value
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case SourceHAR:
+		var har harFile
+		har.Log.Version = "1.2"
+		har.Log.Creator = harCreator{Name: "seesv", Version: "1"}
+		har.Log.Entries = []harEntry{}
+		for i := 0; i < df.Nrow(); i++ {
+			entry := harEntry{
+				StartedDateTime: cell(i, "started"),
+				Time:            number(i, "time_ms", 0),
+				Request: harRequest{Method: cell(i, "method"), URL: rowURL(cell, i), HTTPVersion: "HTTP/1.1",
+					Cookies: []harPair{}, Headers: []harPair{}, QueryString: []harPair{}, HeadersSize: -1, BodySize: -1},
+				Response: harResponse{Status: int(number(i, "status", 0)), HTTPVersion: "HTTP/1.1",
+					Cookies: []harPair{}, Headers: []harPair{}, HeadersSize: -1, BodySize: -1,
+					Content: harContent{Size: int(number(i, "length", 0)), MimeType: cell(i, "mime")}},
+				Timings: harTimings{
+					Blocked: timing(i, "blocked_ms", -1), DNS: timing(i, "dns_ms", -1), Connect: timing(i, "connect_ms", -1),
+					SSL: timing(i, "ssl_ms", -1), Send: timing(i, "send_ms", 0), Wait: timing(i, "wait_ms", 0),
+					Receive: timing(i, "receive_ms", 0),
+				},
+			}
+			har.Log.Entries = append(har.Log.Entries, entry)
+		}
+		data, err = json.MarshalIndent(har, "", "  ")
+
+	case SourceBurp:
+		burp := burpItems{BurpVersion: "seesv", ExportTime: time.Now().Format(burpTimeLayout)}
+		for i := 0; i < df.Nrow(); i++ {
+			started := cell(i, "started")
+			if parsed, ok := evaluator.parseDate(started); ok {
+				started = parsed.Format(burpTimeLayout)
+			}
+			u, _ := url.Parse(rowURL(cell, i))
+			port := u.Port()
+			if port == "" {
+				port = cell(i, "port")
+			}
+			burp.Items = append(burp.Items, burpItem{
+				Time: started, URL: u.String(), Host: burpHost{Name: u.Hostname()}, Port: port, Protocol: u.Scheme,
+				Method: cell(i, "method"), Path: u.RequestURI(), Extension: "null",
+				Request: burpBody{Base64: "true"}, Status: cell(i, "status"), ResponseLength: cell(i, "length"),
+				MimeType: cell(i, "mime"), Response: burpBody{Base64: "true"},
+			})
+		}
+		data, err = xml.MarshalIndent(burp, "", "  ")
+		data = append([]byte(xml.Header), data...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", sourceNames[format], err)
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	file, err := ops.createOutput(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %v", sourceNames[format], err)
+	}
+	return file.Close()
+}
+
+// rowURL returns the url column of a row, or builds the URL from its scheme, host, port,
+// path and query columns; default ports are left out
+func rowURL(cell func(int, string) string, row int) string {
+	if raw := cell(row, "url"); raw != "" {
+		return raw
+	}
+	scheme := cell(row, "scheme")
+	if scheme == "" {
+		scheme = "https"
+	}
+	raw := scheme + "://" + cell(row, "host")
+	if port := cell(row, "port"); port != "" && !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		raw += ":" + port
+	}
+	raw += cell(row, "path")
+	if query := cell(row, "query"); query != "" {
+		raw += "?" + query
+	}
+	return raw
+}
@@ -0,0 +1,85 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/oauth2/google"
+)
+
+// gcsReadScope is the OAuth scope needed to download Cloud Storage objects
+const gcsReadScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// IsObjectURL reports whether an input names an S3 (s3://) or Cloud Storage (gs://) object
+func IsObjectURL(name string) bool {
+	return strings.HasPrefix(name, "s3://") || strings.HasPrefix(name, "gs://")
+}
+
+// FetchObject downloads an s3://bucket/key or gs://bucket/key object into a temporary file,
+// signing in with the ambient credentials of the environment: for S3 the AWS SDK chain
+// (environment, shared config and profiles, web identity, instance roles), for Cloud Storage
+// Application Default Credentials. Only Timeout and MaxBytes of options apply.
+func FetchObject(objectURL string, options RemoteOptions) (string, error) {
+	scheme, rest, _ := strings.Cut(objectURL, "://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", fmt.Errorf("invalid object URL: %s (expected %s://bucket/key)", objectURL, scheme)
+	}
+
+	ctx := context.Background()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	if scheme == "s3" {
+		return fetchS3Object(ctx, objectURL, bucket, key, options.MaxBytes)
+	}
+	return fetchGCSObject(ctx, objectURL, bucket, key, options.MaxBytes)
+}
+
+func fetchS3Object(ctx context.Context, objectURL, bucket, key string, maxBytes int64) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS credentials: %v", err)
+	}
+	// A bucket outside us-east-1 needs its region set with AWS_REGION or the profile
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	object, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %v", objectURL, err)
+	}
+	defer object.Body.Close()
+	return spoolDownload(objectURL, object.Body, aws.ToInt64(object.ContentLength), maxBytes)
+}
+
+func fetchGCSObject(ctx context.Context, objectURL, bucket, key string, maxBytes int64) (string, error) {
+	client, err := google.DefaultClient(ctx, gcsReadScope)
+	if err != nil {
+		return "", fmt.Errorf("failed to load Google Cloud credentials: %v", err)
+	}
+
+	mediaURL := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media", url.PathEscape(bucket), url.PathEscape(key))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid object URL %s: %v", objectURL, err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %v", objectURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", objectURL, response.Status)
+	}
+	return spoolDownload(objectURL, response.Body, response.ContentLength, maxBytes)
+}
@@ -0,0 +1,101 @@
+package operations
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-gota/gota/series"
+)
+
+// CoercePolicy decides what happens to appended cells that do not fit the target column type
+type CoercePolicy string
+
+const (
+	CoerceStrict  CoercePolicy = "strict"   // fail the import on any mismatch
+	CoerceLossy   CoercePolicy = "lossy"    // convert where possible (e.g. 2.7 -> 2 for int columns), write the rest as is
+	CoerceSkipRow CoercePolicy = "skip-row" // drop rows containing a mismatched cell
+)
+
+// ParseCoercePolicy validates a -coerce flag value, defaulting to lossy
+func ParseCoercePolicy(value string) (CoercePolicy, error) {
+	switch CoercePolicy(strings.ToLower(value)) {
+	case "", CoerceLossy:
+		return CoerceLossy, nil
+	case CoerceStrict:
+		return CoerceStrict, nil
+	case CoerceSkipRow:
+		return CoerceSkipRow, nil
+	default:
+		return "", fmt.Errorf("invalid coerce policy: %s (use strict, lossy or skip-row)", value)
+	}
+}
+
+// CoercionIssue describes a cell that would change type or lose precision in the target column
+type CoercionIssue struct {
+	Row        int
+	Column     string
+	Value      string
+	TargetType series.Type
+	Lossy      bool // Lossy is set when the value converts but loses precision
+}
+
+func (issue CoercionIssue) String() string {
+	if issue.Lossy {
+		return fmt.Sprintf("row %d, column '%s': %q loses precision as %s", issue.Row, issue.Column, issue.Value, issue.TargetType)
+	}
+	return fmt.Sprintf("row %d, column '%s': %q is not a valid %s", issue.Row, issue.Column, issue.Value, issue.TargetType)
+}
+
+// CoerceRow checks a row of values against the target column types, converting lossy values in place.
+// The row number is only used for reporting.
+func (ops *CSVOperations) CoerceRow(row int, values map[string]string) []CoercionIssue {
+	var issues []CoercionIssue
+	for column, value := range values {
+		if indexOf(ops.Headers, column) < 0 || value == "" || ops.DataFrame.Nrow() == 0 {
+			continue
+		}
+
+		targetType := ops.DataFrame.Col(column).Type()
+		switch targetType {
+		case series.Int:
+			if _, err := strconv.Atoi(value); err == nil {
+				continue
+			}
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				issues = append(issues, CoercionIssue{Row: row, Column: column, Value: value, TargetType: targetType, Lossy: true})
+				values[column] = strconv.Itoa(int(math.Trunc(f)))
+				continue
+			}
+		case series.Float:
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				continue
+			}
+		case series.Bool:
+			if _, err := strconv.ParseBool(value); err == nil {
+				continue
+			}
+		default:
+			continue
+		}
+		issues = append(issues, CoercionIssue{Row: row, Column: column, Value: value, TargetType: targetType})
+	}
+	return issues
+}
+
+// PrintCoercionReport lists coercion issues, capped to keep the output readable
+func PrintCoercionReport(w io.Writer, issues []CoercionIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Type coercion report (%d cells):\n", len(issues))
+	for i, issue := range issues {
+		if i == 20 {
+			fmt.Fprintf(w, "  ... and %d more\n", len(issues)-20)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", issue)
+	}
+}
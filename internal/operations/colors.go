@@ -0,0 +1,43 @@
+package operations
+
+// ANSI escapes of colored table and record output
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"        // ansiBold marks the header row and record field names
+	ansiShade     = "\x1b[48;5;236m" // ansiShade is the background of every other table row
+	ansiHighlight = "\x1b[1;33m"     // ansiHighlight marks the cells of the columns WHERE tested
+)
+
+// rowColors returns the escapes of a table row when Color is set: one for the whole line and
+// one for each cell, empty for none. Rows count from 0, the first after the header; -1 is the
+// header itself.
+func (ops *CSVOperations) rowColors(headers []string, row int) (string, []string) {
+	if !ops.Color {
+		return "", nil
+	}
+	if row < 0 {
+		return ansiBold, nil
+	}
+	line := ""
+	if row%2 == 1 {
+		line = ansiShade
+	}
+	var cells []string
+	if len(ops.highlight) > 0 {
+		cells = make([]string, len(headers))
+		for j, header := range headers {
+			if indexOf(ops.highlight, header) >= 0 {
+				cells[j] = ansiHighlight
+			}
+		}
+	}
+	return line, cells
+}
+
+// colorize wraps text in an ANSI escape when Color is set
+func (ops *CSVOperations) colorize(text, color string) string {
+	if !ops.Color || color == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
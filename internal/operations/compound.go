@@ -0,0 +1,262 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// QueryCompound runs SELECTs combined with UNION, INTERSECT or EXCEPT, left to right. Each
+// SELECT reads the file named by its FROM (the input file when FROM is omitted), and result
+// columns are matched by name, in the order of the first SELECT.
+func (ops *CSVOperations) QueryCompound(stmt *sqlparser.CompoundStatement) error {
+	resultDF, err := ops.statementFrame(stmt)
+	if err != nil {
+		return fmt.Errorf("query error: %v", err)
+	}
+
+	if err := ops.PrintDataFrame(resultDF); err != nil {
+		return err
+	}
+	if !ops.RawOutput {
+		fmt.Printf("\n(%d rows)\n", resultDF.Nrow())
+	}
+	return nil
+}
+
+// statementFrame evaluates a plain SELECT, or SELECTs combined with set operations, into its
+// result table. Plain SELECTs are limited as in compound statements: no aggregates or GROUP BY.
+func (ops *CSVOperations) statementFrame(stmt sqlparser.Statement) (dataframe.DataFrame, error) {
+	var selects []*sqlparser.SelectStatement
+	var setOps []string
+	var orderItems []sqlparser.OrderItem
+	var limit int
+	switch s := stmt.(type) {
+	case *sqlparser.SelectStatement:
+		selects, orderItems, limit = []*sqlparser.SelectStatement{s}, s.OrderBy, s.Limit
+	case *sqlparser.CompoundStatement:
+		selects, setOps, orderItems, limit = s.Selects, s.Ops, s.OrderBy, s.Limit
+	}
+	orderBy, err := orderByClause(orderItems)
+	if err != nil {
+		return dataframe.DataFrame{}, err
+	}
+
+	var headers []string
+	var rows [][]string
+	for i, sel := range selects {
+		df, err := ops.selectFrame(sel)
+		if err != nil {
+			if len(selects) == 1 {
+				return df, err
+			}
+			return df, fmt.Errorf("SELECT %d: %v", i+1, err)
+		}
+		if i == 0 {
+			headers = df.Names()
+			rows, _ = ops.alignedRows(df, headers)
+			continue
+		}
+		next, err := ops.alignedRows(df, headers)
+		if err != nil {
+			return df, fmt.Errorf("%s: columns of SELECT %d do not match SELECT 1 (%v)", setOps[i-1], i+1, err)
+		}
+		rows = combineRows(setOps[i-1], rows, next)
+	}
+
+	resultDF, err := RecordsToDataFrame(append([][]string{headers}, rows...))
+	if err != nil {
+		return resultDF, err
+	}
+	resultDF, err = ops.ApplyOrderBy(resultDF, orderBy)
+	if err != nil {
+		return resultDF, fmt.Errorf("ORDER BY error: %v", err)
+	}
+	return ops.ApplyLimit(resultDF, limit), nil
+}
+
+// selectFrame evaluates a single SELECT without its ORDER BY and LIMIT into its result rows
+func (ops *CSVOperations) selectFrame(sel *sqlparser.SelectStatement) (dataframe.DataFrame, error) {
+	if len(sel.GroupBy) > 0 || sel.Having != nil {
+		return dataframe.DataFrame{}, fmt.Errorf("GROUP BY and HAVING are not supported in combined SELECTs or subqueries")
+	}
+	for _, item := range sel.Columns {
+		if call, ok := item.Expr.(*sqlparser.FuncCall); ok && indexOf(aggregateFunctions, call.Name) >= 0 {
+			return dataframe.DataFrame{}, fmt.Errorf("aggregate functions are not supported in combined SELECTs or subqueries")
+		}
+	}
+	if len(sel.Joins) > 0 {
+		return dataframe.DataFrame{}, fmt.Errorf("JOIN is not supported in combined SELECTs or subqueries")
+	}
+
+	df, source := ops.DataFrame, ops.FilePath
+	if sel.From.Name != "" {
+		path, err := ops.resolveTable(sel.From.Name)
+		if err != nil {
+			return df, err
+		}
+		if filepath.Clean(path) != filepath.Clean(ops.FilePath) {
+			if df, err = ops.LoadFile(path); err != nil {
+				return df, err
+			}
+			source = path
+		}
+	}
+
+	for _, item := range sel.Columns {
+		if col, ok := item.Expr.(*sqlparser.ColumnRef); ok && indexOf(df.Names(), col.Name) < 0 {
+			return df, fmt.Errorf("column '%s' does not exist in %s", col.Name, source)
+		}
+	}
+	if sel.Where != nil {
+		filtered, err := ops.ApplyWhereCondition(df, sel.Where.String())
+		if err != nil {
+			return df, fmt.Errorf("WHERE condition error: %v", err)
+		}
+		df = filtered
+	}
+	projected, err := ops.ProjectSelectItems(df, sel.Columns)
+	if err != nil {
+		return df, err
+	}
+	if sel.Qualify != nil {
+		if projected, err = ops.applyQualify(df, projected, sel.Qualify, sel.Columns); err != nil {
+			return df, err
+		}
+	}
+	df = projected
+
+	seen := make(map[string]bool)
+	for _, name := range df.Names() {
+		if seen[name] {
+			return df, fmt.Errorf("duplicate result column '%s' (use AS to rename it)", name)
+		}
+		seen[name] = true
+	}
+	if sel.Distinct {
+		df = ops.ApplyDistinct(df)
+	}
+	return df, nil
+}
+
+// alignedRows returns the rows of df with its columns in the order of headers, failing when
+// df does not have exactly the columns named by headers. Numbers are written canonically
+// (1.0 as 1), so rows from files with differently typed columns compare equal.
+func (ops *CSVOperations) alignedRows(df dataframe.DataFrame, headers []string) ([][]string, error) {
+	names := df.Names()
+	var missing, unexpected []string
+	for _, name := range headers {
+		if indexOf(names, name) < 0 {
+			missing = append(missing, name)
+		}
+	}
+	for _, name := range names {
+		if indexOf(headers, name) < 0 {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if len(missing) > 0 || len(unexpected) > 0 {
+		var problems []string
+		if len(missing) > 0 {
+			problems = append(problems, "missing "+strings.Join(missing, ", "))
+		}
+		if len(unexpected) > 0 {
+			problems = append(problems, "unexpected "+strings.Join(unexpected, ", "))
+		}
+		return nil, fmt.Errorf("expected %s: %s", strings.Join(headers, ", "), strings.Join(problems, "; "))
+	}
+
+	evaluator := ops.evaluator(df)
+	rows := make([][]string, df.Nrow())
+	for i := range rows {
+		rows[i] = make([]string, len(headers))
+		for j, name := range headers {
+			rows[i][j] = formatValue(evaluator.cellValue(i, indexOf(names, name)))
+		}
+	}
+	return rows, nil
+}
+
+// combineRows applies a set operation to the rows so far and the rows of the next SELECT.
+// Rows are compared by a SHA-256 hash of their values. With ALL, each row of the right side
+// matches at most one row of the left; without it, the result has no duplicate rows.
+func combineRows(op string, left, right [][]string) [][]string {
+	all := strings.HasSuffix(op, " ALL")
+	setOp := strings.TrimSuffix(op, " ALL")
+
+	var rows [][]string
+	if setOp == "UNION" {
+		rows = append(left, right...)
+	} else {
+		counts := make(map[[sha256.Size]byte]int)
+		for _, row := range right {
+			counts[hashFields(row)]++
+		}
+		for _, row := range left {
+			key := hashFields(row)
+			found := counts[key] > 0
+			if found && all {
+				counts[key]--
+			}
+			if found == (setOp == "INTERSECT") {
+				rows = append(rows, row)
+			}
+		}
+	}
+	if all {
+		return rows
+	}
+
+	seen := make(map[[sha256.Size]byte]bool)
+	unique := rows[:0]
+	for _, row := range rows {
+		key := hashFields(row)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, row)
+		}
+	}
+	return unique
+}
+
+// ApplySetFilter keeps the distinct rows of the loaded file that also appear (INTERSECT) or
+// do not appear (EXCEPT) in the file at path. Columns are matched by name, so both files
+// need the same columns, in any order; rows are compared by a hash of all their values.
+func (ops *CSVOperations) ApplySetFilter(path, op string) error {
+	other, err := ops.LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load %s file: %v", strings.ToLower(op), err)
+	}
+	otherRows, err := ops.alignedRows(other, ops.Headers)
+	if err != nil {
+		return fmt.Errorf("%s error: columns of %s do not match %s (%v)", op, path, ops.FilePath, err)
+	}
+	inOther := make(map[[sha256.Size]byte]bool)
+	for _, row := range otherRows {
+		inOther[hashFields(row)] = true
+	}
+
+	df := ops.DataFrame
+	rows, _ := ops.alignedRows(df, ops.Headers)
+	seen := make(map[[sha256.Size]byte]bool)
+	indices := []int{}
+	for i, row := range rows {
+		key := hashFields(row)
+		if seen[key] || inOther[key] != (op == "INTERSECT") {
+			continue
+		}
+		seen[key] = true
+		indices = append(indices, i)
+	}
+
+	if len(indices) == 0 {
+		ops.DataFrame = NewStringDataFrame(df.Names(), nil)
+	} else if len(indices) < df.Nrow() {
+		ops.DataFrame = df.Subset(indices)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic bytes that start gzip and zstd data, whatever the file is called
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressedReader reads the decompressed data of a file opened by openInput
+type compressedReader struct {
+	io.Reader
+	file  *os.File
+	close func()
+}
+
+func (r *compressedReader) Close() error {
+	if r.close != nil {
+		r.close()
+	}
+	return r.file.Close()
+}
+
+// openInput opens path for reading, decompressing gzip and zstd data on the fly. It reports
+// whether the file is compressed, since such files cannot be sought or rewritten in place.
+func openInput(path string) (io.ReadCloser, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, true, fmt.Errorf("failed to decompress %s: %v", path, err)
+		}
+		return &compressedReader{Reader: reader, file: file, close: func() { reader.Close() }}, true, nil
+	case bytes.HasPrefix(header, zstdMagic):
+		reader, err := zstd.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, true, fmt.Errorf("failed to decompress %s: %v", path, err)
+		}
+		return &compressedReader{Reader: reader, file: file, close: reader.Close}, true, nil
+	}
+	return &compressedReader{Reader: buffered, file: file}, false, nil
+}
+
+// readInput reads a whole input file, decompressing it when it is gzip or zstd compressed
+func readInput(path string) ([]byte, error) {
+	reader, _, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %v", path, err)
+	}
+	return data, nil
+}
+
+// IsCompressed reports whether the file at path holds gzip or zstd data
+func IsCompressed(path string) bool {
+	reader, compressed, err := openInput(path)
+	if err != nil {
+		return compressed
+	}
+	reader.Close()
+	return compressed
+}
+
+// compressedWriter compresses what is written to a file created by createOutput
+type compressedWriter struct {
+	io.Writer
+	file  *os.File
+	close func() error
+}
+
+// Close flushes the compressor, then closes the file
+func (w *compressedWriter) Close() error {
+	var err error
+	if w.close != nil {
+		err = w.close()
+		w.close = nil
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// outputCompression returns how an output file is compressed: zstd when its name ends in
+// .zst, gzip when it ends in .gz or when -compress asks for it, and not at all otherwise
+func (ops *CSVOperations) outputCompression(filename string) string {
+	switch {
+	case strings.HasSuffix(strings.ToLower(filename), ".zst"):
+		return "zstd"
+	case strings.HasSuffix(strings.ToLower(filename), ".gz"), ops.Compress:
+		return "gzip"
+	}
+	return ""
+}
+
+// createOutput creates an -output file, compressing what is written to it as
+// outputCompression says. Closing the writer finishes the compressed stream.
+func (ops *CSVOperations) createOutput(filename string) (io.WriteCloser, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %v", err)
+	}
+	switch ops.outputCompression(filename) {
+	case "gzip":
+		writer := gzip.NewWriter(file)
+		return &compressedWriter{Writer: writer, file: file, close: writer.Close}, nil
+	case "zstd":
+		writer, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create output file: %v", err)
+		}
+		return &compressedWriter{Writer: writer, file: file, close: writer.Close}, nil
+	}
+	return &compressedWriter{Writer: file, file: file}, nil
+}
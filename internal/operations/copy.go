@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CopyColumnSpec describes a column copy in the form "src.csv:col -> [dst.csv:]col"
+type CopyColumnSpec struct {
+	SourceFile   string
+	SourceColumn string
+	DestFile     string
+	DestColumn   string
+}
+
+// ParseCopyColumnSpec parses a copy specification like "src.csv:email -> dst.csv:email"
+func ParseCopyColumnSpec(spec string) (CopyColumnSpec, error) {
+	parts := strings.SplitN(spec, "->", 2)
+	if len(parts) != 2 {
+		return CopyColumnSpec{}, fmt.Errorf("invalid copy specification: %s (expected src.csv:col -> dst.csv:col)", spec)
+	}
+
+	source := strings.TrimSpace(parts[0])
+	sep := strings.LastIndex(source, ":")
+	if sep <= 0 || sep == len(source)-1 {
+		return CopyColumnSpec{}, fmt.Errorf("invalid copy source: %s (expected src.csv:col)", source)
+	}
+
+	result := CopyColumnSpec{
+		SourceFile:   strings.TrimSpace(source[:sep]),
+		SourceColumn: strings.TrimSpace(source[sep+1:]),
+	}
+
+	// The destination file is optional and defaults to -file
+	dest := strings.TrimSpace(parts[1])
+	if sep := strings.LastIndex(dest, ":"); sep >= 0 {
+		result.DestFile = strings.TrimSpace(dest[:sep])
+		result.DestColumn = strings.TrimSpace(dest[sep+1:])
+	} else {
+		result.DestColumn = dest
+	}
+
+	if result.DestColumn == "" {
+		return CopyColumnSpec{}, fmt.Errorf("invalid copy destination: %s", dest)
+	}
+	return result, nil
+}
+
+// CopyColumn backfills a column of the input file from a column of another file.
+// Rows are matched on the key column when onKey is set, otherwise by position.
+func (ops *CSVOperations) CopyColumn(spec CopyColumnSpec, onKey string) error {
+	if spec.DestFile != "" && spec.DestFile != ops.FilePath {
+		return fmt.Errorf("copy destination %s does not match input file %s", spec.DestFile, ops.FilePath)
+	}
+
+	srcDF, err := ops.LoadFile(spec.SourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to load source file: %v", err)
+	}
+
+	srcColumnIndex := indexOf(srcDF.Names(), spec.SourceColumn)
+	if srcColumnIndex < 0 {
+		return fmt.Errorf("column '%s' does not exist in %s", spec.SourceColumn, spec.SourceFile)
+	}
+
+	// Copy into an existing column or append a new one
+	headers := append([]string{}, ops.Headers...)
+	destColumnIndex := indexOf(headers, spec.DestColumn)
+	if destColumnIndex < 0 {
+		headers = append(headers, spec.DestColumn)
+		destColumnIndex = len(headers) - 1
+	}
+
+	rows := make([][]string, ops.DataFrame.Nrow())
+	for i := range rows {
+		rows[i] = RowValues(ops.DataFrame, i)
+		if len(rows[i]) < len(headers) {
+			rows[i] = append(rows[i], "")
+		}
+	}
+
+	copied := 0
+	if onKey == "" {
+		if srcDF.Nrow() != len(rows) {
+			return fmt.Errorf("positional copy requires equal row counts (source has %d, destination has %d); use -on to match by key", srcDF.Nrow(), len(rows))
+		}
+		for i := range rows {
+			rows[i][destColumnIndex] = CellText(srcDF.Elem(i, srcColumnIndex))
+			copied++
+		}
+	} else {
+		if err := ops.ValidateColumns([]string{onKey}); err != nil {
+			return err
+		}
+		srcKeyIndex := indexOf(srcDF.Names(), onKey)
+		if srcKeyIndex < 0 {
+			return fmt.Errorf("key column '%s' does not exist in %s", onKey, spec.SourceFile)
+		}
+
+		// Build a lookup of source values by key, rejecting ambiguous keys
+		lookup := make(map[string]string)
+		for i := 0; i < srcDF.Nrow(); i++ {
+			key := CellText(srcDF.Elem(i, srcKeyIndex))
+			if _, exists := lookup[key]; exists {
+				return fmt.Errorf("duplicate key '%s' in %s", key, spec.SourceFile)
+			}
+			lookup[key] = CellText(srcDF.Elem(i, srcColumnIndex))
+		}
+
+		destKeyIndex := indexOf(headers, onKey)
+		for i := range rows {
+			if value, ok := lookup[rows[i][destKeyIndex]]; ok {
+				rows[i][destColumnIndex] = value
+				copied++
+			}
+		}
+	}
+
+	updatedDF := NewStringDataFrame(headers, rows)
+	if err := ops.SaveDataFrameToCSV(updatedDF, ops.FilePath); err != nil {
+		return fmt.Errorf("failed to save updated CSV: %v", err)
+	}
+
+	ops.reportMutation("COPY", copied, copied, fmt.Sprintf("Successfully copied %d values from %s:%s into %s:%s", copied, spec.SourceFile, spec.SourceColumn, ops.FilePath, spec.DestColumn))
+	return nil
+}
+
+// indexOf returns the position of name in names, or -1 if it is not present
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
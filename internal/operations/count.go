@@ -0,0 +1,77 @@
+package operations
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// CountRows counts data rows with a buffered, quote-aware newline scanner, without building
+// a dataframe. Blank lines are ignored, as the CSV reader does.
+func (ops *CSVOperations) CountRows() (int, error) {
+	file, _, err := openInput(ops.FilePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 1<<20)
+	sample, _ := reader.Peek(SniffSampleSize)
+	dialect, err := ops.DetectDialect(sample)
+	if err != nil {
+		return 0, err
+	}
+
+	// Multi-byte encodings, captures, escaped quotes, skipped lines and bad rows cannot be
+	// scanned byte by byte
+	if dialect.Encoding == "utf-16le" || dialect.Encoding == "utf-16be" || dialect.Source != "" || dialect.Escape != 0 || ops.skipsLines() || ops.toleratesBadRows() {
+		data, err := readInput(ops.FilePath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open file: %v", err)
+		}
+		records, _, err := ops.ReadRecords(data)
+		if err != nil || len(records) == 0 {
+			return 0, err
+		}
+		return len(records) - 1, nil
+	}
+
+	quote := byte(dialect.Quote)
+	records, inQuotes, hasContent := 0, false, false
+	buf := make([]byte, 1<<20)
+	for {
+		n, err := reader.Read(buf)
+		for _, b := range buf[:n] {
+			switch {
+			case b == quote:
+				inQuotes = !inQuotes
+				hasContent = true
+			case b == '\n' && !inQuotes:
+				if hasContent {
+					records++
+				}
+				hasContent = false
+			case b != '\r':
+				hasContent = true
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read file: %v", err)
+		}
+		// The first record is the header
+		if err := ops.checkScanRows(records - 1); err != nil {
+			return 0, err
+		}
+	}
+	if hasContent {
+		records++
+	}
+
+	if dialect.HasHeader && records > 0 {
+		records--
+	}
+	return records, nil
+}
@@ -0,0 +1,116 @@
+package operations
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Create writes a new CSV file containing only the given header. Columns written as name:type,
+// e.g. "id:int,name:string,created_at:date", have their types recorded in the sidecar, where
+// INSERT and UPDATE check values against them.
+func (ops *CSVOperations) Create(header string) error {
+	if header == "" {
+		return fmt.Errorf("CREATE requires -header with comma-separated column names")
+	}
+
+	if _, err := os.Stat(ops.FilePath); err == nil {
+		return fmt.Errorf("file already exists: %s", ops.FilePath)
+	}
+
+	columns, types, err := parseSchema(ops.ParseColumns(header))
+	if err != nil {
+		return fmt.Errorf("CREATE validation failed: %v", err)
+	}
+	if err := ValidateHeader(columns); err != nil {
+		return fmt.Errorf("CREATE validation failed: %v", err)
+	}
+
+	delimiter := ','
+	if ops.Delimiter != "" {
+		delim, err := ParseDelimiter(ops.Delimiter)
+		if err != nil {
+			return err
+		}
+		delimiter = delim
+	}
+
+	file, err := os.Create(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = delimiter
+	writer.UseCRLF = ops.CRLF
+	if err := writer.WriteAll([][]string{columns}); err != nil {
+		return fmt.Errorf("failed to write header: %v", err)
+	}
+
+	if len(types) == 0 {
+		fmt.Printf("Successfully created %s with %d columns\n", ops.FilePath, len(columns))
+		return nil
+	}
+
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+	for column, colType := range types {
+		entry := meta.Columns[column]
+		entry.Type = colType
+		meta.Columns[column] = entry
+	}
+	if err := SaveMetadata(ops.FilePath, meta); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully created %s with %d columns (types in %s)\n", ops.FilePath, len(columns), SidecarPath(ops.FilePath))
+	return nil
+}
+
+// parseSchema splits "name:type" columns into the names and the declared types by name.
+// Columns without a type have none.
+func parseSchema(specs []string) ([]string, map[string]string, error) {
+	columns := make([]string, len(specs))
+	types := make(map[string]string)
+	for i, spec := range specs {
+		name, typeName, found := strings.Cut(spec, ":")
+		columns[i] = strings.TrimSpace(name)
+		if !found {
+			continue
+		}
+		colType, ok := declaredType(typeName)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid type for column '%s': %s (use int, float, string, bool or date)", columns[i], strings.TrimSpace(typeName))
+		}
+		types[columns[i]] = colType
+	}
+	return columns, types, nil
+}
+
+// declaredType returns the canonical name of a schema type: the -force-type names and date
+func declaredType(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "date" {
+		return name, true
+	}
+	colType, ok := forceTypeNames[name]
+	return string(colType), ok
+}
+
+// ValidateHeader checks that column names are non-empty and unique
+func ValidateHeader(columns []string) error {
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		if col == "" {
+			return fmt.Errorf("column names cannot be empty")
+		}
+		if seen[col] {
+			return fmt.Errorf("duplicate column name: %s", col)
+		}
+		seen[col] = true
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// DefaultDateFormats are the date layouts recognized without -date-formats. Ambiguous
+// day/month orders such as 01/02/2006 are left out; add them with -date-formats.
+var DefaultDateFormats = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006/01/02",
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	time.RFC1123,
+}
+
+// dateTokens map readable format tokens to Go layout elements, longest first
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"MMMM", "January",
+	"MMM", "Jan",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// ParseDateFormats parses a comma-separated list of date formats written either with
+// YYYY/MM/DD/HH/mm/ss tokens (e.g. DD.MM.YYYY) or as Go layouts (e.g. 02.01.2006)
+func ParseDateFormats(spec string) ([]string, error) {
+	var layouts []string
+	for _, format := range strings.Split(spec, ",") {
+		format = strings.TrimSpace(format)
+		if format == "" {
+			continue
+		}
+		layout := dateTokens.Replace(format)
+		if !strings.Contains(layout, "06") {
+			return nil, fmt.Errorf("invalid date format: %s (must include a year, e.g. YYYY)", format)
+		}
+		if _, err := time.Parse(layout, time.Date(2024, 12, 31, 23, 59, 58, 0, time.UTC).Format(layout)); err != nil {
+			return nil, fmt.Errorf("invalid date format: %s", format)
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts, nil
+}
+
+// parseDate parses text with the evaluator's date layouts, caching the result
+func (e *Evaluator) parseDate(text string) (time.Time, bool) {
+	if parsed, ok := e.dates[text]; ok {
+		return parsed, !parsed.IsZero()
+	}
+
+	var parsed time.Time
+	if value := strings.TrimSpace(text); strings.ContainsAny(value, "0123456789") {
+		for _, layout := range e.layouts {
+			if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+				parsed = t
+				break
+			}
+		}
+	}
+	e.dates[text] = parsed
+	return parsed, !parsed.IsZero()
+}
+
+// evalDateFunc evaluates DATE, YEAR, MONTH, DAY, DATEDIFF and NOW. Arguments that are not
+// dates give NULL.
+func (e *Evaluator) evalDateFunc(call *sqlparser.FuncCall, row int) (interface{}, error) {
+	want := 1
+	switch call.Name {
+	case "NOW":
+		want = 0
+	case "DATEDIFF":
+		want = 2
+	}
+	if len(call.Args) != want {
+		return nil, fmt.Errorf("%s() expects %d argument(s)", call.Name, want)
+	}
+
+	if call.Name == "NOW" {
+		if e.now == "" {
+			e.now = time.Now().Format("2006-01-02 15:04:05")
+		}
+		return e.now, nil
+	}
+
+	dates := make([]time.Time, len(call.Args))
+	for i, arg := range call.Args {
+		value, err := e.Eval(arg, row)
+		if err != nil || value == nil {
+			return nil, err
+		}
+		date, ok := e.parseDate(formatValue(value))
+		if !ok {
+			return nil, nil
+		}
+		dates[i] = date
+	}
+
+	switch call.Name {
+	case "DATE":
+		return dates[0].Format("2006-01-02"), nil
+	case "YEAR":
+		return float64(dates[0].Year()), nil
+	case "MONTH":
+		return float64(dates[0].Month()), nil
+	case "DAY":
+		return float64(dates[0].Day()), nil
+	default:
+		// Whole calendar days from the second date to the first
+		end := dates[0].Truncate(24 * time.Hour)
+		start := dates[1].Truncate(24 * time.Hour)
+		return float64(end.Sub(start) / (24 * time.Hour)), nil
+	}
+}
@@ -2,6 +2,8 @@ package operations
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-gota/gota/dataframe"
@@ -14,6 +16,11 @@ func (ops *CSVOperations) Delete(whereCond string) error {
 		return fmt.Errorf("DELETE requires WHERE condition to prevent accidental mass deletion")
 	}
 
+	if ops.DataFrame.Nrow() == 0 {
+		ops.reportMutation("DELETE", 0, 0, "No rows match the WHERE condition. No deletions performed.")
+		return nil
+	}
+
 	// Apply WHERE condition to find rows to delete
 	df := ops.DataFrame
 	rowsToDelete, err := ops.ApplyWhereCondition(df, whereCond)
@@ -22,7 +29,7 @@ func (ops *CSVOperations) Delete(whereCond string) error {
 	}
 
 	if rowsToDelete.Nrow() == 0 {
-		fmt.Println("No rows match the WHERE condition. No deletions performed.")
+		ops.reportMutation("DELETE", 0, 0, "No rows match the WHERE condition. No deletions performed.")
 		return nil
 	}
 
@@ -37,7 +44,10 @@ func (ops *CSVOperations) Delete(whereCond string) error {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully deleted %d rows from %s\n", rowsDeleted, ops.FilePath)
+	if err := ops.returnRows(rowsToDelete); err != nil {
+		return err
+	}
+	ops.reportMutation("DELETE", rowsDeleted, rowsDeleted, fmt.Sprintf("Successfully deleted %d rows from %s", rowsDeleted, ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
@@ -65,7 +75,7 @@ func (ops *CSVOperations) PerformDelete(originalDF, rowsToDelete dataframe.DataF
 // GetIndicesToKeep returns indices of rows that should be kept (not deleted)
 func (ops *CSVOperations) GetIndicesToKeep(df dataframe.DataFrame, whereCond string) []int {
 	// Get rows that match the WHERE condition (to be deleted)
-	rowsToDelete, err := ops.ApplyWhereCondition(df, whereCond)
+	matching, err := ops.MatchingRows(df, whereCond)
 	if err != nil {
 		// If WHERE condition fails, keep all rows
 		indices := make([]int, df.Nrow())
@@ -75,18 +85,13 @@ func (ops *CSVOperations) GetIndicesToKeep(df dataframe.DataFrame, whereCond str
 		return indices
 	}
 
-	// Create a set of row signatures to delete
-	deleteSignatures := make(map[string]bool)
-	for i := 0; i < rowsToDelete.Nrow(); i++ {
-		signature := ops.CreateRowSignature(rowsToDelete, i)
-		deleteSignatures[signature] = true
+	deleted := make(map[int]bool, len(matching))
+	for _, i := range matching {
+		deleted[i] = true
 	}
-
-	// Find indices of rows to keep
 	var indicesToKeep []int
 	for i := 0; i < df.Nrow(); i++ {
-		signature := ops.CreateRowSignature(df, i)
-		if !deleteSignatures[signature] {
+		if !deleted[i] {
 			indicesToKeep = append(indicesToKeep, i)
 		}
 	}
@@ -94,18 +99,6 @@ func (ops *CSVOperations) GetIndicesToKeep(df dataframe.DataFrame, whereCond str
 	return indicesToKeep
 }
 
-// CreateRowSignature creates a unique signature for a row
-func (ops *CSVOperations) CreateRowSignature(df dataframe.DataFrame, rowIndex int) string {
-	var signature strings.Builder
-	for j := 0; j < df.Ncol(); j++ {
-		if j > 0 {
-			signature.WriteString("|")
-		}
-		signature.WriteString(fmt.Sprintf("%v", df.Elem(rowIndex, j)))
-	}
-	return signature.String()
-}
-
 // SubsetByIndices creates a new dataframe containing only specified row indices
 func (ops *CSVOperations) SubsetByIndices(df dataframe.DataFrame, indices []int) dataframe.DataFrame {
 	if len(indices) == 0 {
@@ -117,7 +110,7 @@ func (ops *CSVOperations) SubsetByIndices(df dataframe.DataFrame, indices []int)
 	for j := 0; j < df.Ncol(); j++ {
 		columnData := make([]string, len(indices))
 		for i, rowIndex := range indices {
-			columnData[i] = fmt.Sprintf("%v", df.Elem(rowIndex, j))
+			columnData[i] = CellText(df.Elem(rowIndex, j))
 		}
 		allData[j] = columnData
 	}
@@ -140,8 +133,10 @@ func (ops *CSVOperations) CreateEmptyDataFrame() dataframe.DataFrame {
 	return dataframe.New(seriesList...)
 }
 
-// DeleteAll removes all rows (truncate table equivalent)
+// DeleteAll removes all rows (truncate table equivalent), keeping the header
 func (ops *CSVOperations) DeleteAll() error {
+	deleted := ops.DataFrame.Nrow()
+
 	// Create empty dataframe with same structure
 	emptyDF := ops.CreateEmptyDataFrame()
 	
@@ -150,43 +145,57 @@ func (ops *CSVOperations) DeleteAll() error {
 		return fmt.Errorf("failed to save truncated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully deleted all rows from %s\n", ops.FilePath)
+	if deleted > 0 {
+		if err := ops.returnRows(ops.DataFrame); err != nil {
+			return err
+		}
+	}
+
+	ops.reportMutation("TRUNCATE", deleted, deleted, fmt.Sprintf("Successfully deleted all %d rows from %s", deleted, ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
-// DeleteByRowNumbers deletes rows by their row numbers (future enhancement)
+// ParseRowNumbers parses a -delete-rows list such as "5,10,100-200" into sorted, distinct
+// 1-based row numbers. Row 1 is the first row after the header.
+func ParseRowNumbers(spec string) ([]int, error) {
+	seen := make(map[int]bool)
+	var rows []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		fromText, toText, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(fromText))
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(strings.TrimSpace(toText))
+		}
+		if err != nil || from < 1 || to < from {
+			return nil, fmt.Errorf("invalid row number or range: %s (expected e.g. 5,10,100-200)", part)
+		}
+		for row := from; row <= to; row++ {
+			if !seen[row] {
+				seen[row] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+	sort.Ints(rows)
+	return rows, nil
+}
+
+// DeleteByRowNumbers deletes rows by their 1-based position among the data rows. Every
+// number is checked before anything is deleted.
 func (ops *CSVOperations) DeleteByRowNumbers(rowNumbers []int) error {
 	df := ops.DataFrame
-	
-	// Validate row numbers
+
+	deleteSet := make(map[int]bool, len(rowNumbers))
 	for _, rowNum := range rowNumbers {
 		if rowNum < 1 || rowNum > df.Nrow() {
 			return fmt.Errorf("invalid row number: %d (valid range: 1-%d)", rowNum, df.Nrow())
 		}
+		deleteSet[rowNum-1] = true
 	}
 
-	// Convert to 0-based indices and sort in descending order to avoid index shifting
-	indices := make([]int, len(rowNumbers))
-	for i, rowNum := range rowNumbers {
-		indices[i] = rowNum - 1 // Convert to 0-based
-	}
-
-	// Sort indices in descending order
-	for i := 0; i < len(indices)-1; i++ {
-		for j := i + 1; j < len(indices); j++ {
-			if indices[i] < indices[j] {
-				indices[i], indices[j] = indices[j], indices[i]
-			}
-		}
-	}
-
-	// Create list of indices to keep
-	keepIndices := make([]int, 0, df.Nrow()-len(indices))
-	deleteSet := make(map[int]bool)
-	for _, idx := range indices {
-		deleteSet[idx] = true
-	}
-
+	keepIndices := make([]int, 0, df.Nrow()-len(deleteSet))
 	for i := 0; i < df.Nrow(); i++ {
 		if !deleteSet[i] {
 			keepIndices = append(keepIndices, i)
@@ -201,7 +210,19 @@ func (ops *CSVOperations) DeleteByRowNumbers(rowNumbers []int) error {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully deleted %d rows from %s\n", len(rowNumbers), ops.FilePath)
+	deleted := len(deleteSet)
+	if deleted > 0 {
+		removed := make([]int, 0, deleted)
+		for i := 0; i < df.Nrow(); i++ {
+			if deleteSet[i] {
+				removed = append(removed, i)
+			}
+		}
+		if err := ops.returnRows(df.Subset(removed)); err != nil {
+			return err
+		}
+	}
+	ops.reportMutation("DELETE", deleted, deleted, fmt.Sprintf("Successfully deleted %d rows from %s", deleted, ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
@@ -220,7 +241,9 @@ func (ops *CSVOperations) SafeDelete(whereCond string, requireConfirmation bool)
 	for i := 0; i < previewRows; i++ {
 		indices[i] = i
 	}
-	ops.PrintDataFrame(rowsToDelete.Subset(indices))
+	if err := ops.PrintDataFrame(rowsToDelete.Subset(indices)); err != nil {
+		return err
+	}
 
 	if rowsToDelete.Nrow() > 10 {
 		fmt.Printf("... and %d more rows\n", rowsToDelete.Nrow()-10)
@@ -0,0 +1,141 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// DiffColumns summarizes per column what changed between the loaded file and a newer version
+// at path: how many cells differ in matching rows and, for numeric columns, how the mean and
+// range moved. Rows are matched on the key column when onKey is set, otherwise by position.
+func (ops *CSVOperations) DiffColumns(path, onKey string) error {
+	oldDF := ops.DataFrame
+	newDF, err := ops.LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %v", path, err)
+	}
+
+	pairs, added, removed, err := matchRows(oldDF, newDF, onKey, ops.FilePath, path)
+	if err != nil {
+		return err
+	}
+
+	// Columns of the old file first, then any the new file added
+	columns := append([]string{}, oldDF.Names()...)
+	for _, name := range newDF.Names() {
+		if indexOf(columns, name) < 0 {
+			columns = append(columns, name)
+		}
+	}
+
+	records := [][]string{{"column", "status", "changed", "changed_pct", "mean_old", "mean_new", "range_old", "range_new"}}
+	for _, name := range columns {
+		oldIdx, newIdx := indexOf(oldDF.Names(), name), indexOf(newDF.Names(), name)
+		row := []string{name, "", "", "", "", "", "", ""}
+		switch {
+		case oldIdx < 0:
+			row[1] = "added"
+		case newIdx < 0:
+			row[1] = "removed"
+		default:
+			changed := 0
+			for _, pair := range pairs {
+				if CellText(oldDF.Elem(pair[0], oldIdx)) != CellText(newDF.Elem(pair[1], newIdx)) {
+					changed++
+				}
+			}
+			row[1] = "unchanged"
+			if changed > 0 {
+				row[1] = "changed"
+			}
+			row[2] = fmt.Sprintf("%d", changed)
+			if len(pairs) > 0 {
+				row[3] = fmt.Sprintf("%.1f", 100*float64(changed)/float64(len(pairs)))
+			}
+		}
+		if oldIdx >= 0 {
+			row[4], row[6] = numericSummary(oldDF.Col(name))
+		}
+		if newIdx >= 0 {
+			row[5], row[7] = numericSummary(newDF.Col(name))
+		}
+		records = append(records, row)
+	}
+
+	if !ops.RawOutput && ops.OutputFile == "" {
+		matchedBy := "position"
+		if onKey != "" {
+			matchedBy = onKey
+		}
+		fmt.Printf("Rows: %d -> %d (%d matched by %s, %d added, %d removed)\n\n", oldDF.Nrow(), newDF.Nrow(), len(pairs), matchedBy, added, removed)
+	}
+	return ops.PrintDataFrame(NewStringDataFrame(records[0], records[1:]))
+}
+
+// matchRows pairs row indices of oldDF and newDF, by key column or by position, and counts
+// the rows only present in the new (added) or old (removed) frame
+func matchRows(oldDF, newDF dataframe.DataFrame, onKey, oldPath, newPath string) ([][2]int, int, int, error) {
+	var pairs [][2]int
+	if onKey == "" {
+		for i := 0; i < min(oldDF.Nrow(), newDF.Nrow()); i++ {
+			pairs = append(pairs, [2]int{i, i})
+		}
+		return pairs, max(newDF.Nrow()-oldDF.Nrow(), 0), max(oldDF.Nrow()-newDF.Nrow(), 0), nil
+	}
+
+	oldRows, err := keyIndex(oldDF, onKey, oldPath)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if _, err := keyIndex(newDF, onKey, newPath); err != nil {
+		return nil, 0, 0, err
+	}
+
+	added := 0
+	keyCol := newDF.Col(onKey)
+	for j := 0; j < newDF.Nrow(); j++ {
+		if i, ok := oldRows[CellText(keyCol.Elem(j))]; ok {
+			pairs = append(pairs, [2]int{i, j})
+		} else {
+			added++
+		}
+	}
+	return pairs, added, len(oldRows) - len(pairs), nil
+}
+
+// keyIndex maps each value of the key column to its row, rejecting ambiguous keys
+func keyIndex(df dataframe.DataFrame, key, path string) (map[string]int, error) {
+	if indexOf(df.Names(), key) < 0 {
+		return nil, fmt.Errorf("key column '%s' does not exist in %s", key, path)
+	}
+	rows := make(map[string]int)
+	col := df.Col(key)
+	for i := 0; i < df.Nrow(); i++ {
+		value := CellText(col.Elem(i))
+		if _, exists := rows[value]; exists {
+			return nil, fmt.Errorf("duplicate key '%s' in %s", value, path)
+		}
+		rows[value] = i
+	}
+	return rows, nil
+}
+
+// numericSummary returns the mean and "min..max" of a numeric column, or blanks for other columns
+func numericSummary(col series.Series) (string, string) {
+	if col.Type() != series.Int && col.Type() != series.Float {
+		return "", ""
+	}
+	values := numericValues(col)
+	if len(values) == 0 {
+		return "", ""
+	}
+	low, high, sum := values[0], values[0], 0.0
+	for _, v := range values {
+		low, high, sum = math.Min(low, v), math.Max(high, v), sum+v
+	}
+	mean := sum / float64(len(values))
+	return FormatAggregateValue(mean), FormatAggregateValue(low) + ".." + FormatAggregateValue(high)
+}
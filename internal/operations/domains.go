@@ -0,0 +1,162 @@
+package operations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseAllowedValues parses an -allowed list such as "severity:low|medium|high,status:open|closed"
+// into the allowed values by column. A column with no values, as in "severity:", has no domain.
+func ParseAllowedValues(spec string) (map[string][]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	domains := make(map[string][]string)
+	for _, part := range strings.Split(spec, ",") {
+		column, list, found := strings.Cut(part, ":")
+		column = strings.TrimSpace(column)
+		if !found || column == "" {
+			return nil, fmt.Errorf("invalid -allowed entry: %s (expected column:value1|value2|...)", strings.TrimSpace(part))
+		}
+		if _, dup := domains[column]; dup {
+			return nil, fmt.Errorf("-allowed lists column '%s' twice", column)
+		}
+		values := []string{}
+		for _, value := range strings.Split(list, "|") {
+			if value = strings.TrimSpace(value); value != "" && indexOf(values, value) < 0 {
+				values = append(values, value)
+			}
+		}
+		domains[column] = values
+	}
+	return domains, nil
+}
+
+// loadColumnRules reads the rules on written values: the types declared by create and the
+// allowed values annotated in the sidecar, overridden by -allowed. The sidecar is read once.
+func (ops *CSVOperations) loadColumnRules() error {
+	if ops.domains != nil {
+		return nil
+	}
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+	domains := make(map[string][]string)
+	declared := make(map[string]string)
+	for _, column := range ops.Headers {
+		entry, ok := meta.Columns[ops.originalHeader(column)]
+		if !ok {
+			continue
+		}
+		if len(entry.Allowed) > 0 {
+			domains[column] = entry.Allowed
+		}
+		if entry.Type != "" {
+			declared[column] = entry.Type
+		}
+	}
+	for column, values := range ops.Allowed {
+		if indexOf(ops.Headers, column) < 0 {
+			return fmt.Errorf("-allowed column '%s' does not exist in CSV", column)
+		}
+		if len(values) == 0 {
+			delete(domains, column)
+		} else {
+			domains[column] = values
+		}
+	}
+	ops.domains, ops.declared = domains, declared
+	return nil
+}
+
+// checkAllowed rejects a value written to a column that does not fit the column's declared
+// type or is not one of its allowed values, suggesting the nearest allowed one. Empty values
+// are always accepted.
+func (ops *CSVOperations) checkAllowed(column, value string) error {
+	if err := ops.loadColumnRules(); err != nil || value == "" {
+		return err
+	}
+	if colType, ok := ops.declared[column]; ok && !ops.fitsType(value, colType) {
+		return fmt.Errorf("value '%s' is not a valid %s for column '%s'", value, colType, column)
+	}
+	allowed, ok := ops.domains[column]
+	if !ok || indexOf(allowed, value) >= 0 {
+		return nil
+	}
+	if nearest := nearestValue(value, allowed); nearest != "" {
+		return fmt.Errorf("value '%s' is not allowed in column '%s'; did you mean '%s'? (allowed: %s)", value, column, nearest, strings.Join(allowed, ", "))
+	}
+	return fmt.Errorf("value '%s' is not allowed in column '%s' (allowed: %s)", value, column, strings.Join(allowed, ", "))
+}
+
+// fitsType reports whether value can be read as a declared type
+func (ops *CSVOperations) fitsType(value, colType string) bool {
+	var err error
+	switch colType {
+	case "int":
+		_, err = strconv.Atoi(value)
+	case "float":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "date":
+		_, ok := ops.evaluator(ops.DataFrame).parseDate(value)
+		return ok
+	}
+	return err == nil
+}
+
+// checkAllowedValues applies checkAllowed to every value of a row, in column order
+func (ops *CSVOperations) checkAllowedValues(values map[string]string) error {
+	for _, column := range ops.Headers {
+		if value, ok := values[column]; ok {
+			if err := ops.checkAllowed(column, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nearestValue returns the allowed value closest to value: one differing only in case, or
+// else the one with the fewest edits if that is at most a third of its length
+func nearestValue(value string, allowed []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, value) {
+			return candidate
+		}
+		distance := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if bestDistance > max(1, len([]rune(best))/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b, counted in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,151 @@
+package operations
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Scan sizes above which a full read of the input is worth a second thought: a warning on
+// stderr first, then a refusal unless -force is given
+const (
+	ScanWarnBytes  = 512 << 20
+	ScanForceBytes = 4 << 30
+)
+
+// estimateSampleSize is how much of a file without stats is read to estimate its row count
+const estimateSampleSize = 1 << 20
+
+// FileStats records the size of a file when it was last analyzed, so later reads can be
+// estimated without scanning it
+type FileStats struct {
+	Rows     int       `json:"rows"`
+	Bytes    int64     `json:"bytes"`
+	Modified time.Time `json:"modified"`
+}
+
+// ScanEstimate is the expected cost of reading the whole input file
+type ScanEstimate struct {
+	Bytes      int64
+	Rows       int
+	OutputRows int    // OutputRows is an upper bound: filters are not estimated
+	Source     string // Source says where Rows comes from: stats, scaled stats or a sample
+}
+
+// Analyze counts the rows of the file and records them in the sidecar with the file's size,
+// for the cost estimates made before queries
+func (ops *CSVOperations) Analyze() error {
+	info, err := os.Stat(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	rows, err := ops.CountRows()
+	if err != nil {
+		return err
+	}
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+	meta.Stats = &FileStats{Rows: rows, Bytes: info.Size(), Modified: info.ModTime().UTC()}
+	if err := SaveMetadata(ops.FilePath, meta); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d rows (%s) of %s in %s\n", rows, HumanizeValue(strconv.FormatInt(info.Size(), 10), HumanizeBytes), ops.FilePath, SidecarPath(ops.FilePath))
+	return nil
+}
+
+// EstimateScan estimates the rows and bytes a full read of the file scans, and the rows a
+// query returns at most (limit caps them when non-zero). The row count comes from the
+// sidecar stats when they describe the file, scaled by size when it has changed since, and
+// otherwise from the line length of the first megabyte.
+func (ops *CSVOperations) EstimateScan(limit int) (ScanEstimate, error) {
+	info, err := os.Stat(ops.FilePath)
+	if err != nil {
+		return ScanEstimate{}, fmt.Errorf("failed to open file: %v", err)
+	}
+	estimate := ScanEstimate{Bytes: info.Size()}
+
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return estimate, err
+	}
+	switch stats := meta.Stats; {
+	case stats != nil && stats.Bytes == info.Size() && stats.Modified.Equal(info.ModTime().UTC()):
+		estimate.Rows, estimate.Source = stats.Rows, "sidecar stats"
+	case stats != nil && stats.Bytes > 0:
+		estimate.Rows = int(float64(stats.Rows) * float64(info.Size()) / float64(stats.Bytes))
+		estimate.Source = "sidecar stats, scaled to the current size"
+	default:
+		if estimate.Rows, err = sampleRows(ops.FilePath, info.Size()); err != nil {
+			return estimate, err
+		}
+		estimate.Source = "sample of the first lines"
+	}
+
+	estimate.OutputRows = estimate.Rows
+	if limit > 0 && limit < estimate.OutputRows {
+		estimate.OutputRows = limit
+	}
+	return estimate, nil
+}
+
+// sampleRows extrapolates the data rows of a file from the lines in its first megabyte
+func sampleRows(path string, size int64) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	sample := make([]byte, estimateSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, fmt.Errorf("failed to read file: %v", err)
+	}
+	sample = sample[:n]
+	lines := bytes.Count(sample, []byte("\n"))
+	if n > 0 && sample[n-1] != '\n' && int64(n) == size {
+		lines++
+	}
+	if int64(n) < size && lines > 0 {
+		lines = int(float64(lines) * float64(size) / float64(n))
+	}
+	// The first line is the header
+	return max(lines-1, 0), nil
+}
+
+// CheckScanCost warns on stderr before a read of more than ScanWarnBytes, and refuses one of
+// more than ScanForceBytes unless force is set. A file that does not exist yet, as with INSERT
+// and -header, costs nothing to read.
+func (ops *CSVOperations) CheckScanCost(force bool) error {
+	if _, err := os.Stat(ops.FilePath); os.IsNotExist(err) {
+		return nil
+	}
+	estimate, err := ops.EstimateScan(0)
+	if err != nil || estimate.Bytes <= ScanWarnBytes {
+		return err
+	}
+	size := HumanizeValue(strconv.FormatInt(estimate.Bytes, 10), HumanizeBytes)
+	if estimate.Bytes > ScanForceBytes && !force {
+		return fmt.Errorf("%s is %s (about %d rows); reading all of it may take very long, so re-run with -force, or read part of it with -head, -tail or -lines", ops.FilePath, size, estimate.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: reading all of %s, %s (about %d rows)\n", ops.FilePath, size, estimate.Rows)
+	return nil
+}
+
+// PrintScanEstimate prints the estimated cost of the query without running it
+func (ops *CSVOperations) PrintScanEstimate(limit int) error {
+	estimate, err := ops.EstimateScan(limit)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("file:        %s\n", ops.FilePath)
+	fmt.Printf("bytes:       %d (%s)\n", estimate.Bytes, HumanizeValue(strconv.FormatInt(estimate.Bytes, 10), HumanizeBytes))
+	fmt.Printf("rows:        about %d (from %s)\n", estimate.Rows, estimate.Source)
+	fmt.Printf("output rows: at most %d\n", estimate.OutputRows)
+	return nil
+}
@@ -0,0 +1,140 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// exampleCategoryLimit is the most distinct values a column may have to be used as a category
+const exampleCategoryLimit = 20
+
+// Examples prints ready-to-run invocations built from the file's columns: a filter on a
+// categorical column, the top rows by a numeric column and counts per category
+func (ops *CSVOperations) Examples() error {
+	if ops.IsEmpty() {
+		return fmt.Errorf("%s is empty: there are no columns to build examples from", ops.FilePath)
+	}
+
+	category, value := ops.exampleCategory()
+	numeric := ops.exampleNumeric()
+	file := shellQuote(ops.FilePath)
+
+	var examples [][2]string
+	add := func(title, command string) {
+		examples = append(examples, [2]string{title, command})
+	}
+	add("Columns and their types", fmt.Sprintf("seesv -file %s -describe", file))
+	add("First rows", fmt.Sprintf("seesv -file %s -head 10", file))
+	if category != "" {
+		add(fmt.Sprintf("Rows where %s is %s", category, value),
+			fmt.Sprintf("seesv -file %s -where \"%s = %s\"", file, category, sqlString(value)))
+		add(fmt.Sprintf("Rows per %s, most common first", category),
+			fmt.Sprintf("seesv -file %s -select \"COUNT(*)\" -group %s -order \"COUNT(*) desc\"", file, category))
+	}
+	if numeric != "" {
+		add(fmt.Sprintf("Top 10 rows by %s", numeric),
+			fmt.Sprintf("seesv -file %s -order \"%s desc\" -limit 10", file, numeric))
+	}
+	if category != "" && numeric != "" {
+		add(fmt.Sprintf("%s statistics per %s", numeric, category),
+			fmt.Sprintf("seesv -file %s -select \"COUNT(*), AVG(%s), MAX(%s)\" -group %s", file, numeric, numeric, category))
+		add(fmt.Sprintf("Top 3 rows by %s in each %s", numeric, category),
+			fmt.Sprintf("seesv -file %s -top-per-group 3 -group %s -order \"%s desc\"", file, category, numeric))
+	}
+
+	// FROM finds the file by name when run from its directory
+	if table := strings.TrimSuffix(filepath.Base(ops.FilePath), ".csv"); category != "" && workspaceTableName.MatchString(table) {
+		add("The same counts in SQL, from the file's directory",
+			fmt.Sprintf("seesv -query \"SELECT %s, COUNT(*) AS n FROM %s GROUP BY %s ORDER BY n DESC LIMIT 5\"", category, table, category))
+	}
+
+	for i, example := range examples {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s\n%s\n", example[0], example[1])
+	}
+	return nil
+}
+
+// exampleColumn reports whether name can be written unquoted in the example commands
+func exampleColumn(name string) bool {
+	expr, err := sqlparser.ParseExpr(name)
+	if err != nil {
+		return false
+	}
+	col, ok := expr.(*sqlparser.ColumnRef)
+	return ok && !col.Quoted && col.Table == "" && col.Name == name
+}
+
+// exampleCategory picks the first text column with a handful of repeated values, returning it
+// with its most common value
+func (ops *CSVOperations) exampleCategory() (string, string) {
+	for _, name := range ops.Headers {
+		col := ops.DataFrame.Col(name)
+		if col.Type() != series.String || !exampleColumn(name) {
+			continue
+		}
+		counts := make(map[string]int)
+		var order []string
+		for i := 0; i < col.Len(); i++ {
+			// Values the shell would expand inside the double-quoted -where are left out
+			value := col.Elem(i).String()
+			if col.Elem(i).IsNA() || strings.TrimSpace(value) == "" || strings.ContainsAny(value, "\"$`\\\n") {
+				continue
+			}
+			if counts[value] == 0 {
+				order = append(order, value)
+			}
+			counts[value]++
+		}
+		if len(order) < 2 || len(order) > exampleCategoryLimit || len(order)*2 > col.Len() {
+			continue
+		}
+		best := order[0]
+		for _, value := range order {
+			if counts[value] > counts[best] {
+				best = value
+			}
+		}
+		return name, best
+	}
+	return "", ""
+}
+
+// exampleNumeric picks a numeric column to rank by, preferring one that is not an identifier
+func (ops *CSVOperations) exampleNumeric() string {
+	fallback := ""
+	for _, name := range ops.Headers {
+		colType := ops.DataFrame.Col(name).Type()
+		if (colType != series.Int && colType != series.Float) || !exampleColumn(name) {
+			continue
+		}
+		lower := strings.ToLower(name)
+		if lower == "id" || strings.HasSuffix(lower, "_id") {
+			if fallback == "" {
+				fallback = name
+			}
+			continue
+		}
+		return name
+	}
+	return fallback
+}
+
+// sqlString writes value as a SQL string literal
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// shellQuote quotes a path for a POSIX shell when it contains anything but plain characters
+func shellQuote(path string) string {
+	if path != "" && strings.Trim(path, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-/+:@") == "" {
+		return path
+	}
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
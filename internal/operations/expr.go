@@ -0,0 +1,509 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// Evaluator evaluates SQL expressions row by row against a dataframe.
+// Values are nil (NULL), float64, string or bool.
+type Evaluator struct {
+	df         dataframe.DataFrame
+	columns    map[string]int
+	types      []series.Type
+	patterns   map[string]*likePattern // patterns caches compiled LIKE patterns
+	random     *rand.Rand              // random drives RANDOM() and RANDOM_PICK()
+	layouts    []string                // layouts are the accepted date formats, tried in order
+	dates      map[string]time.Time    // dates caches parsed dates; the zero time marks text that is not a date
+	now        string
+	subqueries map[*sqlparser.InExpr]*memberSet      // subqueries holds the materialized IN (SELECT ...) results
+	windows    map[*sqlparser.FuncCall][]interface{} // windows holds the window function values by row
+	aliases    map[string]sqlparser.Expr             // aliases maps select list aliases to their expressions for QUALIFY
+}
+
+// NewEvaluator prepares an evaluator for the given dataframe; random is the source for
+// RANDOM() and RANDOM_PICK()
+func NewEvaluator(df dataframe.DataFrame, random *rand.Rand) *Evaluator {
+	columns := make(map[string]int)
+	for i, name := range df.Names() {
+		columns[name] = i
+	}
+	return &Evaluator{
+		df:         df,
+		columns:    columns,
+		types:      df.Types(),
+		patterns:   make(map[string]*likePattern),
+		random:     random,
+		layouts:    DefaultDateFormats,
+		dates:      make(map[string]time.Time),
+		subqueries: make(map[*sqlparser.InExpr]*memberSet),
+		windows:    make(map[*sqlparser.FuncCall][]interface{}),
+		aliases:    make(map[string]sqlparser.Expr),
+	}
+}
+
+// Eval computes the value of expr for the given row
+func (e *Evaluator) Eval(expr sqlparser.Expr, row int) (interface{}, error) {
+	switch ex := expr.(type) {
+	case *sqlparser.Literal:
+		return literalValue(ex), nil
+
+	case *sqlparser.ColumnRef:
+		if idx, ok := e.columns[ex.Name]; ok {
+			return e.cellValue(row, idx), nil
+		}
+		if alias, ok := e.aliases[ex.Name]; ok && ex.Table == "" {
+			return e.Eval(alias, row)
+		}
+		if ex.Table == "" {
+			// Words that do not name a column are text, as in -where "status = active"
+			return ex.Name, nil
+		}
+		return nil, fmt.Errorf("column '%s' does not exist in CSV", ex.String())
+
+	case *sqlparser.FuncCall:
+		// Grouped results expose aggregates as columns named after the call, e.g. COUNT(*)
+		if idx, ok := e.columns[ex.String()]; ok {
+			return e.cellValue(row, idx), nil
+		}
+		if ex.Over != nil {
+			values, ok := e.windows[ex]
+			if !ok {
+				return nil, fmt.Errorf("window functions such as %s are only supported in the select list", ex.Name)
+			}
+			return values[row], nil
+		}
+		return e.evalFunc(ex, row)
+
+	case *sqlparser.UnaryExpr:
+		value, err := e.Eval(ex.Expr, row)
+		if err != nil {
+			return nil, err
+		}
+		switch ex.Op {
+		case "NOT":
+			if value == nil {
+				return nil, nil
+			}
+			return !truthy(value), nil
+		case "-":
+			f, ok := toNumber(value)
+			if !ok {
+				return nil, nil
+			}
+			return -f, nil
+		}
+		return nil, fmt.Errorf("unsupported operator: %s", ex.Op)
+
+	case *sqlparser.BinaryExpr:
+		return e.evalBinary(ex, row)
+
+	case *sqlparser.InExpr:
+		return e.evalIn(ex, row)
+
+	case *sqlparser.BetweenExpr:
+		return e.evalBetween(ex, row)
+
+	case *sqlparser.LikeExpr:
+		return e.evalLike(ex, row)
+
+	case *sqlparser.CaseExpr:
+		return e.evalCase(ex, row)
+
+	case *sqlparser.CastExpr:
+		return e.evalCast(ex, row)
+
+	case *sqlparser.StarExpr:
+		return nil, fmt.Errorf("* is only allowed in the select list and COUNT(*)")
+	}
+	return nil, fmt.Errorf("unsupported expression: %s", expr.String())
+}
+
+// evalBinary evaluates logical, comparison and arithmetic operators
+func (e *Evaluator) evalBinary(ex *sqlparser.BinaryExpr, row int) (interface{}, error) {
+	left, err := e.Eval(ex.Left, row)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit logical operators
+	switch ex.Op {
+	case "AND":
+		if left != nil && !truthy(left) {
+			return false, nil
+		}
+	case "OR":
+		if left != nil && truthy(left) {
+			return true, nil
+		}
+	}
+
+	right, err := e.Eval(ex.Right, row)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ex.Op {
+	case "AND", "OR":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		if ex.Op == "AND" {
+			return truthy(left) && truthy(right), nil
+		}
+		return truthy(left) || truthy(right), nil
+
+	case "=", "!=", "<", "<=", ">", ">=":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		cmp := e.compare(left, right)
+		switch ex.Op {
+		case "=":
+			return cmp == 0, nil
+		case "!=":
+			return cmp != 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+
+	case "||":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return formatValue(left) + formatValue(right), nil
+
+	case "+", "-", "*", "/", "%":
+		a, okA := toNumber(left)
+		b, okB := toNumber(right)
+		if !okA || !okB {
+			return nil, nil
+		}
+		switch ex.Op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/":
+			if b == 0 {
+				return nil, nil
+			}
+			return a / b, nil
+		default:
+			if b == 0 {
+				return nil, nil
+			}
+			return math.Mod(a, b), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported operator: %s", ex.Op)
+}
+
+// evalIn evaluates expr [NOT] IN (list) with SQL NULL semantics:
+// no match against a list containing NULL yields NULL
+func (e *Evaluator) evalIn(ex *sqlparser.InExpr, row int) (interface{}, error) {
+	value, err := e.Eval(ex.Expr, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	if ex.Subquery != nil {
+		set, ok := e.subqueries[ex]
+		if !ok {
+			return nil, fmt.Errorf("IN (SELECT ...) is only supported in WHERE conditions")
+		}
+		if set.values[membershipKey(value)] {
+			return !ex.Not, nil
+		}
+		if set.hasNull {
+			return nil, nil
+		}
+		return ex.Not, nil
+	}
+
+	sawNull := false
+	for _, item := range ex.List {
+		candidate, err := e.Eval(item, row)
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil {
+			sawNull = true
+			continue
+		}
+		if e.compare(value, candidate) == 0 {
+			return !ex.Not, nil
+		}
+	}
+	if sawNull {
+		return nil, nil
+	}
+	return ex.Not, nil
+}
+
+// evalBetween evaluates expr [NOT] BETWEEN low AND high as an inclusive range
+func (e *Evaluator) evalBetween(ex *sqlparser.BetweenExpr, row int) (interface{}, error) {
+	values := make([]interface{}, 3)
+	for i, operand := range []sqlparser.Expr{ex.Expr, ex.Low, ex.High} {
+		value, err := e.Eval(operand, row)
+		if err != nil || value == nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	inRange := e.compare(values[0], values[1]) >= 0 && e.compare(values[0], values[2]) <= 0
+	return inRange != ex.Not, nil
+}
+
+// evalLike evaluates expr [NOT] LIKE pattern
+func (e *Evaluator) evalLike(ex *sqlparser.LikeExpr, row int) (interface{}, error) {
+	value, err := e.Eval(ex.Expr, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	pattern, err := e.Eval(ex.Pattern, row)
+	if err != nil || pattern == nil {
+		return nil, err
+	}
+
+	text := formatValue(pattern)
+	compiled, ok := e.patterns[text]
+	if !ok {
+		compiled = compileLike(text)
+		e.patterns[text] = compiled
+	}
+	return compiled.Match(formatValue(value)) != ex.Not, nil
+}
+
+// evalCase returns the result of the first matching WHEN branch, the ELSE result,
+// or NULL when nothing matches
+func (e *Evaluator) evalCase(ex *sqlparser.CaseExpr, row int) (interface{}, error) {
+	var operand interface{}
+	if ex.Operand != nil {
+		value, err := e.Eval(ex.Operand, row)
+		if err != nil {
+			return nil, err
+		}
+		operand = value
+	}
+
+	for _, when := range ex.Whens {
+		cond, err := e.Eval(when.Cond, row)
+		if err != nil {
+			return nil, err
+		}
+		matched := cond != nil && truthy(cond)
+		if ex.Operand != nil {
+			matched = operand != nil && cond != nil && e.compare(operand, cond) == 0
+		}
+		if matched {
+			return e.Eval(when.Result, row)
+		}
+	}
+	if ex.Else != nil {
+		return e.Eval(ex.Else, row)
+	}
+	return nil, nil
+}
+
+// cellValue converts a dataframe cell to an evaluator value
+func (e *Evaluator) cellValue(row, col int) interface{} {
+	elem := e.df.Elem(row, col)
+	if elem.IsNA() {
+		return nil
+	}
+	switch e.types[col] {
+	case series.Int, series.Float:
+		return elem.Float()
+	case series.Bool:
+		b, err := elem.Bool()
+		if err != nil {
+			return nil
+		}
+		return b
+	default:
+		return elem.String()
+	}
+}
+
+// EvalColumn evaluates expr for every row of the evaluator's dataframe and returns the results
+// as a column. The column is numeric or boolean when every non-NULL value is, and text otherwise.
+func EvalColumn(evaluator *Evaluator, expr sqlparser.Expr, name string) (series.Series, error) {
+	values := make([]string, evaluator.df.Nrow())
+	colType := series.Type("")
+	fractional := false
+	for i := range values {
+		value, err := evaluator.Eval(expr, i)
+		if err != nil {
+			return series.Series{}, err
+		}
+		if value == nil {
+			// gota marks missing values with "NaN"; CellText prints them as empty cells
+			values[i] = "NaN"
+			continue
+		}
+		values[i] = formatValue(value)
+
+		valueType := series.String
+		switch v := value.(type) {
+		case float64:
+			valueType = series.Float
+			fractional = fractional || v != math.Trunc(v) || math.Abs(v) >= 1e15
+		case bool:
+			valueType = series.Bool
+		}
+		if colType == "" {
+			colType = valueType
+		} else if colType != valueType {
+			colType = series.String
+		}
+	}
+	if colType == "" {
+		colType = series.String
+	}
+	// Whole numbers, such as ROW_NUMBER() or LENGTH(name), make an integer column
+	if colType == series.Float && !fractional {
+		colType = series.Int
+	}
+	return series.New(values, colType, name), nil
+}
+
+// FilterExpr keeps the rows of the evaluator's dataframe for which expr evaluates to true
+func FilterExpr(evaluator *Evaluator, expr sqlparser.Expr) (dataframe.DataFrame, error) {
+	df := evaluator.df
+	indices := []int{}
+	for i := 0; i < df.Nrow(); i++ {
+		value, err := evaluator.Eval(expr, i)
+		if err != nil {
+			return df, err
+		}
+		if value != nil && truthy(value) {
+			indices = append(indices, i)
+		}
+	}
+
+	if len(indices) == 0 {
+		return NewStringDataFrame(df.Names(), nil), nil
+	}
+	return df.Subset(indices), nil
+}
+
+// literalValue converts a parsed literal to an evaluator value
+func literalValue(lit *sqlparser.Literal) interface{} {
+	switch lit.Kind {
+	case sqlparser.NumberLiteral:
+		f, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return lit.Value
+		}
+		return f
+	case sqlparser.BoolLiteral:
+		return lit.Value == "true"
+	case sqlparser.NullLiteral:
+		return nil
+	default:
+		return lit.Value
+	}
+}
+
+// toNumber converts a value to float64 when it is numeric or numeric text
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// truthy reports whether a non-NULL value counts as true in a condition
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	return false
+}
+
+// compare orders two non-NULL values like compareValues, except that two texts that both
+// parse as dates are compared chronologically
+func (e *Evaluator) compare(a, b interface{}) int {
+	textA, okA := a.(string)
+	textB, okB := b.(string)
+	if okA && okB {
+		if dateA, ok := e.parseDate(textA); ok {
+			if dateB, ok := e.parseDate(textB); ok {
+				return dateA.Compare(dateB)
+			}
+		}
+	}
+	return compareValues(a, b)
+}
+
+// compareValues orders two non-NULL values, numerically when either side is a number
+// and the other converts to one, otherwise as text
+func compareValues(a, b interface{}) int {
+	_, aIsNumber := a.(float64)
+	_, bIsNumber := b.(float64)
+	if aIsNumber || bIsNumber {
+		fa, okA := toNumber(a)
+		fb, okB := toNumber(b)
+		if okA && okB {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(formatValue(a), formatValue(b))
+}
+
+// formatValue renders an evaluator value as text
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
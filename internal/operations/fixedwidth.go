@@ -0,0 +1,98 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SourceFixedWidth marks input split into columns by -widths rather than by a delimiter
+const SourceFixedWidth = "fixed-width"
+
+// ParseWidths reads a -widths column spec: a comma separated list of widths such as
+// "10,4,20", or the path of a spec file with one column per line, given as "name width" or as
+// a bare width. Blank lines and lines starting with # are skipped. Names are returned when
+// the spec file gives one for every column.
+func ParseWidths(spec string) ([]int, []string, error) {
+	if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+		return parseWidthsFile(spec)
+	}
+
+	var widths []int
+	for _, part := range strings.Split(spec, ",") {
+		width, err := parseWidth(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		widths = append(widths, width)
+	}
+	return widths, nil, nil
+}
+
+func parseWidthsFile(path string) ([]int, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read widths file: %v", err)
+	}
+
+	var widths []int
+	var names []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, nil, fmt.Errorf("%s line %d: expected \"name width\" or a width, got %q", path, i+1, line)
+		}
+		width, err := parseWidth(fields[len(fields)-1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s line %d: %v", path, i+1, err)
+		}
+		widths = append(widths, width)
+		if len(fields) == 2 {
+			names = append(names, fields[0])
+		}
+	}
+	if len(widths) == 0 {
+		return nil, nil, fmt.Errorf("widths file %s lists no columns", path)
+	}
+	if len(names) > 0 && len(names) != len(widths) {
+		return nil, nil, fmt.Errorf("widths file %s names only %d of its %d columns", path, len(names), len(widths))
+	}
+	return widths, names, nil
+}
+
+func parseWidth(text string) (int, error) {
+	width, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || width <= 0 {
+		return 0, fmt.Errorf("invalid column width: %q (expected a positive number of characters)", strings.TrimSpace(text))
+	}
+	return width, nil
+}
+
+// fixedWidthRecords cuts each line of text into columns of the given widths, counted in
+// characters, and trims the padding around each value. Characters beyond the last column are
+// ignored, a short line leaves its last columns empty and blank lines are skipped.
+func fixedWidthRecords(text string, widths []int) [][]string {
+	var records [][]string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		runes := []rune(line)
+		record := make([]string, len(widths))
+		start := 0
+		for j, width := range widths {
+			if start < len(runes) {
+				end := min(start+width, len(runes))
+				record[j] = strings.TrimSpace(string(runes[start:end]))
+			}
+			start += width
+		}
+		records = append(records, record)
+	}
+	return records
+}
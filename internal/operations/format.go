@@ -0,0 +1,89 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FormatFile rewrites the file in canonical form: UTF-8, LF newlines, minimal quoting with the
+// file's own delimiter, trailing spaces trimmed from every cell and, when sortSpec is given,
+// data rows sorted by those columns. With check set the file is left untouched and an error
+// is returned if it is not already canonical.
+func (ops *CSVOperations) FormatFile(sortSpec string, check bool) error {
+	data, err := os.ReadFile(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return err
+	}
+	if err := sourceWriteError(ops.FilePath, dialect); err != nil {
+		return err
+	}
+
+	canonical, err := canonicalCSV(records, dialect, sortSpec)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(data, canonical) {
+		if check {
+			fmt.Printf("%s is canonically formatted\n", ops.FilePath)
+		} else {
+			fmt.Printf("%s already formatted\n", ops.FilePath)
+		}
+		return nil
+	}
+	if check {
+		return fmt.Errorf("%s is not canonically formatted (run: seesv fmt -file %s)", ops.FilePath, ops.FilePath)
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if err := os.WriteFile(ops.FilePath, canonical, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	fmt.Printf("Formatted %s\n", ops.FilePath)
+	return nil
+}
+
+// canonicalCSV renders records in canonical form
+func canonicalCSV(records [][]string, dialect Dialect, sortSpec string) ([]byte, error) {
+	for _, record := range records {
+		for i, cell := range record {
+			record[i] = strings.TrimRight(cell, " \t")
+		}
+	}
+
+	if sortSpec != "" && len(records) > 1 {
+		keys, err := ParseSortKeys(sortSpec)
+		if err != nil {
+			return nil, err
+		}
+		keys, err = resolveSortKeys(records[0], keys)
+		if err != nil {
+			return nil, err
+		}
+		sortRows(records[1:], keys)
+	}
+
+	// Synthetic column names were added on read and are not part of the file
+	if !dialect.HasHeader && len(records) > 0 {
+		records = records[1:]
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if dialect.Delimiter != 0 {
+		writer.Comma = dialect.Delimiter
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,217 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// Random returns the source for RANDOM() and RANDOM_PICK(). It is seeded from Seed when set,
+// so repeated runs over the same input produce the same values, and from the clock otherwise.
+func (ops *CSVOperations) Random() *rand.Rand {
+	if ops.random == nil {
+		seed := ops.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		ops.random = rand.New(rand.NewSource(seed))
+	}
+	return ops.random
+}
+
+// evaluator prepares an expression evaluator for df with the configured seed and date formats
+func (ops *CSVOperations) evaluator(df dataframe.DataFrame) *Evaluator {
+	evaluator := NewEvaluator(df, ops.Random())
+	evaluator.layouts = append(append([]string{}, ops.DateFormats...), DefaultDateFormats...)
+	return evaluator
+}
+
+// evalFunc evaluates scalar function calls
+func (e *Evaluator) evalFunc(call *sqlparser.FuncCall, row int) (interface{}, error) {
+	switch call.Name {
+	case "RANDOM":
+		if len(call.Args) != 0 {
+			return nil, fmt.Errorf("RANDOM() takes no arguments")
+		}
+		return e.random.Float64(), nil
+
+	case "RANDOM_PICK":
+		if len(call.Args) == 0 {
+			return nil, fmt.Errorf("RANDOM_PICK() needs at least one value")
+		}
+		return e.Eval(call.Args[e.random.Intn(len(call.Args))], row)
+
+	case "SHA256":
+		return e.hashRow(call, row)
+
+	case "DATE", "YEAR", "MONTH", "DAY", "DATEDIFF", "NOW":
+		return e.evalDateFunc(call, row)
+
+	case "UPPER", "LOWER", "TRIM", "LTRIM", "RTRIM", "LENGTH", "SUBSTR", "SUBSTRING", "CONCAT":
+		return e.evalStringFunc(call, row)
+	}
+	return nil, fmt.Errorf("unsupported function: %s", call.Name)
+}
+
+// evalStringFunc evaluates the string functions. NULL arguments give NULL, except in CONCAT,
+// which skips them.
+func (e *Evaluator) evalStringFunc(call *sqlparser.FuncCall, row int) (interface{}, error) {
+	args := make([]interface{}, len(call.Args))
+	for i, arg := range call.Args {
+		value, err := e.Eval(arg, row)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	if call.Name == "CONCAT" {
+		var b strings.Builder
+		for _, arg := range args {
+			b.WriteString(formatValue(arg))
+		}
+		return b.String(), nil
+	}
+
+	switch call.Name {
+	case "SUBSTR", "SUBSTRING":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("%s() expects (text, start[, length])", call.Name)
+		}
+	default:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() expects exactly one argument", call.Name)
+		}
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	text := formatValue(args[0])
+	switch call.Name {
+	case "UPPER":
+		return strings.ToUpper(text), nil
+	case "LOWER":
+		return strings.ToLower(text), nil
+	case "TRIM":
+		return strings.TrimSpace(text), nil
+	case "LTRIM":
+		return strings.TrimLeftFunc(text, unicode.IsSpace), nil
+	case "RTRIM":
+		return strings.TrimRightFunc(text, unicode.IsSpace), nil
+	case "LENGTH":
+		return float64(utf8.RuneCountInString(text)), nil
+	default:
+		return substr(text, args[1:])
+	}
+}
+
+// substr returns the characters of text from a 1-based start, optionally limited to a length
+func substr(text string, args []interface{}) (interface{}, error) {
+	runes := []rune(text)
+	start, ok := toNumber(args[0])
+	if !ok {
+		return nil, fmt.Errorf("SUBSTR() start must be a number")
+	}
+	from := max(int(start)-1, 0)
+	to := len(runes)
+	if len(args) == 2 {
+		length, ok := toNumber(args[1])
+		if !ok || length < 0 {
+			return nil, fmt.Errorf("SUBSTR() length must be a non-negative number")
+		}
+		to = min(int(start)-1+int(length), len(runes))
+	}
+	if from >= to {
+		return "", nil
+	}
+	return string(runes[from:to]), nil
+}
+
+// hashRow returns the hex SHA-256 of the argument values (all columns for *), encoded as one
+// comma-separated CSV line, so a hash can be reproduced with e.g. printf 'a,b\n' | sha256sum
+func (e *Evaluator) hashRow(call *sqlparser.FuncCall, row int) (interface{}, error) {
+	var fields []string
+	for _, arg := range call.Args {
+		if _, ok := arg.(*sqlparser.StarExpr); ok {
+			for col, name := range e.df.Names() {
+				// The row positions added while matching UPDATE and DELETE rows are not data
+				if name == rowIndexColumn {
+					continue
+				}
+				fields = append(fields, formatValue(e.cellValue(row, col)))
+			}
+			continue
+		}
+		value, err := e.Eval(arg, row)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, formatValue(value))
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("SHA256() needs * or at least one column")
+	}
+
+	sum := hashFields(fields)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hashFields returns the SHA-256 of fields encoded as one comma-separated CSV line
+func hashFields(fields []string) [sha256.Size]byte {
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	writer.Write(fields)
+	writer.Flush()
+	return sha256.Sum256([]byte(line.String()))
+}
+
+// evalCast converts a value to the CAST type. NULL stays NULL; a value that cannot be
+// converted is an error naming the row, rather than a silent NULL.
+func (e *Evaluator) evalCast(ex *sqlparser.CastExpr, row int) (interface{}, error) {
+	value, err := e.Eval(ex.Expr, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	switch ex.Type {
+	case "STRING":
+		return formatValue(value), nil
+	case "INT", "FLOAT":
+		if f, ok := toNumber(value); ok {
+			if ex.Type == "INT" {
+				return math.Trunc(f), nil
+			}
+			return f, nil
+		}
+	case "DATE":
+		if date, ok := e.parseDate(formatValue(value)); ok {
+			return date.Format("2006-01-02"), nil
+		}
+	case "BOOL":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			return v != 0, nil
+		default:
+			if b, err := strconv.ParseBool(strings.TrimSpace(formatValue(v))); err == nil {
+				return b, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("CAST: cannot convert '%s' to %s (row %d)", formatValue(value), ex.Type, row+1)
+}
@@ -0,0 +1,221 @@
+package operations
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// HandleGroupedAggregation computes aggregation functions per distinct combination of the group columns.
+// The result has the group columns first, then the aggregates, unless outputCols lists the
+// columns to show in select-list order.
+func (ops *CSVOperations) HandleGroupedAggregation(aggFuncs []AggregateFunction, whereCond string, groupCols []string, having, orderBy string, limit int, outputCols []string) error {
+	if err := ops.ValidateColumns(groupCols); err != nil {
+		return fmt.Errorf("GROUP BY error: %v", err)
+	}
+
+	// Apply WHERE condition first
+	filteredDF, err := ops.ApplyWhereCondition(ops.DataFrame, whereCond)
+	if err != nil {
+		return fmt.Errorf("WHERE condition error: %v", err)
+	}
+
+	// Aggregates referenced only by HAVING or ORDER BY are computed too, then dropped
+	havingCond, extraFuncs, err := ops.ParseHaving(having, aggFuncs)
+	if err != nil {
+		return fmt.Errorf("HAVING condition error: %v", err)
+	}
+	orderFuncs, err := ops.orderByAggregates(orderBy, groupCols, append(aggFuncs, extraFuncs...))
+	if err != nil {
+		return fmt.Errorf("ORDER BY error: %v", err)
+	}
+	extraFuncs = append(extraFuncs, orderFuncs...)
+
+	groupedDF, err := ops.GroupAggregate(filteredDF, groupCols, append(aggFuncs, extraFuncs...))
+	if err != nil {
+		return err
+	}
+
+	// Apply HAVING to the grouped results
+	if havingCond != "" {
+		groupedDF, err = ops.ApplyWhereCondition(groupedDF, havingCond)
+		if err != nil {
+			return fmt.Errorf("HAVING condition error: %v", err)
+		}
+	}
+
+	// Apply ORDER BY
+	orderedDF, err := ops.ApplyOrderBy(groupedDF, orderBy)
+	if err != nil {
+		return fmt.Errorf("ORDER BY error: %v", err)
+	}
+	switch {
+	case len(outputCols) > 0:
+		orderedDF = orderedDF.Select(outputCols)
+	case len(extraFuncs) > 0:
+		orderedDF = orderedDF.Select(orderedDF.Names()[:len(groupCols)+len(aggFuncs)])
+	}
+
+	// Apply LIMIT
+	limitedDF := ops.ApplyLimit(orderedDF, limit)
+
+	for _, aggFunc := range aggFuncs {
+		ops.aggregates = append(ops.aggregates, aggFunc.Alias)
+	}
+	if err := ops.PrintDataFrame(limitedDF); err != nil {
+		return err
+	}
+	if !ops.RawOutput {
+		fmt.Printf("\n(%d groups)\n", limitedDF.Nrow())
+	}
+	return nil
+}
+
+// GroupAggregate builds a result table with one row per group: the group columns followed by each aggregate
+func (ops *CSVOperations) GroupAggregate(df dataframe.DataFrame, groupCols []string, aggFuncs []AggregateFunction) (dataframe.DataFrame, error) {
+	df, err := ops.withDerivedColumns(df, aggFuncs)
+	if err != nil {
+		return dataframe.DataFrame{}, fmt.Errorf("aggregation error: %v", err)
+	}
+	for _, aggFunc := range aggFuncs {
+		if indexOf(df.Names(), aggFunc.Column) < 0 {
+			return dataframe.DataFrame{}, fmt.Errorf("column '%s' does not exist in CSV", aggFunc.Column)
+		}
+	}
+
+	// Collect row indices per group, keeping groups in order of first appearance
+	groupIndex := make(map[string]int)
+	var groupKeys [][]string
+	var groupRows [][]int
+	for i := 0; i < df.Nrow(); i++ {
+		key := make([]string, len(groupCols))
+		for j, col := range groupCols {
+			key[j] = CellText(df.Col(col).Elem(i))
+		}
+		signature := strings.Join(key, "\x1f")
+
+		idx, exists := groupIndex[signature]
+		if !exists {
+			idx = len(groupKeys)
+			groupIndex[signature] = idx
+			groupKeys = append(groupKeys, key)
+			groupRows = append(groupRows, nil)
+		}
+		groupRows[idx] = append(groupRows[idx], i)
+	}
+
+	header := append([]string{}, groupCols...)
+	for _, aggFunc := range aggFuncs {
+		header = append(header, aggFunc.Alias)
+	}
+
+	records := [][]string{header}
+	for g, key := range groupKeys {
+		groupDF := df.Subset(groupRows[g])
+		row := append([]string{}, key...)
+		for _, aggFunc := range aggFuncs {
+			result, err := ops.CalculateAggregation(groupDF, aggFunc)
+			if err != nil {
+				return dataframe.DataFrame{}, fmt.Errorf("aggregation error: %v", err)
+			}
+			row = append(row, aggregateCell(result))
+		}
+		records = append(records, row)
+	}
+
+	// Load through records so numeric aggregates get numeric column types. They keep full
+	// precision for HAVING, ORDER BY and -output; only printing rounds them.
+	return RecordsToDataFrame(records)
+}
+
+// ParseHaving checks a HAVING condition over the grouped result columns,
+// returning any aggregates it references that are not already being computed
+func (ops *CSVOperations) ParseHaving(having string, aggFuncs []AggregateFunction) (string, []AggregateFunction, error) {
+	if having == "" {
+		return "", nil, nil
+	}
+
+	expr, err := sqlparser.ParseExpr(having)
+	if err != nil {
+		return "", nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, aggFunc := range aggFuncs {
+		known[aggFunc.Alias] = true
+	}
+
+	var extra []AggregateFunction
+	var walkErr error
+	sqlparser.Walk(expr, func(node sqlparser.Expr) {
+		call, ok := node.(*sqlparser.FuncCall)
+		if !ok || known[call.String()] || walkErr != nil {
+			return
+		}
+		funcs, ok := ops.ParseAggregations(call.String())
+		if !ok {
+			walkErr = fmt.Errorf("unsupported function: %s", call.Name)
+			return
+		}
+		known[call.String()] = true
+		extra = append(extra, funcs...)
+	})
+	if walkErr != nil {
+		return "", nil, walkErr
+	}
+	return having, extra, nil
+}
+
+// orderByAggregates returns the aggregates that a grouped ORDER BY sorts on when they are not
+// already result columns, as in ORDER BY COUNT(*) DESC for a list that names it otherwise
+func (ops *CSVOperations) orderByAggregates(orderBy string, groupCols []string, aggFuncs []AggregateFunction) ([]AggregateFunction, error) {
+	if orderBy == "" {
+		return nil, nil
+	}
+	keys, err := orderByKeys(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var extra []AggregateFunction
+	for _, key := range keys {
+		if indexOf(groupCols, key.Column) >= 0 || slices.ContainsFunc(aggFuncs, func(aggFunc AggregateFunction) bool {
+			return aggFunc.Alias == key.Column
+		}) {
+			continue
+		}
+		if funcs, ok := ops.ParseAggregations(key.Column); ok {
+			extra = append(extra, funcs...)
+		}
+	}
+	return extra, nil
+}
+
+// aggregateCell renders an aggregation result at full precision for a result table
+func aggregateCell(value interface{}) string {
+	if elem, ok := value.(series.Element); ok {
+		return CellText(elem)
+	}
+	return formatValue(value)
+}
+
+// FormatAggregateValue renders an aggregation result the same way the aggregation printer does.
+// NULL, as for the AVG of no values, is an empty cell like any other NULL.
+func FormatAggregateValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%.0f", v)
+		}
+		return fmt.Sprintf("%.2f", v)
+	case series.Element:
+		return CellText(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// normalizeHeader trims a column name, lowercases it and joins its words with underscores, so
+// " Max Severity " becomes max_severity
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "_"))
+}
+
+// normalizeHeaders applies -normalize-headers to a header row, remembering the original names
+// so output can show them. Two columns with the same normalized name are an error, since
+// neither could be referenced.
+func (ops *CSVOperations) normalizeHeaders(header []string) ([]string, error) {
+	if !ops.Normalize {
+		return header, nil
+	}
+	if ops.headerNames == nil {
+		ops.headerNames = make(map[string]string)
+	}
+
+	normalized := make([]string, len(header))
+	seen := make(map[string]string)
+	for i, name := range header {
+		normalized[i] = normalizeHeader(name)
+		if other, ok := seen[normalized[i]]; ok {
+			return nil, fmt.Errorf("columns %q and %q both normalize to %s", other, name, normalized[i])
+		}
+		seen[normalized[i]] = name
+		// Headers already normalized by an earlier read keep their recorded original
+		if _, ok := ops.headerNames[normalized[i]]; !ok && normalized[i] != name {
+			ops.headerNames[normalized[i]] = name
+		}
+	}
+	return normalized, nil
+}
+
+// ParseRenames parses a -rename list such as "old1=new1,old2=new2" into new names by old name
+func ParseRenames(spec string) (map[string]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	renames := make(map[string]string)
+	targets := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		oldName, newName, found := strings.Cut(part, "=")
+		oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+		if !found || oldName == "" || newName == "" {
+			return nil, fmt.Errorf("invalid -rename entry: %s (expected old=new)", strings.TrimSpace(part))
+		}
+		if _, dup := renames[oldName]; dup {
+			return nil, fmt.Errorf("column '%s' is renamed twice", oldName)
+		}
+		if targets[newName] {
+			return nil, fmt.Errorf("two columns are renamed to '%s'", newName)
+		}
+		renames[oldName] = newName
+		targets[newName] = true
+	}
+	return renames, nil
+}
+
+// readHeader prepares a header row as it is read: -normalize-headers first, then -rename,
+// whose old names are the normalized ones when both are given. Renames of columns the file
+// does not have are skipped here, as other files read alongside the input share them;
+// checkRenames reports them for the input file.
+func (ops *CSVOperations) readHeader(header []string) ([]string, error) {
+	header, err := ops.normalizeHeaders(header)
+	if err != nil || len(ops.Renames) == 0 {
+		return header, err
+	}
+	renamed := make([]string, len(header))
+	for i, name := range header {
+		renamed[i] = name
+		if newName, ok := ops.Renames[name]; ok {
+			renamed[i] = newName
+		}
+	}
+	for i, name := range renamed {
+		if j := indexOf(renamed, name); j != i {
+			return nil, fmt.Errorf("-rename: column '%s' already exists", name)
+		}
+	}
+	return renamed, nil
+}
+
+// checkRenames reports -rename entries whose old name is not a column of the input file
+func (ops *CSVOperations) checkRenames() error {
+	for oldName, newName := range ops.Renames {
+		if indexOf(ops.Headers, newName) < 0 {
+			return fmt.Errorf("-rename column '%s' does not exist in CSV", oldName)
+		}
+	}
+	return nil
+}
+
+// originalHeader returns the name in the file of a normalized column name
+func (ops *CSVOperations) originalHeader(name string) string {
+	if orig, ok := ops.headerNames[name]; ok {
+		return orig
+	}
+	return name
+}
+
+// originalHeaders maps normalized column names back to the names in the file; other names,
+// such as aliases and aggregates, are kept
+func (ops *CSVOperations) originalHeaders(names []string) []string {
+	if len(ops.headerNames) == 0 {
+		return names
+	}
+	original := make([]string, len(names))
+	for i, name := range names {
+		original[i] = name
+		if orig := ops.originalHeader(name); indexOf(names, orig) < 0 {
+			original[i] = orig
+		}
+	}
+	return original
+}
+
+// withOriginalHeaders renames the normalized columns of df back to their names in the file
+func (ops *CSVOperations) withOriginalHeaders(df dataframe.DataFrame) dataframe.DataFrame {
+	if len(ops.headerNames) == 0 || df.Ncol() == 0 {
+		return df
+	}
+	renamed := df.Copy()
+	if err := renamed.SetNames(ops.originalHeaders(df.Names())...); err != nil {
+		return df
+	}
+	return renamed
+}
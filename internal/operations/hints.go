@@ -0,0 +1,37 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strategy hints accepted by -hint. no-index and hash-join name what seesv always does, so
+// they are accepted for scripts that pass them but change nothing.
+const (
+	HintStream   = "stream"    // HintStream requires the streaming reader and fails if the file cannot be streamed
+	HintNoStream = "no-stream" // HintNoStream loads the whole file even where a streaming path exists
+	HintNoIndex  = "no-index"  // HintNoIndex scans every row; there are no indexes, so every query does
+	HintHashJoin = "hash-join" // HintHashJoin joins through a hash of keys, as JOIN, -join, -intersect and -except do
+)
+
+var knownHints = []string{HintStream, HintNoStream, HintNoIndex, HintHashJoin}
+
+// ParseHints parses a comma-separated list of strategy hints
+func ParseHints(spec string) (map[string]bool, error) {
+	hints := make(map[string]bool)
+	if strings.TrimSpace(spec) == "" {
+		return hints, nil
+	}
+
+	for _, hint := range strings.Split(spec, ",") {
+		hint = strings.ToLower(strings.TrimSpace(hint))
+		if indexOf(knownHints, hint) < 0 {
+			return nil, fmt.Errorf("unknown hint: %s (supported: %s)", hint, strings.Join(knownHints, ", "))
+		}
+		hints[hint] = true
+	}
+	if hints[HintStream] && hints[HintNoStream] {
+		return nil, fmt.Errorf("hints %s and %s conflict", HintStream, HintNoStream)
+	}
+	return hints, nil
+}
@@ -0,0 +1,81 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HumanizeUnit selects how a column's numbers are abbreviated in table output
+type HumanizeUnit string
+
+const (
+	HumanizeSI    HumanizeUnit = "si"    // 1532676 -> 1.5M
+	HumanizeBytes HumanizeUnit = "bytes" // 1532676 -> 1.46 MiB
+)
+
+// ParseHumanizeSpec parses a -humanize value in format "col1,col2:bytes,col3:si".
+// Columns without a unit use bytes when their name mentions bytes or size, SI otherwise.
+func ParseHumanizeSpec(spec string) (map[string]HumanizeUnit, error) {
+	units := make(map[string]HumanizeUnit)
+	if strings.TrimSpace(spec) == "" {
+		return units, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		column, unit := strings.TrimSpace(entry), ""
+		if idx := strings.LastIndex(column, ":"); idx >= 0 {
+			column, unit = strings.TrimSpace(column[:idx]), strings.ToLower(strings.TrimSpace(column[idx+1:]))
+		}
+		if column == "" {
+			return nil, fmt.Errorf("invalid humanize column: %s", strings.TrimSpace(entry))
+		}
+
+		switch HumanizeUnit(unit) {
+		case HumanizeSI, HumanizeBytes:
+			units[column] = HumanizeUnit(unit)
+		case "":
+			lower := strings.ToLower(column)
+			if strings.Contains(lower, "byte") || strings.Contains(lower, "size") {
+				units[column] = HumanizeBytes
+			} else {
+				units[column] = HumanizeSI
+			}
+		default:
+			return nil, fmt.Errorf("invalid humanize unit: %s (use si or bytes)", unit)
+		}
+	}
+	return units, nil
+}
+
+// HumanizeValue abbreviates a numeric value, returning other text unchanged
+func HumanizeValue(value string, unit HumanizeUnit) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return value
+	}
+
+	if unit == HumanizeBytes {
+		return humanizeScaled(f, 1024, []string{" B", " KiB", " MiB", " GiB", " TiB", " PiB", " EiB"}, "%.2f")
+	}
+	return humanizeScaled(f, 1000, []string{"", "k", "M", "G", "T", "P", "E"}, "%.1f")
+}
+
+// humanizeScaled divides f by base until it fits, then appends the matching suffix
+func humanizeScaled(f, base float64, suffixes []string, precision string) string {
+	abs := math.Abs(f)
+	if abs < base {
+		return strconv.FormatFloat(f, 'f', -1, 64) + suffixes[0]
+	}
+
+	i := 0
+	for abs >= base && i < len(suffixes)-1 {
+		abs /= base
+		f /= base
+		i++
+	}
+	text := fmt.Sprintf(precision, f)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return text + suffixes[i]
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/go-gota/gota/dataframe"
-	"github.com/go-gota/gota/series"
 )
 
 // Insert adds a new row to the CSV file
@@ -15,6 +14,23 @@ func (ops *CSVOperations) Insert(insertVals string) error {
 		return fmt.Errorf("INSERT values cannot be empty")
 	}
 
+	if ops.IsEmpty() {
+		return fmt.Errorf("%s is empty: use -header \"col1,col2,...\" to bootstrap its columns", ops.FilePath)
+	}
+
+	// Rows separated by semicolons are inserted together, rewriting the file once
+	if specs := splitInsertRows(insertVals); len(specs) > 1 {
+		rows := make([]map[string]string, len(specs))
+		for i, spec := range specs {
+			values, err := ops.ParseInsertValues(spec)
+			if err != nil {
+				return fmt.Errorf("failed to parse INSERT values of row %d: %v", i+1, err)
+			}
+			rows[i] = values
+		}
+		return ops.BatchInsert(rows)
+	}
+
 	// Parse the insert values
 	values, err := ops.ParseInsertValues(insertVals)
 	if err != nil {
@@ -30,17 +46,48 @@ func (ops *CSVOperations) Insert(insertVals string) error {
 	newRow := ops.CreateInsertRow(values)
 
 	// Add the new row to the dataframe
-	newDF := ops.AppendRowToDataFrame(ops.DataFrame, newRow)
+	newDF, err := ops.AppendRows(ops.DataFrame, [][]string{newRow})
+	if err != nil {
+		return err
+	}
 
 	// Save back to the original file
 	if err := ops.SaveDataFrameToCSV(newDF, ops.FilePath); err != nil {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully inserted 1 row into %s\n", ops.FilePath)
+	ops.reportMutation("INSERT", 0, 1, fmt.Sprintf("Successfully inserted 1 row into %s", ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
+// splitInsertRows splits INSERT values on the semicolons between rows, outside quotes, skipping
+// empty rows such as the one after a trailing semicolon
+func splitInsertRows(insertVals string) []string {
+	var rows []string
+	var quote rune
+	start := 0
+	add := func(row string) {
+		if strings.TrimSpace(row) != "" {
+			rows = append(rows, row)
+		}
+	}
+	for i, r := range insertVals {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';':
+			add(insertVals[start:i])
+			start = i + 1
+		}
+	}
+	add(insertVals[start:])
+	return rows
+}
+
 // ParseInsertValues parses INSERT values in format "col1=val1,col2=val2"
 func (ops *CSVOperations) ParseInsertValues(insertVals string) (map[string]string, error) {
 	values := make(map[string]string)
@@ -78,13 +125,8 @@ func (ops *CSVOperations) ValidateInsertValues(values map[string]string) error {
 		}
 	}
 	
-	// In a more sophisticated implementation, you might check for:
-	// - Required columns (non-nullable)
-	// - Data type validation
-	// - Constraint validation
-	// For now, we'll allow partial inserts and fill missing columns with empty values
-	
-	return nil
+	// Columns with allowed values reject anything else; missing columns are left empty
+	return ops.checkAllowedValues(values)
 }
 
 // CreateInsertRow creates a properly ordered row for insertion
@@ -103,39 +145,165 @@ func (ops *CSVOperations) CreateInsertRow(values map[string]string) []string {
 	return row
 }
 
-// AppendRowToDataFrame adds a new row to the dataframe
-func (ops *CSVOperations) AppendRowToDataFrame(df dataframe.DataFrame, newRow []string) dataframe.DataFrame {
-	// Convert row to series
-	seriesList := make([]series.Series, len(newRow))
-	for i, val := range newRow {
-		seriesList[i] = series.New([]string{val}, series.String, ops.Headers[i])
+// AppendRows adds rows, in header order, to the dataframe. The result is reloaded from text,
+// so a value that does not fit its column type, such as "abc" in an int column, turns the
+// column into text instead of being lost as NULL.
+func (ops *CSVOperations) AppendRows(df dataframe.DataFrame, rows [][]string) (dataframe.DataFrame, error) {
+	df, err := RecordsToDataFrame(append(dataFrameRecords(df), rows...))
+	if err != nil {
+		return df, fmt.Errorf("failed to insert rows: %v", err)
 	}
-	
-	// Create a new dataframe with the single row
-	newRowDF := dataframe.New(seriesList...)
-	
-	// Concatenate with original dataframe
-	return df.Concat(newRowDF)
+	return df, nil
 }
 
-// BatchInsert allows inserting multiple rows (for future enhancement)
+// Upsert updates the rows whose key columns hold the given values, or inserts the values as a
+// new row when no row does. Keys are compared as text, and every key column must be given.
+func (ops *CSVOperations) Upsert(upsertVals string, keyCols []string) error {
+	if upsertVals == "" {
+		return fmt.Errorf("UPSERT values cannot be empty")
+	}
+	if len(keyCols) == 0 {
+		return fmt.Errorf("-upsert requires -key with the key columns")
+	}
+	if ops.IsEmpty() {
+		return fmt.Errorf("%s is empty: use -header \"col1,col2,...\" to bootstrap its columns", ops.FilePath)
+	}
+
+	values, err := ops.ParseInsertValues(upsertVals)
+	if err != nil {
+		return fmt.Errorf("failed to parse UPSERT values: %v", err)
+	}
+	if err := ops.ValidateInsertValues(values); err != nil {
+		return fmt.Errorf("UPSERT validation failed: %v", err)
+	}
+	if err := ops.ValidateColumns(keyCols); err != nil {
+		return fmt.Errorf("UPSERT validation failed: %v", err)
+	}
+	for _, key := range keyCols {
+		if _, ok := values[key]; !ok {
+			return fmt.Errorf("UPSERT values must set the key column '%s'", key)
+		}
+	}
+
+	df := ops.DataFrame
+	rows := make([][]string, df.Nrow())
+	updated := 0
+	for i := range rows {
+		rows[i] = RowValues(df, i)
+		match := true
+		for _, key := range keyCols {
+			if rows[i][indexOf(ops.Headers, key)] != values[key] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		for column, value := range values {
+			rows[i][indexOf(ops.Headers, column)] = value
+		}
+		updated++
+	}
+
+	if updated == 0 {
+		rows = append(rows, ops.CreateInsertRow(values))
+	}
+	if err := ops.SaveDataFrameToCSV(NewStringDataFrame(ops.Headers, rows), ops.FilePath); err != nil {
+		return fmt.Errorf("failed to save updated CSV: %v", err)
+	}
+
+	if updated == 0 {
+		ops.reportMutation("UPSERT", 0, 1, fmt.Sprintf("Successfully inserted 1 row into %s", ops.Workspace.Describe(ops.FilePath)))
+	} else {
+		ops.reportMutation("UPSERT", updated, updated, fmt.Sprintf("Successfully updated %d rows in %s", updated, ops.Workspace.Describe(ops.FilePath)))
+	}
+	return nil
+}
+
+// InsertIfAbsent inserts the rows of insertVals whose key columns hold values that no row of
+// the file, or earlier row of insertVals, has yet. Keys are compared as text, and every key
+// column must be given, so appending the same recon output twice adds nothing the second time.
+func (ops *CSVOperations) InsertIfAbsent(insertVals string, keyCols []string) error {
+	if insertVals == "" {
+		return fmt.Errorf("INSERT values cannot be empty")
+	}
+	if ops.IsEmpty() {
+		return fmt.Errorf("%s is empty: use -header \"col1,col2,...\" to bootstrap its columns", ops.FilePath)
+	}
+	if err := ops.ValidateColumns(keyCols); err != nil {
+		return fmt.Errorf("INSERT validation failed: %v", err)
+	}
+
+	key := func(row []string) string {
+		parts := make([]string, len(keyCols))
+		for i, column := range keyCols {
+			parts[i] = row[indexOf(ops.Headers, column)]
+		}
+		return strings.Join(parts, "\x00")
+	}
+	present := make(map[string]bool)
+	for i := 0; i < ops.DataFrame.Nrow(); i++ {
+		present[key(RowValues(ops.DataFrame, i))] = true
+	}
+
+	specs := splitInsertRows(insertVals)
+	var newRows [][]string
+	for i, spec := range specs {
+		values, err := ops.ParseInsertValues(spec)
+		if err != nil {
+			return fmt.Errorf("failed to parse INSERT values of row %d: %v", i+1, err)
+		}
+		if err := ops.ValidateInsertValues(values); err != nil {
+			return fmt.Errorf("row %d validation failed: %v", i+1, err)
+		}
+		for _, column := range keyCols {
+			if _, ok := values[column]; !ok {
+				return fmt.Errorf("row %d must set the key column '%s'", i+1, column)
+			}
+		}
+		row := ops.CreateInsertRow(values)
+		if present[key(row)] {
+			continue
+		}
+		present[key(row)] = true
+		newRows = append(newRows, row)
+	}
+
+	message := fmt.Sprintf("Successfully inserted %d rows into %s (%d already present)", len(newRows), ops.Workspace.Describe(ops.FilePath), len(specs)-len(newRows))
+	if len(newRows) == 0 {
+		ops.reportMutation("INSERT", len(specs), 0, message)
+		return nil
+	}
+	df, err := ops.AppendRows(ops.DataFrame, newRows)
+	if err != nil {
+		return err
+	}
+	if err := ops.SaveDataFrameToCSV(df, ops.FilePath); err != nil {
+		return fmt.Errorf("failed to save updated CSV: %v", err)
+	}
+
+	ops.reportMutation("INSERT", len(specs), len(newRows), message)
+	return nil
+}
+
+// BatchInsert appends several rows and rewrites the file once. No row is inserted unless
+// every row is valid.
 func (ops *CSVOperations) BatchInsert(rows []map[string]string) error {
 	if len(rows) == 0 {
 		return fmt.Errorf("no rows to insert")
 	}
 
-	df := ops.DataFrame
-	
-	// Process each row
+	newRows := make([][]string, len(rows))
 	for i, values := range rows {
-		// Validate values
 		if err := ops.ValidateInsertValues(values); err != nil {
 			return fmt.Errorf("row %d validation failed: %v", i+1, err)
 		}
-		
-		// Create and append row
-		newRow := ops.CreateInsertRow(values)
-		df = ops.AppendRowToDataFrame(df, newRow)
+		newRows[i] = ops.CreateInsertRow(values)
+	}
+	df, err := ops.AppendRows(ops.DataFrame, newRows)
+	if err != nil {
+		return err
 	}
 
 	// Save back to file
@@ -143,41 +311,83 @@ func (ops *CSVOperations) BatchInsert(rows []map[string]string) error {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully inserted %d rows into %s\n", len(rows), ops.FilePath)
+	ops.reportMutation("INSERT", 0, len(rows), fmt.Sprintf("Successfully inserted %d rows into %s", len(rows), ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
-// InsertFromCSV inserts data from another CSV file (for future enhancement)
-func (ops *CSVOperations) InsertFromCSV(sourceFile string) error {
-	// Open source CSV file
-	srcFile, err := os.Open(sourceFile)
+// InsertFromCSV inserts data from another CSV file, mapping source columns onto target columns
+// and applying the coerce policy to cells that do not fit the target column types. Source cells
+// are copied as written, not as read by type.
+func (ops *CSVOperations) InsertFromCSV(sourceFile string, mapping ColumnMapping, interactive bool, policy CoercePolicy) error {
+	if ops.IsEmpty() {
+		return fmt.Errorf("%s is empty: use -header \"col1,col2,...\" to bootstrap its columns", ops.FilePath)
+	}
+
+	// Read source records
+	data, err := readInput(sourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to read source CSV: %v", err)
+	}
+	records, _, err := ops.ReadRecords(data)
 	if err != nil {
-		return fmt.Errorf("failed to open source file: %v", err)
+		return fmt.Errorf("failed to read source CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("source file is empty: %s", sourceFile)
 	}
-	defer srcFile.Close()
 
-	// Read source dataframe
-	srcDF := dataframe.ReadCSV(srcFile)
-	if srcDF.Err != nil {
-		return fmt.Errorf("failed to read source CSV: %v", srcDF.Err)
+	// Work out where each source column goes
+	srcHeaders := records[0]
+	resolved, err := ops.ResolveColumnMapping(srcHeaders, mapping, interactive)
+	if err != nil {
+		return fmt.Errorf("incompatible column in source file: %v", err)
 	}
 
-	// Validate that source has compatible columns
-	srcHeaders := srcDF.Names()
-	for _, header := range srcHeaders {
-		if err := ops.ValidateColumns([]string{header}); err != nil {
-			return fmt.Errorf("incompatible column in source file: %v", err)
+	// Build rows in target column order, leaving unmapped columns empty
+	var newRows [][]string
+	var allIssues []CoercionIssue
+	for i, record := range records[1:] {
+		values := make(map[string]string)
+		for j, header := range srcHeaders {
+			if dst, ok := resolved[header]; ok && j < len(record) {
+				values[dst] = record[j]
+			}
+		}
+
+		if err := ops.checkAllowedValues(values); err != nil {
+			return fmt.Errorf("row %d of %s: %v", i+1, sourceFile, err)
+		}
+
+		issues := ops.CoerceRow(i+1, values)
+		allIssues = append(allIssues, issues...)
+		if len(issues) > 0 && policy == CoerceSkipRow {
+			continue
 		}
+		newRows = append(newRows, ops.CreateInsertRow(values))
+	}
+
+	// The report goes to stderr when stdout carries the JSON summary
+	report := os.Stdout
+	if ops.JSONSummary {
+		report = os.Stderr
+	}
+	PrintCoercionReport(report, allIssues)
+	if len(allIssues) > 0 && policy == CoerceStrict {
+		return fmt.Errorf("INSERT aborted: %d cells do not match the target column types (use -coerce lossy or skip-row)", len(allIssues))
 	}
 
-	// Concatenate dataframes
-	combinedDF := ops.DataFrame.Concat(srcDF)
+	df := ops.DataFrame
+	if len(newRows) > 0 {
+		if df, err = ops.AppendRows(df, newRows); err != nil {
+			return err
+		}
+	}
 
 	// Save back to original file
-	if err := ops.SaveDataFrameToCSV(combinedDF, ops.FilePath); err != nil {
+	if err := ops.SaveDataFrameToCSV(df, ops.FilePath); err != nil {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
 
-	fmt.Printf("Successfully inserted %d rows from %s into %s\n", srcDF.Nrow(), sourceFile, ops.FilePath)
+	ops.reportMutation("INSERT", len(records)-1, len(newRows), fmt.Sprintf("Successfully inserted %d rows from %s into %s", len(newRows), sourceFile, ops.Workspace.Describe(ops.FilePath)))
 	return nil
-}
\ No newline at end of file
+}
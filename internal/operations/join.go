@@ -0,0 +1,79 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Join types accepted by -join-type
+const (
+	JoinSemi = "semi" // JoinSemi keeps rows of the input file that have a match in the other file
+	JoinAnti = "anti" // JoinAnti keeps rows of the input file that have no match in the other file
+)
+
+// ParseJoinKey splits an -on key into the input file's column and the other file's column.
+// "id" uses the same column on both sides; "host=hostname" names each side.
+func ParseJoinKey(spec string) (string, string, error) {
+	left, right, found := strings.Cut(spec, "=")
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	if !found {
+		right = left
+	}
+	if left == "" || right == "" {
+		return "", "", fmt.Errorf("invalid join key: %s (expected column or column=other_column)", spec)
+	}
+	return left, right, nil
+}
+
+// ApplyJoinFilter keeps the rows of the loaded file that do (semi) or do not (anti) have a
+// row in the file at path with the same key. Keys are compared as text and the other file
+// is read into a set of keys, so only the input file's columns are kept.
+func (ops *CSVOperations) ApplyJoinFilter(path, joinType, onKey string) error {
+	joinType = strings.ToLower(strings.TrimSpace(joinType))
+	if joinType == "" {
+		return fmt.Errorf("-join requires -join-type %s or %s", JoinSemi, JoinAnti)
+	}
+	if joinType != JoinSemi && joinType != JoinAnti {
+		return fmt.Errorf("invalid join type: %s (use %s or %s)", joinType, JoinSemi, JoinAnti)
+	}
+	if onKey == "" {
+		return fmt.Errorf("-join requires -on with the key column")
+	}
+	leftKey, rightKey, err := ParseJoinKey(onKey)
+	if err != nil {
+		return err
+	}
+	if err := ops.ValidateColumns([]string{leftKey}); err != nil {
+		return fmt.Errorf("JOIN error: %v", err)
+	}
+
+	other, err := ops.LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load join file: %v", err)
+	}
+	if indexOf(other.Names(), rightKey) < 0 {
+		return fmt.Errorf("key column '%s' does not exist in %s", rightKey, path)
+	}
+
+	keys := make(map[string]bool)
+	col := other.Col(rightKey)
+	for i := 0; i < col.Len(); i++ {
+		keys[CellText(col.Elem(i))] = true
+	}
+
+	df := ops.DataFrame
+	leftCol := df.Col(leftKey)
+	indices := []int{}
+	for i := 0; i < df.Nrow(); i++ {
+		if keys[CellText(leftCol.Elem(i))] == (joinType == JoinSemi) {
+			indices = append(indices, i)
+		}
+	}
+
+	if len(indices) == 0 {
+		ops.DataFrame = NewStringDataFrame(df.Names(), nil)
+	} else if len(indices) < df.Nrow() {
+		ops.DataFrame = df.Subset(indices)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// isJSONObjects reports whether text starts like JSON Lines or a JSON array of objects
+func isJSONObjects(text string) bool {
+	switch {
+	case strings.HasPrefix(text, "{"):
+		rest := strings.TrimSpace(text[1:])
+		return strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "}")
+	case strings.HasPrefix(text, "["):
+		rest := strings.TrimSpace(text[1:])
+		return strings.HasPrefix(rest, "{") || rest == "]"
+	}
+	return false
+}
+
+// jsonRecords reads JSON Lines, or a JSON array of objects, as records, header first. Top-level
+// keys become columns in the order they first appear; nested objects and arrays are kept as
+// JSON text and null as an empty cell.
+func jsonRecords(text string) ([][]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if strings.HasPrefix(strings.TrimSpace(text), "[") {
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read JSON: %v", err)
+		}
+	}
+
+	var header []string
+	columns := make(map[string]int)
+	var rows [][]string
+	for decoder.More() {
+		item := len(rows) + 1
+		if tok, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read JSON (object %d): %v", item, err)
+		} else if tok != json.Delim('{') {
+			return nil, fmt.Errorf("failed to read JSON (object %d): expected an object, got %v", item, tok)
+		}
+
+		row := make([]string, len(header))
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JSON (object %d): %v", item, err)
+			}
+			key := tok.(string)
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("failed to read JSON (object %d, key %s): %v", item, key, err)
+			}
+
+			j, ok := columns[key]
+			if !ok {
+				j = len(header)
+				columns[key] = j
+				header = append(header, key)
+			}
+			for len(row) <= j {
+				row = append(row, "")
+			}
+			row[j] = jsonCell(value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read JSON (object %d): %v", item, err)
+		}
+		rows = append(rows, row)
+	}
+	if len(header) == 0 {
+		return nil, nil
+	}
+
+	// Objects read before a key first appeared lack its cell
+	records := [][]string{header}
+	for _, row := range rows {
+		records = append(records, append(row, make([]string, len(header)-len(row))...))
+	}
+	return records, nil
+}
+
+// jsonCell renders a JSON value as a cell: strings unquoted, null empty, anything else as
+// compact JSON
+func jsonCell(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
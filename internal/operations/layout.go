@@ -0,0 +1,138 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when the terminal width cannot be determined
+const defaultTerminalWidth = 80
+
+// Output formats accepted by -format
+const (
+	FormatTable    = "table"    // aligned columns (default)
+	FormatRecord   = "record"   // one "column: value" line per field, rows separated by record headers
+	FormatSQLite   = "sqlite"   // a table of the SQLite database named by -output
+	FormatTemplate = "template" // each row rendered through the -template
+)
+
+// ParseOutputFormat validates a -format value, defaulting to table
+func ParseOutputFormat(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", FormatTable:
+		return FormatTable, nil
+	case FormatRecord, FormatSQLite, FormatTemplate:
+		return strings.ToLower(strings.TrimSpace(value)), nil
+	default:
+		return "", fmt.Errorf("invalid output format: %s (use table, record, sqlite or template)", value)
+	}
+}
+
+// VisibleColumns applies the -only-cols, -hide-cols and -reorder presets to the result columns.
+// Names that are not part of the result are ignored.
+func (ops *CSVOperations) VisibleColumns(df dataframe.DataFrame) dataframe.DataFrame {
+	if len(ops.OnlyCols) == 0 && len(ops.HideCols) == 0 && len(ops.Reorder) == 0 {
+		return df
+	}
+
+	names := df.Names()
+	var keep []string
+	if len(ops.OnlyCols) > 0 {
+		for _, col := range ops.OnlyCols {
+			if indexOf(names, col) >= 0 && indexOf(keep, col) < 0 {
+				keep = append(keep, col)
+			}
+		}
+	} else {
+		keep = names
+	}
+
+	var visible []string
+	for _, col := range keep {
+		if indexOf(ops.HideCols, col) < 0 {
+			visible = append(visible, col)
+		}
+	}
+	visible = reorderNames(visible, ops.Reorder)
+	if slices.Equal(visible, names) {
+		return df
+	}
+	if len(visible) == 0 {
+		return NewStringDataFrame(nil, nil)
+	}
+	return df.Select(visible)
+}
+
+// reorderNames moves the names listed in first to the front, in that order, keeping the
+// others after them in their original order. Listed names not in names are ignored.
+func reorderNames(names, first []string) []string {
+	if len(first) == 0 {
+		return names
+	}
+	ordered := make([]string, 0, len(names))
+	for _, name := range first {
+		if indexOf(names, name) >= 0 && indexOf(ordered, name) < 0 {
+			ordered = append(ordered, name)
+		}
+	}
+	for _, name := range names {
+		if indexOf(ordered, name) < 0 {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered
+}
+
+// TableWidth returns the number of characters a table row needs for columns of the given widths
+func TableWidth(widths []int) int {
+	if len(widths) == 0 {
+		return 0
+	}
+	total := (len(widths) - 1) * 3
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
+
+// TerminalWidth reports the width of the terminal on stdout, then $COLUMNS, falling back to 80
+func TerminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// PrintVertical prints each row as a block of "column: value" lines, like psql's expanded mode
+func (ops *CSVOperations) PrintVertical(df dataframe.DataFrame) {
+	headers := df.Names()
+	nameWidth := 0
+	for _, header := range headers {
+		nameWidth = max(nameWidth, DisplayWidth(header))
+	}
+
+	for i := 0; i < df.Nrow(); i++ {
+		title := fmt.Sprintf("-[ RECORD %d ]", i+1)
+		fmt.Println(title + strings.Repeat("-", max(nameWidth+16-len(title), 4)))
+		for j, header := range headers {
+			text := ops.displayText(df, i, j)
+			if unit, ok := ops.Humanize[header]; ok {
+				text = HumanizeValue(text, unit)
+			}
+			valueColor := ""
+			if indexOf(ops.highlight, header) >= 0 {
+				valueColor = ansiHighlight
+			}
+			fmt.Printf("%s %s\n", ops.colorize(padRight(header+":", nameWidth+1), ansiBold), ops.colorize(text, valueColor))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package operations
+
+// likePattern is a compiled LIKE pattern: literal segments separated by % wildcards,
+// where _ inside a segment matches any single character. The first segment is anchored
+// at the start of the text and the last at the end (either is empty when the pattern
+// starts or ends with %).
+type likePattern struct {
+	segments [][]likeToken
+}
+
+// likeToken is one character of a segment; any is set for the _ wildcard
+type likeToken struct {
+	r   rune
+	any bool
+}
+
+// compileLike translates a LIKE pattern. A backslash escapes the next %, _ or backslash.
+func compileLike(pattern string) *likePattern {
+	compiled := &likePattern{}
+	var segment []likeToken
+	runes := []rune(pattern)
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			segment = append(segment, likeToken{r: runes[i]})
+		case r == '%':
+			compiled.segments = append(compiled.segments, segment)
+			segment = nil
+		case r == '_':
+			segment = append(segment, likeToken{any: true})
+		default:
+			segment = append(segment, likeToken{r: r})
+		}
+	}
+	compiled.segments = append(compiled.segments, segment)
+	return compiled
+}
+
+// Match reports whether text matches the whole pattern. Segments between % wildcards are
+// matched greedily left to right, which is exact because % can absorb any gap.
+func (p *likePattern) Match(text string) bool {
+	runes := []rune(text)
+	segments := p.segments
+
+	// Without any %, the pattern must cover the text exactly
+	if len(segments) == 1 {
+		return len(runes) == len(segments[0]) && matchAt(runes, 0, segments[0])
+	}
+
+	first, last := segments[0], segments[len(segments)-1]
+	if len(first)+len(last) > len(runes) {
+		return false
+	}
+	if !matchAt(runes, 0, first) || !matchAt(runes, len(runes)-len(last), last) {
+		return false
+	}
+
+	// Find each middle segment in order between the fixed prefix and suffix
+	pos, end := len(first), len(runes)-len(last)
+	for _, segment := range segments[1 : len(segments)-1] {
+		found := -1
+		for start := pos; start+len(segment) <= end; start++ {
+			if matchAt(runes, start, segment) {
+				found = start
+				break
+			}
+		}
+		if found < 0 {
+			return false
+		}
+		pos = found + len(segment)
+	}
+	return true
+}
+
+// matchAt reports whether segment matches runes starting at offset
+func matchAt(runes []rune, offset int, segment []likeToken) bool {
+	if offset < 0 || offset+len(segment) > len(runes) {
+		return false
+	}
+	for i, token := range segment {
+		if !token.any && token.r != runes[offset+i] {
+			return false
+		}
+	}
+	return true
+}
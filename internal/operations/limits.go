@@ -0,0 +1,36 @@
+package operations
+
+import (
+	"fmt"
+	"sync"
+)
+
+// writeMu is held while the input file is being rewritten, so that a command abandoned on
+// -timeout never stops halfway through a write
+var writeMu sync.Mutex
+
+// StopWrites waits for a write in progress to finish and blocks any further writes.
+// It is called when a command is abandoned, e.g. on -timeout.
+func StopWrites() {
+	writeMu.Lock()
+}
+
+// checkScanRows fails once more than MaxScanRows data rows have been read (no limit when 0)
+func (ops *CSVOperations) checkScanRows(rows int) error {
+	if ops.MaxScanRows > 0 && rows > ops.MaxScanRows {
+		return fmt.Errorf("input has more than %d rows, the -max-scan-rows limit", ops.MaxScanRows)
+	}
+	return nil
+}
+
+// scanRecordLimit is the number of records, header included, that a read may parse under
+// MaxScanRows, or 0 when there is no limit
+func (ops *CSVOperations) scanRecordLimit(dialect Dialect) int {
+	if ops.MaxScanRows == 0 {
+		return 0
+	}
+	if dialect.HasHeader {
+		return ops.MaxScanRows + 1
+	}
+	return ops.MaxScanRows
+}
@@ -0,0 +1,227 @@
+package operations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// Locale describes how numbers and dates are written in a regional export
+type Locale struct {
+	Name      string
+	Decimal   rune   // Decimal is the decimal separator
+	Group     []rune // Group lists accepted thousands separators
+	DateOrder string // DateOrder is the day/month/year order: "dmy", "mdy" or "ymd"
+	DateSeps  string // DateSeps lists accepted date separators
+}
+
+// locales holds the supported parsing profiles, keyed by lowercase name
+var locales = map[string]Locale{
+	"en-us": {Name: "en-US", Decimal: '.', Group: []rune{','}, DateOrder: "mdy", DateSeps: "/"},
+	"en-gb": {Name: "en-GB", Decimal: '.', Group: []rune{','}, DateOrder: "dmy", DateSeps: "/"},
+	"de-de": {Name: "de-DE", Decimal: ',', Group: []rune{'.'}, DateOrder: "dmy", DateSeps: "."},
+	"de-ch": {Name: "de-CH", Decimal: '.', Group: []rune{'\''}, DateOrder: "dmy", DateSeps: "."},
+	"fr-fr": {Name: "fr-FR", Decimal: ',', Group: []rune{' ', ' ', ' '}, DateOrder: "dmy", DateSeps: "/"},
+	"es-es": {Name: "es-ES", Decimal: ',', Group: []rune{'.'}, DateOrder: "dmy", DateSeps: "/"},
+	"it-it": {Name: "it-IT", Decimal: ',', Group: []rune{'.'}, DateOrder: "dmy", DateSeps: "/"},
+	"nl-nl": {Name: "nl-NL", Decimal: ',', Group: []rune{'.'}, DateOrder: "dmy", DateSeps: "-"},
+	"pt-br": {Name: "pt-BR", Decimal: ',', Group: []rune{'.'}, DateOrder: "dmy", DateSeps: "/"},
+	"ru-ru": {Name: "ru-RU", Decimal: ',', Group: []rune{' ', ' '}, DateOrder: "dmy", DateSeps: "."},
+	"ja-jp": {Name: "ja-JP", Decimal: '.', Group: []rune{','}, DateOrder: "ymd", DateSeps: "/"},
+}
+
+// ParseLocale looks up a parsing profile such as "de-DE" (also accepts de_DE)
+func ParseLocale(name string) (Locale, error) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	locale, ok := locales[key]
+	if !ok {
+		names := make([]string, 0, len(locales))
+		for _, l := range locales {
+			names = append(names, l.Name)
+		}
+		sort.Strings(names)
+		return Locale{}, fmt.Errorf("unsupported locale: %s (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return locale, nil
+}
+
+// NormalizeNumber converts a number written in the locale's format to plain "1234.5" form
+func (l Locale) NormalizeNumber(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", false
+	}
+
+	sign := ""
+	if value[0] == '-' || value[0] == '+' {
+		sign, value = value[:1], value[1:]
+		if sign == "+" {
+			sign = ""
+		}
+	}
+
+	intPart, fracPart := value, ""
+	if idx := strings.IndexRune(value, l.Decimal); idx >= 0 {
+		intPart, fracPart = value[:idx], value[idx+len(string(l.Decimal)):]
+		if fracPart == "" || !isDigits(fracPart) {
+			return "", false
+		}
+	}
+
+	// Thousands separators must split the integer part into groups of three
+	groups := strings.Split(strings.Map(func(r rune) rune {
+		if l.isGroup(r) {
+			return 0
+		}
+		return r
+	}, intPart), "\x00")
+	if len(groups) > 1 {
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return "", false
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return "", false
+			}
+		}
+	}
+
+	digits := strings.Join(groups, "")
+	if !isDigits(digits) {
+		return "", false
+	}
+
+	normalized := sign + digits
+	if fracPart != "" {
+		normalized += "." + fracPart
+	}
+	return normalized, true
+}
+
+// NormalizeDate converts a date written in the locale's order (e.g. 31.12.2024) to ISO 2006-01-02 form.
+// A trailing time of day is kept as is.
+func (l Locale) NormalizeDate(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	datePart, timePart := value, ""
+	if idx := strings.IndexAny(value, " T"); idx >= 0 {
+		datePart, timePart = value[:idx], value[idx:]
+	}
+
+	var parts []string
+	for _, sep := range l.DateSeps {
+		if strings.ContainsRune(datePart, sep) {
+			parts = strings.Split(datePart, string(sep))
+			break
+		}
+	}
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	var day, month, year string
+	switch l.DateOrder {
+	case "dmy":
+		day, month, year = parts[0], parts[1], parts[2]
+	case "mdy":
+		month, day, year = parts[0], parts[1], parts[2]
+	default:
+		year, month, day = parts[0], parts[1], parts[2]
+	}
+
+	d, errD := strconv.Atoi(day)
+	m, errM := strconv.Atoi(month)
+	y, errY := strconv.Atoi(year)
+	if errD != nil || errM != nil || errY != nil || len(year) != 4 || len(day) > 2 || len(month) > 2 {
+		return "", false
+	}
+	if d < 1 || d > 31 || m < 1 || m > 12 {
+		return "", false
+	}
+	return fmt.Sprintf("%04d-%02d-%02d%s", y, m, d, timePart), true
+}
+
+// NormalizeValue converts a single locale-formatted number or date, leaving other text unchanged
+func (l Locale) NormalizeValue(value string) string {
+	if n, ok := l.NormalizeNumber(value); ok {
+		return n
+	}
+	if d, ok := l.NormalizeDate(value); ok {
+		return d
+	}
+	return value
+}
+
+// NormalizeRecords rewrites number and date columns in place so type inference and
+// comparisons see canonical values. A column is only converted when every non-empty
+// value parses, so free text that happens to look numeric is left alone.
+func (l Locale) NormalizeRecords(records [][]string) [][]string {
+	if len(records) < 2 {
+		return records
+	}
+
+	for col := range records[0] {
+		for _, normalize := range []func(string) (string, bool){l.NormalizeNumber, l.NormalizeDate} {
+			converted := make([]string, len(records)-1)
+			ok, seen := true, false
+			for i, row := range records[1:] {
+				if col >= len(row) || strings.TrimSpace(row[col]) == "" {
+					continue
+				}
+				value, valid := normalize(row[col])
+				if !valid {
+					ok = false
+					break
+				}
+				converted[i], seen = value, true
+			}
+			if !ok || !seen {
+				continue
+			}
+			for i, row := range records[1:] {
+				if col < len(row) && converted[i] != "" {
+					row[col] = converted[i]
+				}
+			}
+			break
+		}
+	}
+	return records
+}
+
+// normalizeLiterals rewrites locale-formatted string literals in a condition to canonical numbers and dates
+func (l Locale) normalizeLiterals(expr sqlparser.Expr) {
+	sqlparser.Walk(expr, func(node sqlparser.Expr) {
+		lit, ok := node.(*sqlparser.Literal)
+		if !ok || lit.Kind != sqlparser.StringLiteral {
+			return
+		}
+		if n, ok := l.NormalizeNumber(lit.Value); ok {
+			lit.Kind, lit.Value = sqlparser.NumberLiteral, n
+		} else if d, ok := l.NormalizeDate(lit.Value); ok {
+			lit.Value = d
+		}
+	})
+}
+
+func (l Locale) isGroup(r rune) bool {
+	for _, g := range l.Group {
+		if r == g {
+			return true
+		}
+	}
+	return false
+}
+
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
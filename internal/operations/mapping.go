@@ -0,0 +1,108 @@
+package operations
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColumnMapping maps source column names to target column names
+type ColumnMapping map[string]string
+
+// ParseColumnMapping parses a mapping in format "src1:dst1,src2:dst2"; "src=dst" is also accepted
+func ParseColumnMapping(spec string) (ColumnMapping, error) {
+	mapping := make(ColumnMapping)
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		sep := strings.IndexAny(pair, ":=")
+		if sep < 0 {
+			return nil, fmt.Errorf("invalid column mapping: %s (expected src:dst)", strings.TrimSpace(pair))
+		}
+		src := strings.TrimSpace(pair[:sep])
+		dst := strings.TrimSpace(pair[sep+1:])
+		if src == "" || dst == "" {
+			return nil, fmt.Errorf("invalid column mapping: %s (expected src:dst)", strings.TrimSpace(pair))
+		}
+		mapping[src] = dst
+	}
+	return mapping, nil
+}
+
+// ResolveColumnMapping decides which target column each source column is written to.
+// Explicit mappings win, then identical names; remaining columns are prompted for when
+// interactive is set, and reported as an error otherwise.
+func (ops *CSVOperations) ResolveColumnMapping(srcHeaders []string, mapping ColumnMapping, interactive bool) (ColumnMapping, error) {
+	resolved := make(ColumnMapping)
+	var unmatched []string
+
+	for _, src := range srcHeaders {
+		if dst, ok := mapping[src]; ok {
+			if err := ops.ValidateColumns([]string{dst}); err != nil {
+				return nil, fmt.Errorf("invalid mapping for '%s': %v", src, err)
+			}
+			resolved[src] = dst
+			continue
+		}
+		if indexOf(ops.Headers, src) >= 0 {
+			resolved[src] = src
+			continue
+		}
+		unmatched = append(unmatched, src)
+	}
+
+	for src := range mapping {
+		if indexOf(srcHeaders, src) < 0 {
+			return nil, fmt.Errorf("mapped column '%s' does not exist in source file", src)
+		}
+	}
+
+	if len(unmatched) == 0 {
+		return resolved, nil
+	}
+	if !interactive {
+		return nil, fmt.Errorf("source columns not found in target: %s (use -map \"src:dst\" to map them)", strings.Join(unmatched, ", "))
+	}
+
+	if err := ops.promptColumnMapping(os.Stdin, unmatched, resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// promptColumnMapping asks the user where each unmatched source column should go
+func (ops *CSVOperations) promptColumnMapping(in io.Reader, unmatched []string, resolved ColumnMapping) error {
+	fmt.Println("Target columns:")
+	for i, header := range ops.Headers {
+		fmt.Printf("%d: %s\n", i+1, header)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for _, src := range unmatched {
+		for {
+			fmt.Printf("Map source column '%s' to (number or name, empty to skip): ", src)
+			if !scanner.Scan() {
+				return fmt.Errorf("column mapping cancelled")
+			}
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				break
+			}
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(ops.Headers) {
+				resolved[src] = ops.Headers[n-1]
+				break
+			}
+			if indexOf(ops.Headers, answer) >= 0 {
+				resolved[src] = answer
+				break
+			}
+			fmt.Printf("Unknown column: %s\n", answer)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColumnMeta holds the documentation attached to a column
+type ColumnMeta struct {
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Allowed     []string `json:"allowed,omitempty"` // Allowed lists the only values INSERT and UPDATE may write
+	Type        string   `json:"type,omitempty"`    // Type is the type declared by create: int, float, string, bool or date
+}
+
+// Metadata is the sidecar document stored next to a CSV file
+type Metadata struct {
+	Columns    map[string]ColumnMeta `json:"columns"`
+	Migrations []string              `json:"migrations,omitempty"` // Migrations lists the applied migration versions in order
+	Stats      *FileStats            `json:"stats,omitempty"`      // Stats is recorded by analyze for cost estimates
+}
+
+// SidecarPath returns the metadata file used for a CSV file, e.g. data.csv.meta.json
+func SidecarPath(csvPath string) string {
+	return csvPath + ".meta.json"
+}
+
+// LoadMetadata reads the sidecar for a CSV file; a missing sidecar yields empty metadata
+func LoadMetadata(csvPath string) (*Metadata, error) {
+	meta := &Metadata{Columns: make(map[string]ColumnMeta)}
+
+	data, err := os.ReadFile(SidecarPath(csvPath))
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %v", err)
+	}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata %s: %v", SidecarPath(csvPath), err)
+	}
+	if meta.Columns == nil {
+		meta.Columns = make(map[string]ColumnMeta)
+	}
+	return meta, nil
+}
+
+// SaveMetadata writes the sidecar for a CSV file
+func SaveMetadata(csvPath string, meta *Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %v", err)
+	}
+	if err := os.WriteFile(SidecarPath(csvPath), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %v", err)
+	}
+	return nil
+}
+
+// Annotate attaches a description and/or comma-separated tags to a column, and records the
+// allowed values of the columns listed in an -allowed spec (see ParseAllowedValues)
+func (ops *CSVOperations) Annotate(column, description, tags, allowed string) error {
+	domains, err := ParseAllowedValues(allowed)
+	if err != nil {
+		return err
+	}
+	if description == "" && tags == "" && len(domains) == 0 {
+		return fmt.Errorf("ANNOTATE requires -desc, -tags and/or -allowed")
+	}
+	if column == "" && (description != "" || tags != "") {
+		return fmt.Errorf("ANNOTATE requires -col with the column name")
+	}
+	if column != "" {
+		if err := ops.ValidateColumns([]string{column}); err != nil {
+			return err
+		}
+	}
+
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+	for name, values := range domains {
+		if err := ops.ValidateColumns([]string{name}); err != nil {
+			return err
+		}
+		entry := meta.Columns[ops.originalHeader(name)]
+		entry.Allowed = values
+		meta.Columns[ops.originalHeader(name)] = entry
+	}
+	if column == "" {
+		if err := SaveMetadata(ops.FilePath, meta); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded allowed values in %s\n", SidecarPath(ops.FilePath))
+		return nil
+	}
+
+	entry := meta.Columns[column]
+	if description != "" {
+		entry.Description = description
+	}
+	if tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && indexOf(entry.Tags, tag) < 0 {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+	}
+	meta.Columns[column] = entry
+
+	if err := SaveMetadata(ops.FilePath, meta); err != nil {
+		return err
+	}
+	fmt.Printf("Annotated column '%s' in %s\n", column, SidecarPath(ops.FilePath))
+	return nil
+}
+
+// Describe lists each column with its detected type and any annotations
+func (ops *CSVOperations) Describe() error {
+	if ops.IsEmpty() {
+		fmt.Println("No columns: the CSV file is empty.")
+		return nil
+	}
+
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+
+	types := ops.DataFrame.Types()
+	fmt.Printf("%-4s %-20s %-8s %-20s %s\n", "#", "column", "type", "tags", "description")
+	fmt.Println(strings.Repeat("-", 80))
+	for i, col := range ops.Headers {
+		// A declared type wins over the one read from the values, which a file without rows lacks
+		entry := meta.Columns[ops.originalHeader(col)]
+		colType := string(types[i])
+		if entry.Type != "" {
+			colType = entry.Type
+		}
+		fmt.Printf("%-4d %-20s %-8s %-20s %s\n", i+1, col, colType, strings.Join(entry.Tags, ","), entry.Description)
+	}
+	return nil
+}
+
+// columnAnnotation renders a column's description and tags for the -columns listing
+func columnAnnotation(meta *Metadata, column string) string {
+	entry, ok := meta.Columns[column]
+	if !ok {
+		return ""
+	}
+	text := ""
+	if entry.Description != "" {
+		text = " - " + entry.Description
+	}
+	if len(entry.Tags) > 0 {
+		text += " [" + strings.Join(entry.Tags, ", ") + "]"
+	}
+	if len(entry.Allowed) > 0 {
+		text += " {" + strings.Join(entry.Allowed, "|") + "}"
+	}
+	return text
+}
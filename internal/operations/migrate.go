@@ -0,0 +1,301 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// MigrationStep is one schema change of a migration file
+type MigrationStep struct {
+	Action  string         // Action is ADD, RENAME, BACKFILL or DROP
+	Column  string         // Column is the column the step changes
+	NewName string         // NewName is the new name given by RENAME
+	Default string         // Default is the value of every row in a column added by ADD
+	Expr    sqlparser.Expr // Expr computes the values written by BACKFILL
+	Where   sqlparser.Expr // Where limits BACKFILL to matching rows when set
+}
+
+// Migration is a migration file: its version (the file name without extension) and steps
+type Migration struct {
+	Version string
+	Steps   []MigrationStep
+}
+
+// LoadMigrations reads the *.sql files of dir, ordered by file name
+func LoadMigrations(dir string) ([]Migration, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %v", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no migration files (*.sql) found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	migrations := make([]Migration, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration: %v", err)
+		}
+		version := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		migration, err := ParseMigration(version, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", filepath.Base(path), err)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+// ParseMigration parses one step per line; blank lines and "--" comments are ignored:
+//
+//	ADD COLUMN name [DEFAULT value]
+//	RENAME COLUMN old TO new
+//	BACKFILL name = expression [WHERE condition]
+//	DROP COLUMN name
+func ParseMigration(version, text string) (Migration, error) {
+	migration := Migration{Version: version}
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		step, err := parseMigrationStep(line)
+		if err != nil {
+			return migration, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		migration.Steps = append(migration.Steps, step)
+	}
+	if len(migration.Steps) == 0 {
+		return migration, fmt.Errorf("no steps")
+	}
+	return migration, nil
+}
+
+// parseMigrationStep parses a single migration line
+func parseMigrationStep(line string) (MigrationStep, error) {
+	tokens, err := sqlparser.Tokenize(line)
+	if err != nil {
+		return MigrationStep{}, err
+	}
+	word := func(i int) string {
+		if i < len(tokens) && tokens[i].Type == sqlparser.TokenIdent {
+			return strings.ToUpper(tokens[i].Value)
+		}
+		return ""
+	}
+	name := func(i int) (string, bool) {
+		if i < len(tokens) && (tokens[i].Type == sqlparser.TokenIdent || tokens[i].Type == sqlparser.TokenQuotedIdent) {
+			return tokens[i].Value, true
+		}
+		return "", false
+	}
+	// rest returns the source text from token i on
+	rest := func(i int) string {
+		return strings.TrimSpace(string([]rune(line)[tokens[i].Pos:]))
+	}
+	end := len(tokens) - 1 // the EOF token
+
+	switch word(0) {
+	case "ADD":
+		column, ok := name(2)
+		if word(1) != "COLUMN" || !ok {
+			return MigrationStep{}, fmt.Errorf("expected ADD COLUMN name [DEFAULT value]")
+		}
+		step := MigrationStep{Action: "ADD", Column: column}
+		switch {
+		case end == 3:
+		case end > 4 && word(3) == "DEFAULT":
+			value, ok := defaultValue(rest(4))
+			if !ok {
+				return MigrationStep{}, fmt.Errorf("DEFAULT must be a single value")
+			}
+			step.Default = value
+		default:
+			return MigrationStep{}, fmt.Errorf("expected ADD COLUMN name [DEFAULT value]")
+		}
+		return step, nil
+
+	case "RENAME":
+		column, okOld := name(2)
+		newName, okNew := name(4)
+		if word(1) != "COLUMN" || word(3) != "TO" || !okOld || !okNew || end != 5 {
+			return MigrationStep{}, fmt.Errorf("expected RENAME COLUMN old TO new")
+		}
+		return MigrationStep{Action: "RENAME", Column: column, NewName: newName}, nil
+
+	case "DROP":
+		column, ok := name(2)
+		if word(1) != "COLUMN" || !ok || end != 3 {
+			return MigrationStep{}, fmt.Errorf("expected DROP COLUMN name")
+		}
+		return MigrationStep{Action: "DROP", Column: column}, nil
+
+	case "BACKFILL":
+		column, ok := name(1)
+		if !ok || end < 4 || tokens[2].Type != sqlparser.TokenSymbol || tokens[2].Value != "=" {
+			return MigrationStep{}, fmt.Errorf("expected BACKFILL name = expression [WHERE condition]")
+		}
+		exprText, whereText := rest(3), ""
+		for i := 3; i < end; i++ {
+			if word(i) == "WHERE" {
+				exprText = strings.TrimSpace(string([]rune(line)[tokens[3].Pos:tokens[i].Pos]))
+				whereText = rest(i + 1)
+				break
+			}
+		}
+		step := MigrationStep{Action: "BACKFILL", Column: column}
+		if step.Expr, err = sqlparser.ParseExpr(exprText); err != nil {
+			return MigrationStep{}, fmt.Errorf("invalid BACKFILL expression: %v", err)
+		}
+		if whereText != "" {
+			if step.Where, err = sqlparser.ParseExpr(whereText); err != nil {
+				return MigrationStep{}, fmt.Errorf("invalid BACKFILL condition: %v", err)
+			}
+		}
+		return step, nil
+	}
+	return MigrationStep{}, fmt.Errorf("unknown migration step: %s (use ADD COLUMN, RENAME COLUMN, BACKFILL or DROP COLUMN)", line)
+}
+
+// defaultValue reads the constant of an ADD COLUMN ... DEFAULT clause; NULL is an empty cell
+func defaultValue(text string) (string, bool) {
+	expr, err := sqlparser.ParseExpr(text)
+	if err != nil {
+		return "", false
+	}
+	switch value := expr.(type) {
+	case *sqlparser.Literal:
+		if value.Kind == sqlparser.NullLiteral {
+			return "", true
+		}
+		return value.Value, true
+	case *sqlparser.UnaryExpr:
+		if lit, ok := value.Expr.(*sqlparser.Literal); ok && value.Op == "-" && lit.Kind == sqlparser.NumberLiteral {
+			return "-" + lit.Value, true
+		}
+	}
+	return "", false
+}
+
+// Migrate applies the migrations of dir that the file's sidecar metadata does not list as applied,
+// in order, and records their versions. The file is only rewritten if every pending step succeeds.
+func (ops *CSVOperations) Migrate(dir string) error {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return err
+	}
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if indexOf(meta.Migrations, migration.Version) < 0 {
+			pending = append(pending, migration)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Printf("%s is up to date (%d migrations applied)\n", ops.FilePath, len(meta.Migrations))
+		return nil
+	}
+
+	records, dialect, err := ops.readRawRecords()
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(records); i++ {
+		for len(records[i]) < len(records[0]) {
+			records[i] = append(records[i], "")
+		}
+	}
+
+	for _, migration := range pending {
+		for _, step := range migration.Steps {
+			if records, err = ops.applyMigrationStep(records, meta, step); err != nil {
+				return fmt.Errorf("migration %s: %s %s: %v (no changes were written)", migration.Version, step.Action, step.Column, err)
+			}
+		}
+		meta.Migrations = append(meta.Migrations, migration.Version)
+	}
+
+	if err := ops.writeRecords(records, dialect); err != nil {
+		return err
+	}
+	if err := SaveMetadata(ops.FilePath, meta); err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		fmt.Printf("Applied %s (%d steps)\n", migration.Version, len(migration.Steps))
+	}
+	return nil
+}
+
+// applyMigrationStep applies one step to the records (header first), keeping column
+// annotations in meta in step with renames and drops
+func (ops *CSVOperations) applyMigrationStep(records [][]string, meta *Metadata, step MigrationStep) ([][]string, error) {
+	header := records[0]
+	idx := indexOf(header, step.Column)
+	if step.Action == "ADD" {
+		if idx >= 0 {
+			return records, fmt.Errorf("column already exists")
+		}
+	} else if idx < 0 {
+		return records, fmt.Errorf("column does not exist")
+	}
+
+	switch step.Action {
+	case "ADD":
+		records[0] = append(header, step.Column)
+		for i := 1; i < len(records); i++ {
+			records[i] = append(records[i], step.Default)
+		}
+
+	case "RENAME":
+		if indexOf(header, step.NewName) >= 0 {
+			return records, fmt.Errorf("column '%s' already exists", step.NewName)
+		}
+		header[idx] = step.NewName
+		if entry, ok := meta.Columns[step.Column]; ok {
+			delete(meta.Columns, step.Column)
+			meta.Columns[step.NewName] = entry
+		}
+
+	case "DROP":
+		for i := range records {
+			records[i] = append(records[i][:idx:idx], records[i][idx+1:]...)
+		}
+		delete(meta.Columns, step.Column)
+
+	case "BACKFILL":
+		// Only empty cells are filled, so re-running a backfill never overwrites data
+		evaluator := ops.evaluator(NewStringDataFrame(header, records[1:]))
+		for i := 1; i < len(records); i++ {
+			if records[i][idx] != "" {
+				continue
+			}
+			if step.Where != nil {
+				cond, err := evaluator.Eval(step.Where, i-1)
+				if err != nil {
+					return records, err
+				}
+				if cond == nil || !truthy(cond) {
+					continue
+				}
+			}
+			value, err := evaluator.Eval(step.Expr, i-1)
+			if err != nil {
+				return records, err
+			}
+			records[i][idx] = formatValue(value)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,175 @@
+package operations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// forceTypeNames maps the type names accepted by -force-type to column types
+var forceTypeNames = map[string]series.Type{
+	"int": series.Int, "integer": series.Int,
+	"float": series.Float, "number": series.Float,
+	"string": series.String, "text": series.String,
+	"bool": series.Bool, "boolean": series.Bool,
+}
+
+// mixedReportLimit is how many offending values and rows a -detect-mixed line shows
+const mixedReportLimit = 5
+
+// ParseForceTypes parses a -force-type list such as "amount:float,zip:string"
+func ParseForceTypes(spec string) (map[string]series.Type, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	types := make(map[string]series.Type)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		sep := strings.LastIndex(part, ":")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid -force-type entry: %s (expected column:type)", part)
+		}
+		column, name := strings.TrimSpace(part[:sep]), strings.ToLower(strings.TrimSpace(part[sep+1:]))
+		colType, ok := forceTypeNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid -force-type type: %s (use int, float, string or bool)", name)
+		}
+		types[column] = colType
+	}
+	return types, nil
+}
+
+// loadOptions returns the dataframe options for loading the input file, checking that every
+// -force-type column exists
+func (ops *CSVOperations) loadOptions(headers []string) ([]dataframe.LoadOption, error) {
+	if len(ops.ForceTypes) == 0 {
+		return nil, nil
+	}
+	for column := range ops.ForceTypes {
+		if indexOf(headers, column) < 0 {
+			return nil, fmt.Errorf("-force-type column '%s' does not exist in CSV", column)
+		}
+	}
+	return []dataframe.LoadOption{dataframe.WithTypes(ops.ForceTypes)}, nil
+}
+
+// MixedColumn is a column whose values mostly share a type that the rest do not fit, so the
+// whole column was read as text
+type MixedColumn struct {
+	Column   string
+	Expected series.Type // Expected is the type most of its values have
+	Rows     []int       // Rows are the data rows (from 1) whose values do not fit Expected
+	Values   []string    // Values are the distinct offending values, in order of appearance
+}
+
+// valueType returns the narrowest type text fits: int, float, bool or string
+func valueType(text string) series.Type {
+	if _, err := strconv.Atoi(text); err == nil {
+		return series.Int
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return series.Float
+	}
+	if _, err := strconv.ParseBool(text); err == nil {
+		return series.Bool
+	}
+	return series.String
+}
+
+// MixedColumns finds text columns in which more than half of the non-empty values are numbers
+// or booleans. Such columns were read as text because of a few values like "N/A", so
+// aggregates over them quietly skip or misread data.
+func (ops *CSVOperations) MixedColumns() []MixedColumn {
+	var mixed []MixedColumn
+	for _, name := range ops.Headers {
+		col := ops.DataFrame.Col(name)
+		if col.Type() != series.String {
+			continue
+		}
+
+		kinds := make([]series.Type, col.Len())
+		counts := make(map[series.Type]int)
+		filled := 0
+		for i := 0; i < col.Len(); i++ {
+			elem := col.Elem(i)
+			if elem.IsNA() || strings.TrimSpace(elem.String()) == "" {
+				continue
+			}
+			kinds[i] = valueType(strings.TrimSpace(elem.String()))
+			counts[kinds[i]]++
+			filled++
+		}
+
+		// Whole numbers also fit a float column
+		expected, fits := series.Bool, counts[series.Bool]
+		if numbers := counts[series.Int] + counts[series.Float]; numbers > fits {
+			expected, fits = series.Float, numbers
+			if counts[series.Float] == 0 {
+				expected = series.Int
+			}
+		}
+		if fits*2 <= filled || fits == filled {
+			continue
+		}
+
+		entry := MixedColumn{Column: name, Expected: expected}
+		seen := make(map[string]bool)
+		for i, kind := range kinds {
+			if kind == "" || kind == expected || (expected == series.Float && kind == series.Int) {
+				continue
+			}
+			entry.Rows = append(entry.Rows, i+1)
+			if value := col.Elem(i).String(); !seen[value] {
+				seen[value] = true
+				entry.Values = append(entry.Values, value)
+			}
+		}
+		mixed = append(mixed, entry)
+	}
+	return mixed
+}
+
+// DetectMixed reports the columns found by MixedColumns with the values and rows that do not
+// fit, and suggests the -force-type list that reads those values as NULL instead
+func (ops *CSVOperations) DetectMixed() error {
+	mixed := ops.MixedColumns()
+	if len(mixed) == 0 {
+		if !ops.RawOutput {
+			fmt.Println("No mixed-type columns found.")
+		}
+		return nil
+	}
+
+	var rows [][]string
+	var overrides []string
+	for _, entry := range mixed {
+		values := make([]string, 0, mixedReportLimit)
+		for _, value := range entry.Values[:min(len(entry.Values), mixedReportLimit)] {
+			values = append(values, strconv.Quote(value))
+		}
+		if len(entry.Values) > mixedReportLimit {
+			values = append(values, "...")
+		}
+		rowNumbers := make([]string, 0, mixedReportLimit)
+		for _, row := range entry.Rows[:min(len(entry.Rows), mixedReportLimit)] {
+			rowNumbers = append(rowNumbers, strconv.Itoa(row))
+		}
+		if len(entry.Rows) > mixedReportLimit {
+			rowNumbers = append(rowNumbers, "...")
+		}
+		rows = append(rows, []string{entry.Column, string(entry.Expected), strconv.Itoa(len(entry.Rows)),
+			strings.Join(values, ", "), strings.Join(rowNumbers, ", ")})
+		overrides = append(overrides, entry.Column+":"+string(entry.Expected))
+	}
+
+	if err := ops.PrintDataFrame(NewStringDataFrame([]string{"column", "expected", "bad_values", "values", "rows"}, rows)); err != nil {
+		return err
+	}
+	if !ops.RawOutput {
+		fmt.Printf("\nThese columns were read as text. Read the other values as NULL with -force-type \"%s\"\n", strings.Join(overrides, ","))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// MutationSummary is the outcome of a statement that rewrote the input file, as printed by
+// -json-summary
+type MutationSummary struct {
+	Operation string  `json:"operation"`
+	File      string  `json:"file"`
+	Matched   int     `json:"matched"`     // Matched counts the rows the statement selected (source rows for INSERT)
+	Modified  int     `json:"modified"`    // Modified counts the rows written: inserted, changed or deleted
+	Duration  float64 `json:"duration_ms"` // Duration is set by the caller, which times the whole statement
+	Backup    string  `json:"backup"`      // Backup is the copy made by -backup, empty without one
+}
+
+// reportMutation records the outcome of a rewrite for -json-summary, printing message instead
+// when no summary was asked for. Rows printed by -returning keep stdout, so the message goes
+// to stderr then.
+func (ops *CSVOperations) reportMutation(operation string, matched, modified int, message string) {
+	ops.Mutation = &MutationSummary{Operation: operation, File: ops.FilePath, Matched: matched, Modified: modified}
+	switch {
+	case ops.JSONSummary:
+	case ops.Returning && ops.OutputFile == "":
+		fmt.Fprintln(os.Stderr, message)
+	default:
+		fmt.Println(message)
+	}
+}
+
+// returnRows prints the rows a statement removed or, for UPDATE, their new values, when
+// -returning is given. They go to -output like query results.
+func (ops *CSVOperations) returnRows(df dataframe.DataFrame) error {
+	if !ops.Returning {
+		return nil
+	}
+	return ops.PrintDataFrame(df)
+}
+
+// BackupFile copies the input file to <file>.bak, replacing an older backup, and returns the
+// copy's path. A file that does not exist yet, as with INSERT and -header, has no backup.
+func (ops *CSVOperations) BackupFile() (string, error) {
+	data, err := os.ReadFile(ops.FilePath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to back up %s: %v", ops.FilePath, err)
+	}
+	info, err := os.Stat(ops.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to back up %s: %v", ops.FilePath, err)
+	}
+
+	path := ops.FilePath + ".bak"
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %v", ops.FilePath, err)
+	}
+	return path, nil
+}
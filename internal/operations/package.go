@@ -0,0 +1,234 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-gota/gota/series"
+)
+
+// DataPackage is a Frictionless Data Package descriptor, written as datapackage.json
+type DataPackage struct {
+	Profile   string            `json:"profile"`
+	Name      string            `json:"name"`
+	Licenses  []PackageLicense  `json:"licenses,omitempty"`
+	Created   string            `json:"created"`
+	Resources []PackageResource `json:"resources"`
+}
+
+// PackageLicense names a license by its SPDX identifier, e.g. CC-BY-4.0
+type PackageLicense struct {
+	Name string `json:"name"`
+}
+
+// PackageResource describes one data file of the package
+type PackageResource struct {
+	Name      string         `json:"name"`
+	Path      string         `json:"path"`
+	Profile   string         `json:"profile"`
+	Format    string         `json:"format"`
+	Mediatype string         `json:"mediatype"`
+	Encoding  string         `json:"encoding"`
+	Bytes     int            `json:"bytes"`
+	Hash      string         `json:"hash"`
+	Dialect   PackageDialect `json:"dialect"`
+	Schema    TableSchema    `json:"schema"`
+	Stats     ResourceStats  `json:"stats"`
+}
+
+// PackageDialect is the CSV dialect of a resource
+type PackageDialect struct {
+	Delimiter string `json:"delimiter"`
+	QuoteChar string `json:"quoteChar"`
+	Header    bool   `json:"header"`
+}
+
+// TableSchema lists the fields of a tabular resource; empty cells are missing values
+type TableSchema struct {
+	Fields        []SchemaField `json:"fields"`
+	MissingValues []string      `json:"missingValues"`
+}
+
+// SchemaField describes one column. Description and Tags come from the column annotations.
+type SchemaField struct {
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Stats       FieldStats `json:"stats"`
+}
+
+// FieldStats summarizes a column; Min, Max and Mean are only set for numeric columns
+type FieldStats struct {
+	Missing  int      `json:"missing"`
+	Distinct int      `json:"distinct"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Mean     *float64 `json:"mean,omitempty"`
+}
+
+// ResourceStats counts the data rows and columns of a resource
+type ResourceStats struct {
+	Rows   int `json:"rows"`
+	Fields int `json:"fields"`
+}
+
+// packageEncodings maps detected encodings to the names used by Frictionless tools
+var packageEncodings = map[string]string{
+	"utf-8":        "utf-8",
+	"utf-8-bom":    "utf-8-sig",
+	"utf-16le":     "utf-16le",
+	"utf-16be":     "utf-16be",
+	"latin1":       "iso-8859-1",
+	"windows-1252": "windows-1252",
+}
+
+// Package writes the loaded file to dir/data/ unchanged, next to a datapackage.json that
+// describes it: dialect, schema (field types, annotations and per-column stats), hash and license
+func (ops *CSVOperations) Package(dir, license string) error {
+	data, err := os.ReadFile(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	meta, err := LoadMetadata(ops.FilePath)
+	if err != nil {
+		return err
+	}
+
+	base := filepath.Base(ops.FilePath)
+	name := packageName(strings.TrimSuffix(base, filepath.Ext(base)))
+	sum := sha256.Sum256(data)
+	encoding, ok := packageEncodings[ops.Dialect.Encoding]
+	if !ok {
+		encoding = "utf-8"
+	}
+
+	resource := PackageResource{
+		Name:      name,
+		Path:      "data/" + base,
+		Profile:   "tabular-data-resource",
+		Format:    "csv",
+		Mediatype: "text/csv",
+		Encoding:  encoding,
+		Bytes:     len(data),
+		Hash:      "sha256:" + hex.EncodeToString(sum[:]),
+		Dialect: PackageDialect{
+			Delimiter: string(ops.Dialect.Delimiter),
+			QuoteChar: string(ops.Dialect.Quote),
+			Header:    ops.Dialect.HasHeader,
+		},
+		Schema: TableSchema{MissingValues: []string{""}},
+		Stats:  ResourceStats{Rows: ops.DataFrame.Nrow(), Fields: len(ops.Headers)},
+	}
+	for _, header := range ops.Headers {
+		field := ops.schemaField(ops.DataFrame.Col(header))
+		field.Description = meta.Columns[header].Description
+		field.Tags = meta.Columns[header].Tags
+		resource.Schema.Fields = append(resource.Schema.Fields, field)
+	}
+
+	pkg := DataPackage{
+		Profile:   "tabular-data-package",
+		Name:      name,
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Resources: []PackageResource{resource},
+	}
+	if license != "" {
+		pkg.Licenses = []PackageLicense{{Name: license}}
+	}
+	descriptor, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode datapackage.json: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		return fmt.Errorf("failed to create package directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data", base), data, 0644); err != nil {
+		return fmt.Errorf("failed to write data file: %v", err)
+	}
+	descriptorPath := filepath.Join(dir, "datapackage.json")
+	if err := os.WriteFile(descriptorPath, append(descriptor, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write datapackage.json: %v", err)
+	}
+
+	fmt.Printf("Packaged %s as %s (%d rows, %d fields)\n", ops.FilePath, descriptorPath, resource.Stats.Rows, resource.Stats.Fields)
+	if license == "" {
+		fmt.Println("No license recorded; set one with -license (e.g. CC-BY-4.0)")
+	}
+	return nil
+}
+
+// schemaField infers the Table Schema type of a column and summarizes its values.
+// Text columns whose values all parse as dates are typed date.
+func (ops *CSVOperations) schemaField(col series.Series) SchemaField {
+	field := SchemaField{Name: col.Name, Type: "string"}
+	switch col.Type() {
+	case series.Int:
+		field.Type = "integer"
+	case series.Float:
+		field.Type = "number"
+	case series.Bool:
+		field.Type = "boolean"
+	}
+
+	evaluator := ops.evaluator(ops.DataFrame)
+	distinct := make(map[string]bool)
+	dates := 0
+	for i := 0; i < col.Len(); i++ {
+		elem := col.Elem(i)
+		if elem.IsNA() || elem.String() == "" {
+			field.Stats.Missing++
+			continue
+		}
+		distinct[elem.String()] = true
+		if _, ok := evaluator.parseDate(elem.String()); ok {
+			dates++
+		}
+	}
+	field.Stats.Distinct = len(distinct)
+	if field.Type == "string" && dates > 0 && dates == col.Len()-field.Stats.Missing {
+		field.Type = "date"
+	}
+
+	if field.Type == "integer" || field.Type == "number" {
+		if values := numericValues(col); len(values) > 0 {
+			low, high, sum := values[0], values[0], 0.0
+			for _, v := range values {
+				if v < low {
+					low = v
+				}
+				if v > high {
+					high = v
+				}
+				sum += v
+			}
+			mean := sum / float64(len(values))
+			field.Stats.Min, field.Stats.Max, field.Stats.Mean = &low, &high, &mean
+		}
+	}
+	return field
+}
+
+// packageName turns a file name into a valid package name: lowercase letters, digits, '-', '_' and '.'
+func packageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, name)
+	if name == "" {
+		return "dataset"
+	}
+	return name
+}
@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"strings"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// SelectColumns returns the names that -select, -where, -group, -having and -order may use,
+// so the other columns of a wide file need not be loaded, or nil when the query needs every
+// column or cannot be analyzed. Names that are not columns are harmless.
+func SelectColumns(selectCols, whereCond, groupBy, having, orderBy string) []string {
+	var names []string
+	for _, part := range splitTopLevel(selectCols) {
+		part = strings.TrimSpace(part)
+		if len(part) > 9 && strings.EqualFold(part[:9], "DISTINCT ") {
+			part = strings.TrimSpace(part[9:])
+		}
+		if part == "" || part == "*" {
+			return nil
+		}
+		// A column name may contain spaces, so the part itself is kept as well
+		names = append(names, part)
+		if stmt, err := ParseSelectQuery("SELECT " + part); err == nil {
+			refs, ok := statementColumns(stmt)
+			if !ok {
+				return nil
+			}
+			names = append(names, refs...)
+		}
+	}
+
+	for _, cond := range []string{whereCond, having} {
+		if cond == "" {
+			continue
+		}
+		expr, err := sqlparser.ParseExpr(cond)
+		if err != nil {
+			return nil
+		}
+		refs, ok := exprColumns(expr)
+		if !ok {
+			return nil
+		}
+		names = append(names, refs...)
+	}
+
+	for _, key := range strings.Split(groupBy+","+orderBy, ",") {
+		key = strings.TrimSpace(key)
+		lower := strings.ToLower(key)
+		if strings.HasSuffix(lower, " desc") || strings.HasSuffix(lower, " asc") {
+			key = strings.TrimSpace(key[:strings.LastIndex(key, " ")])
+		}
+		if key != "" {
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// QueryColumns returns the columns a -query SELECT uses, like SelectColumns, or nil when it
+// needs them all: SELECT *, joins and subqueries, which may read the loaded table again
+func QueryColumns(query string) []string {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil
+	}
+	sel, ok := stmt.(*sqlparser.SelectStatement)
+	if !ok || len(sel.Joins) > 0 {
+		return nil
+	}
+	names, ok := statementColumns(sel)
+	if !ok {
+		return nil
+	}
+	return names
+}
+
+// statementColumns collects the column references of stmt, reporting false when it selects *
+// or holds a subquery
+func statementColumns(stmt *sqlparser.SelectStatement) ([]string, bool) {
+	exprs := []sqlparser.Expr{stmt.Where, stmt.Having, stmt.Qualify}
+	for _, item := range stmt.Columns {
+		if _, ok := item.Expr.(*sqlparser.StarExpr); ok {
+			return nil, false
+		}
+		exprs = append(exprs, item.Expr)
+	}
+	exprs = append(exprs, stmt.GroupBy...)
+	for _, item := range stmt.OrderBy {
+		exprs = append(exprs, item.Expr)
+	}
+
+	var names []string
+	for _, expr := range exprs {
+		refs, ok := exprColumns(expr)
+		if !ok {
+			return nil, false
+		}
+		names = append(names, refs...)
+	}
+	return names, true
+}
+
+// exprColumns collects the column references of expr, reporting false when it holds a subquery
+func exprColumns(expr sqlparser.Expr) ([]string, bool) {
+	var names []string
+	ok := true
+	sqlparser.Walk(expr, func(e sqlparser.Expr) {
+		switch e := e.(type) {
+		case *sqlparser.ColumnRef:
+			names = append(names, e.Name)
+		case *sqlparser.InExpr:
+			if e.Subquery != nil {
+				ok = false
+			}
+		}
+	})
+	return names, ok
+}
+
+// projectRecords drops the columns of records, header first, that are not in Project, nor
+// renamed or given a type by -rename and -force-type. Kept values are copied, so the rows they
+// were read from can be freed.
+func (ops *CSVOperations) projectRecords(records [][]string) [][]string {
+	if len(ops.Project) == 0 || len(records) == 0 {
+		return records
+	}
+	var keep []int
+	for j, name := range records[0] {
+		_, typed := ops.ForceTypes[name]
+		renamed := false
+		for _, newName := range ops.Renames {
+			renamed = renamed || newName == name
+		}
+		if indexOf(ops.Project, name) >= 0 || typed || renamed {
+			keep = append(keep, j)
+		}
+	}
+	// A query naming no column at all, such as COUNT(*), still needs the rows
+	if len(keep) == 0 {
+		keep = []int{0}
+	}
+	if len(keep) == len(records[0]) {
+		return records
+	}
+
+	projected := make([][]string, len(records))
+	for i, record := range records {
+		row := make([]string, len(keep))
+		for k, j := range keep {
+			if j < len(record) {
+				row[k] = strings.Clone(record[j])
+			}
+		}
+		projected[i] = row
+		records[i] = nil
+	}
+	return projected
+}
@@ -0,0 +1,343 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// ParseSelectQuery parses a -query string and checks that it is a SELECT statement
+func ParseSelectQuery(query string) (*sqlparser.SelectStatement, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	selectStmt, ok := stmt.(*sqlparser.SelectStatement)
+	if !ok {
+		return nil, fmt.Errorf("only SELECT statements are supported")
+	}
+	return selectStmt, nil
+}
+
+// ParseQuery parses a -query string, which is a SELECT statement or SELECTs combined with
+// UNION, and returns it along with its first SELECT, whose FROM names the input file
+func ParseQuery(query string) (sqlparser.Statement, *sqlparser.SelectStatement, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch s := stmt.(type) {
+	case *sqlparser.SelectStatement:
+		return s, s, nil
+	case *sqlparser.CompoundStatement:
+		return s, s.Selects[0], nil
+	}
+	return nil, nil, fmt.Errorf("only SELECT statements are supported")
+}
+
+// Query executes a complete SQL SELECT statement against the loaded CSV
+func (ops *CSVOperations) Query(query string) error {
+	parsed, stmt, err := ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("query error: %v", err)
+	}
+	if compound, ok := parsed.(*sqlparser.CompoundStatement); ok {
+		return ops.QueryCompound(compound)
+	}
+
+	// A join stands in for the loaded table during this query only
+	if len(stmt.Joins) > 0 {
+		df, headers := ops.DataFrame, ops.Headers
+		defer func() { ops.DataFrame, ops.Headers = df, headers }()
+		if err := ops.joinTables(stmt); err != nil {
+			return fmt.Errorf("query error: %v", err)
+		}
+	}
+
+	// The WHERE clause is rendered back to SQL and evaluated like a -where condition
+	whereCond := ""
+	if stmt.Where != nil {
+		whereCond = stmt.Where.String()
+	}
+
+	orderBy, err := orderByClause(stmt.OrderBy)
+	if err != nil {
+		return fmt.Errorf("query error: %v", err)
+	}
+
+	// GROUP BY keys must be plain columns
+	var groupCols []string
+	for _, expr := range stmt.GroupBy {
+		col, ok := expr.(*sqlparser.ColumnRef)
+		if !ok {
+			return fmt.Errorf("query error: GROUP BY supports column names only")
+		}
+		groupCols = append(groupCols, col.Name)
+	}
+
+	// Aggregate queries go through the aggregation pipeline
+	var aggFuncs []AggregateFunction
+	for _, item := range stmt.Columns {
+		call, ok := item.Expr.(*sqlparser.FuncCall)
+		if !ok || call.Over != nil || indexOf(aggregateFunctions, call.Name) < 0 {
+			continue
+		}
+		// Conditional aggregates keep their value and condition arguments as written
+		if strings.HasSuffix(call.Name, "_IF") {
+			if call.Name == "COUNT_IF" && len(call.Args) != 1 {
+				return fmt.Errorf("query error: COUNT_IF expects a condition")
+			}
+			if call.Name != "COUNT_IF" && len(call.Args) != 2 {
+				return fmt.Errorf("query error: %s expects a value and a condition", call.Name)
+			}
+			funcs, ok := ops.ParseAggregations(call.String())
+			if !ok {
+				return fmt.Errorf("query error: unsupported function: %s", call.Name)
+			}
+			if item.Alias != "" {
+				funcs[0].Alias = item.Alias
+			}
+			aggFuncs = append(aggFuncs, funcs...)
+			continue
+		}
+
+		// PERCENTILE takes the percentile as a second, literal argument
+		param := ""
+		if call.Name == "PERCENTILE" {
+			if len(call.Args) != 2 {
+				return fmt.Errorf("query error: PERCENTILE expects a column and a percentile")
+			}
+			lit, ok := call.Args[1].(*sqlparser.Literal)
+			if !ok || lit.Kind != sqlparser.NumberLiteral {
+				return fmt.Errorf("query error: PERCENTILE percentile must be a number")
+			}
+			param = ", " + lit.Value
+		} else if len(call.Args) != 1 {
+			return fmt.Errorf("query error: %s expects exactly one argument", call.Name)
+		}
+		column := ""
+		switch arg := call.Args[0].(type) {
+		case *sqlparser.StarExpr:
+			if call.Name != "COUNT" {
+				return fmt.Errorf("query error: %s(*) is not supported", call.Name)
+			}
+			column = "*"
+		case *sqlparser.ColumnRef:
+			column = arg.Name
+		default:
+			// Expressions such as CAST(amount AS FLOAT) are evaluated into a column first
+			column = arg.String()
+		}
+		funcs, ok := ops.ParseAggregations(fmt.Sprintf("%s(%s%s)", call.Name, column, param))
+		if !ok {
+			return fmt.Errorf("query error: unsupported function: %s", call.Name)
+		}
+		if item.Alias != "" {
+			funcs[0].Alias = item.Alias
+		}
+		aggFuncs = append(aggFuncs, funcs...)
+	}
+	if stmt.Qualify != nil && (len(groupCols) > 0 || len(aggFuncs) > 0) {
+		return fmt.Errorf("query error: QUALIFY is not supported with aggregate functions or GROUP BY")
+	}
+	if len(groupCols) > 0 {
+		// Every item must be a group column or an aggregate; results follow the select list
+		// when it names group columns, and otherwise show the group columns first
+		var outputCols []string
+		plain, next := false, 0
+		for _, item := range stmt.Columns {
+			switch expr := item.Expr.(type) {
+			case *sqlparser.ColumnRef:
+				if indexOf(groupCols, expr.Name) < 0 {
+					return fmt.Errorf("query error: column '%s' must appear in GROUP BY or be used in an aggregate function (GROUP BY %s)", expr.Name, strings.Join(groupCols, ", "))
+				}
+				outputCols = append(outputCols, expr.Name)
+				plain = true
+				continue
+			case *sqlparser.FuncCall:
+				if expr.Over == nil && indexOf(aggregateFunctions, expr.Name) >= 0 {
+					outputCols = append(outputCols, aggFuncs[next].Alias)
+					next++
+					continue
+				}
+			}
+			return fmt.Errorf("query error: %s is neither a GROUP BY column nor an aggregate function (GROUP BY %s)", item.Expr.String(), strings.Join(groupCols, ", "))
+		}
+		if !plain {
+			outputCols = nil
+		}
+		having := ""
+		if stmt.Having != nil {
+			having = stmt.Having.String()
+		}
+		return ops.HandleGroupedAggregation(aggFuncs, whereCond, groupCols, having, orderBy, stmt.Limit, outputCols)
+	}
+	if stmt.Having != nil {
+		return fmt.Errorf("query error: HAVING requires GROUP BY")
+	}
+	if len(aggFuncs) > 0 {
+		if len(aggFuncs) != len(stmt.Columns) {
+			return fmt.Errorf("query error: cannot mix aggregate functions and plain columns")
+		}
+		return ops.HandleAggregation(aggFuncs, whereCond)
+	}
+
+	return ops.SelectItems(stmt.Columns, whereCond, stmt.Qualify, orderBy, stmt.Distinct, stmt.Limit)
+}
+
+// orderByClause converts ORDER BY items to the "col1 [desc], col2 ..." form of -order
+func orderByClause(items []sqlparser.OrderItem) (string, error) {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		column := ""
+		switch expr := item.Expr.(type) {
+		case *sqlparser.ColumnRef:
+			column = expr.Name
+		case *sqlparser.FuncCall:
+			// Aggregates such as COUNT(*) sort grouped results by their result column
+			if indexOf(aggregateFunctions, expr.Name) < 0 || expr.Over != nil {
+				return "", fmt.Errorf("ORDER BY supports column names and aggregate functions only")
+			}
+			column = expr.String()
+		default:
+			return "", fmt.Errorf("ORDER BY supports column names and aggregate functions only")
+		}
+		if item.Desc {
+			column += " desc"
+		}
+		keys[i] = column
+	}
+	return strings.Join(keys, ", "), nil
+}
+
+// SelectItems runs a non-aggregate select list. Plain columns are selected as is; other
+// expressions such as CASE or arithmetic become derived columns. A QUALIFY condition (nil for
+// none) then drops rows using the window functions computed over all rows left by WHERE.
+func (ops *CSVOperations) SelectItems(items []sqlparser.SelectItem, whereCond string, qualify sqlparser.Expr, orderBy string, distinct bool, limit int) error {
+	var columns []string
+	for _, item := range items {
+		switch expr := item.Expr.(type) {
+		case *sqlparser.StarExpr:
+			columns = append(columns, ops.Headers...)
+		case *sqlparser.ColumnRef:
+			columns = append(columns, expr.Name)
+		}
+	}
+	if err := ops.ValidateColumns(columns); err != nil {
+		return err
+	}
+
+	filteredDF, err := ops.ApplyWhereCondition(ops.DataFrame, whereCond)
+	if err != nil {
+		return fmt.Errorf("WHERE condition error: %v", err)
+	}
+
+	// ORDER BY on source columns sorts before projection; one naming the alias of a derived
+	// column sorts after it
+	sortAfter := false
+	if keys, err := orderByKeys(orderBy); err == nil {
+		for _, key := range keys {
+			sortAfter = sortAfter || indexOf(ops.Headers, key.Column) < 0
+		}
+	}
+	if !sortAfter {
+		filteredDF, err = ops.ApplyOrderBy(filteredDF, orderBy)
+		if err != nil {
+			return fmt.Errorf("ORDER BY error: %v", err)
+		}
+	}
+
+	resultDF, err := ops.ProjectSelectItems(filteredDF, items)
+	if err != nil {
+		return fmt.Errorf("query error: %v", err)
+	}
+	if qualify != nil {
+		if resultDF, err = ops.applyQualify(filteredDF, resultDF, qualify, items); err != nil {
+			return err
+		}
+	}
+	if sortAfter {
+		resultDF, err = ops.ApplyOrderBy(resultDF, orderBy)
+		if err != nil {
+			return fmt.Errorf("ORDER BY error: %v", err)
+		}
+	}
+	if distinct {
+		resultDF = ops.ApplyDistinct(resultDF)
+	}
+	resultDF = ops.ApplyLimit(resultDF, limit)
+
+	if err := ops.PrintDataFrame(resultDF); err != nil {
+		return err
+	}
+	if !ops.RawOutput {
+		fmt.Printf("\n(%d rows)\n", resultDF.Nrow())
+	}
+	return nil
+}
+
+// ParseSelectExpressions parses a -select list containing expressions such as
+// "price * quantity AS total". Entries naming an existing column are kept as columns even when
+// they look like expressions (e.g. asset-type). It reports false for plain column lists.
+func (ops *CSVOperations) ParseSelectExpressions(selectCols string) ([]sqlparser.SelectItem, bool, error) {
+	var items []sqlparser.SelectItem
+	computed := false
+	for _, part := range splitTopLevel(selectCols) {
+		part = strings.TrimSpace(part)
+		if indexOf(ops.Headers, part) >= 0 {
+			items = append(items, sqlparser.SelectItem{Expr: &sqlparser.ColumnRef{Name: part}})
+			continue
+		}
+
+		stmt, err := ParseSelectQuery("SELECT " + part)
+		if err != nil {
+			return nil, false, fmt.Errorf("SELECT expression error: %v", err)
+		}
+		if len(stmt.Columns) != 1 || stmt.Distinct {
+			return nil, false, fmt.Errorf("SELECT expression error: unsupported expression: %s", part)
+		}
+		item := stmt.Columns[0]
+		if _, ok := item.Expr.(*sqlparser.ColumnRef); !ok || item.Alias != "" {
+			computed = true
+		}
+		items = append(items, item)
+	}
+	return items, computed, nil
+}
+
+// ProjectSelectItems builds the result columns of a select list. Columns are copied under
+// their output names and any other expression is evaluated per row into a derived column;
+// window functions such as ROW_NUMBER() OVER (...) are evaluated over all rows of df.
+func (ops *CSVOperations) ProjectSelectItems(df dataframe.DataFrame, items []sqlparser.SelectItem) (dataframe.DataFrame, error) {
+	// Window functions see every row of df, so they are computed before any row is projected
+	evaluator := ops.evaluator(df)
+	for _, item := range items {
+		if err := evaluator.computeWindows(item.Expr); err != nil {
+			return df, err
+		}
+	}
+
+	var columns []series.Series
+	for _, item := range items {
+		switch expr := item.Expr.(type) {
+		case *sqlparser.StarExpr:
+			for _, name := range df.Names() {
+				columns = append(columns, df.Col(name))
+			}
+		case *sqlparser.ColumnRef:
+			col := df.Col(expr.Name)
+			col.Name = item.Name()
+			columns = append(columns, col)
+		default:
+			col, err := EvalColumn(evaluator, expr, item.Name())
+			if err != nil {
+				return df, err
+			}
+			columns = append(columns, col)
+		}
+	}
+	return dataframe.New(columns...), nil
+}
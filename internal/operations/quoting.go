@@ -0,0 +1,58 @@
+package operations
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Quoting styles accepted by -quote for results written as CSV
+const (
+	QuoteMinimal = "minimal" // QuoteMinimal quotes values holding a delimiter, quote or line break (the default)
+	QuoteAlways  = "always"  // QuoteAlways quotes every value, header included
+	QuoteNever   = "never"   // QuoteNever writes values as they are, even when that breaks the row apart
+)
+
+// ParseQuoteStyle checks a -quote style
+func ParseQuoteStyle(value string) (string, error) {
+	style := strings.ToLower(strings.TrimSpace(value))
+	switch style {
+	case "":
+		return QuoteMinimal, nil
+	case QuoteMinimal, QuoteAlways, QuoteNever:
+		return style, nil
+	}
+	return "", fmt.Errorf("invalid -quote style: %s (use %s, %s or %s)", value, QuoteMinimal, QuoteAlways, QuoteNever)
+}
+
+// writeResults writes result records to w separated by -out-delimiter, quoted as -quote says
+// and ending in CRLF with -crlf. Minimal quoting is that of encoding/csv; quotes inside quoted values are doubled.
+func (ops *CSVOperations) writeResults(w io.Writer, records [][]string) error {
+	comma := ops.outDelimiter()
+	if ops.QuoteStyle != QuoteAlways && ops.QuoteStyle != QuoteNever {
+		writer := csv.NewWriter(w)
+		writer.Comma = comma
+		writer.UseCRLF = ops.CRLF
+		return writer.WriteAll(records)
+	}
+
+	out := bufio.NewWriter(w)
+	for _, record := range records {
+		for j, field := range record {
+			if j > 0 {
+				out.WriteRune(comma)
+			}
+			if ops.QuoteStyle == QuoteAlways {
+				field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+			}
+			out.WriteString(field)
+		}
+		if ops.CRLF {
+			out.WriteByte('\r')
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
@@ -0,0 +1,274 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// decodeBytes converts raw file bytes in the given encoding to a UTF-8 string
+func decodeBytes(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case "", "utf-8":
+		return string(data), nil
+	case "utf-8-bom":
+		return string(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})), nil
+	case "utf-16le", "utf-16be":
+		data = bytes.TrimPrefix(data, []byte{0xFF, 0xFE})
+		data = bytes.TrimPrefix(data, []byte{0xFE, 0xFF})
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if encoding == "utf-16le" {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		return string(utf16.Decode(units)), nil
+	case "latin1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	case "windows-1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+			if b >= 0x80 && b < 0xA0 && windows1252[b-0x80] != 0 {
+				runes[i] = windows1252[b-0x80]
+			}
+		}
+		return string(runes), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// windows1252 holds the characters that Windows-1252 puts at 0x80-0x9F, where latin1 has
+// control codes; the five unassigned bytes are 0 and read as in latin1
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// encodingNames maps the names -encoding accepts to the encodings of Dialect
+var encodingNames = map[string]string{
+	"utf-8": "utf-8", "utf8": "utf-8",
+	"utf-8-bom": "utf-8-bom", "utf-8-sig": "utf-8-bom", "utf8-bom": "utf-8-bom",
+	"utf-16": "utf-16", "utf16": "utf-16",
+	"utf-16le": "utf-16le", "utf16le": "utf-16le",
+	"utf-16be": "utf-16be", "utf16be": "utf-16be",
+	"latin1": "latin1", "latin-1": "latin1", "iso-8859-1": "latin1", "iso8859-1": "latin1",
+	"windows-1252": "windows-1252", "cp1252": "windows-1252",
+}
+
+// ParseEncoding normalizes an -encoding name such as UTF8, utf16 or cp1252. "utf-16" leaves
+// the byte order to the byte order mark.
+func ParseEncoding(name string) (string, error) {
+	encoding, ok := encodingNames[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")]
+	if !ok {
+		return "", fmt.Errorf("unsupported encoding: %s (use utf-8, utf-8-bom, utf-16, utf-16le, utf-16be, latin1 or windows-1252)", name)
+	}
+	return encoding, nil
+}
+
+// encodeBytes converts UTF-8 text to the given encoding, the reverse of decodeBytes. Byte order
+// marks are written back; text that latin1 cannot represent is an error rather than garbled.
+func encodeBytes(text, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", "utf-8":
+		return []byte(text), nil
+	case "utf-8-bom":
+		return append([]byte{0xEF, 0xBB, 0xBF}, text...), nil
+	case "utf-16le", "utf-16be":
+		units := utf16.Encode([]rune(text))
+		data := make([]byte, 0, 2*len(units)+2)
+		if encoding == "utf-16le" {
+			data = append(data, 0xFF, 0xFE)
+			for _, unit := range units {
+				data = append(data, byte(unit), byte(unit>>8))
+			}
+		} else {
+			data = append(data, 0xFE, 0xFF)
+			for _, unit := range units {
+				data = append(data, byte(unit>>8), byte(unit))
+			}
+		}
+		return data, nil
+	case "latin1":
+		data := make([]byte, 0, len(text))
+		for _, r := range text {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%q cannot be written to a latin1 file", r)
+			}
+			data = append(data, byte(r))
+		}
+		return data, nil
+	case "windows-1252":
+		data := make([]byte, 0, len(text))
+		for _, r := range text {
+			b, ok := windows1252Byte(r)
+			if !ok {
+				return nil, fmt.Errorf("%q cannot be written to a windows-1252 file", r)
+			}
+			data = append(data, b)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// windows1252Byte finds the Windows-1252 byte of r
+func windows1252Byte(r rune) (byte, bool) {
+	for i, c := range windows1252 {
+		if c == r && c != 0 {
+			return byte(0x80 + i), true
+		}
+	}
+	if r <= 0xFF && (r < 0x80 || r >= 0xA0 || windows1252[r-0x80] == 0) {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+// ParseQuoteChar reads a -quote-char or -escape-char value: a single character, or none
+func ParseQuoteChar(value string) (rune, error) {
+	if strings.EqualFold(value, "none") {
+		return 0, nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '\n' || runes[0] == '\r' {
+		return 0, fmt.Errorf("invalid quote or escape character: %q (expected a single character or none)", value)
+	}
+	return runes[0], nil
+}
+
+// parseRecords splits delimited text into records using the delimiter, quote and escape
+// characters of dialect. With a non-zero limit it stops after limit+1 records, so callers can
+// tell that the text holds more than limit without parsing the rest.
+func parseRecords(text string, dialect Dialect, limit int) ([][]string, error) {
+	delim, quote, escape := dialect.Delimiter, dialect.Quote, dialect.Escape
+	if quote == '"' && escape == 0 {
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.Comma = delim
+		reader.LazyQuotes = dialect.LazyQuotes
+		// Rows with the wrong number of fields are left to -on-bad-rows
+		reader.FieldsPerRecord = -1
+		var records [][]string
+		for limit == 0 || len(records) <= limit {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+
+	// encoding/csv only understands doubled double quotes, so handle other quoting here
+	var records [][]string
+	var record []string
+	var field strings.Builder
+	inQuotes := false
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == escape && escape != 0 && i+1 < len(runes):
+			field.WriteRune(runes[i+1])
+			i++
+		case inQuotes && r == quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				// Doubled quote is an escaped quote
+				field.WriteRune(quote)
+				i++
+			} else {
+				inQuotes = false
+			}
+		case inQuotes:
+			field.WriteRune(r)
+		case r == quote && quote != 0 && field.Len() == 0:
+			inQuotes = true
+		case r == delim:
+			record = append(record, field.String())
+			field.Reset()
+		case r == '\r' && i+1 < len(runes) && runes[i+1] == '\n':
+			// Handled by the following newline
+		case r == '\n':
+			record = append(record, field.String())
+			field.Reset()
+			// Skip blank lines the same way encoding/csv does
+			if len(record) > 1 || record[0] != "" {
+				records = append(records, record)
+				if limit > 0 && len(records) > limit {
+					return records, nil
+				}
+			}
+			record = nil
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if inQuotes && !dialect.LazyQuotes {
+		return nil, fmt.Errorf("unterminated quoted field")
+	}
+	if field.Len() > 0 || len(record) > 0 {
+		record = append(record, field.String())
+		if len(record) > 1 || record[0] != "" {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// skipsLines reports whether -skip-rows or -comment drop lines of the input
+func (ops *CSVOperations) skipsLines() bool {
+	return ops.SkipRows > 0 || ops.Comment != ""
+}
+
+// skipLines drops the first SkipRows lines of decoded text, then the lines starting with the
+// Comment prefix. Lines are physical lines, so a quoted value spanning lines counts as several.
+func (ops *CSVOperations) skipLines(text string) string {
+	if !ops.skipsLines() {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	var kept strings.Builder
+	for i, line := range lines {
+		if i < ops.SkipRows || (ops.Comment != "" && strings.HasPrefix(line, ops.Comment)) {
+			continue
+		}
+		kept.WriteString(line)
+	}
+	return kept.String()
+}
+
+// syntheticNames generates column names c1, c2, ... for files without a header row
+func syntheticNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("c%d", i+1)
+	}
+	return names
+}
+
+// headerlessNames names the n columns of a file without a header row: the -names given, then
+// synthetic names for any columns beyond them
+func (ops *CSVOperations) headerlessNames(n int) ([]string, error) {
+	if len(ops.Names) > n {
+		return nil, fmt.Errorf("-names lists %d columns but the file has %d", len(ops.Names), n)
+	}
+	names := syntheticNames(n)
+	copy(names, ops.Names)
+	return names, nil
+}
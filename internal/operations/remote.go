@@ -0,0 +1,69 @@
+package operations
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// RemoteOptions bound the download of an input given as an HTTP(S) URL
+type RemoteOptions struct {
+	Timeout  time.Duration // Timeout limits the whole download, no limit when 0
+	MaxBytes int64         // MaxBytes fails downloads larger than this, no limit when 0
+	Headers  []string      // Headers are sent with the request, as "Name: value"
+}
+
+// IsURL reports whether an input names an HTTP(S) URL rather than a file
+func IsURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// FetchURL streams the body of url into a temporary file, which is then read like a local
+// file (compressed bodies included). The caller removes the file when done.
+func FetchURL(url string, options RemoteOptions) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %v", url, err)
+	}
+	for _, header := range options.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return "", fmt.Errorf("invalid header: %q (expected \"Name: value\")", header)
+		}
+		request.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	client := &http.Client{Timeout: options.Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %v", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", url, response.Status)
+	}
+	return spoolDownload(url, response.Body, response.ContentLength, options.MaxBytes)
+}
+
+// spoolDownload copies a download of size bytes (-1 when unknown) to a temporary file, failing
+// once it passes maxBytes (no limit when 0)
+func spoolDownload(name string, body io.Reader, size, maxBytes int64) (string, error) {
+	if maxBytes > 0 && size > maxBytes {
+		return "", fmt.Errorf("%s is %d bytes, over the -max-download limit of %d", name, size, maxBytes)
+	}
+	if maxBytes > 0 {
+		body = io.LimitReader(body, maxBytes+1)
+	}
+	path, err := SpoolInput(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %v", name, err)
+	}
+	if info, err := os.Stat(path); err == nil && maxBytes > 0 && info.Size() > maxBytes {
+		os.Remove(path)
+		return "", fmt.Errorf("%s is over the -max-download limit of %d bytes", name, maxBytes)
+	}
+	return path, nil
+}
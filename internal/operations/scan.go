@@ -0,0 +1,169 @@
+package operations
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nmapColumns are the columns of Nmap and Masscan XML read as a table, one row per host and
+// port (hosts without ports get one row with an empty port)
+var nmapColumns = []string{
+	"scanner", "time", "ip", "hostname", "host_status", "port", "protocol", "state", "reason",
+	"service", "product", "version", "extrainfo", "tunnel", "banner",
+}
+
+// nucleiColumns are the columns of Nuclei output read as a table, one row per finding
+var nucleiColumns = []string{
+	"timestamp", "template_id", "name", "severity", "type", "host", "port", "ip", "matched_at",
+	"matcher", "extracted", "tags",
+}
+
+// nmapRun is Nmap XML output; Masscan writes the same layout with scanner="masscan"
+type nmapRun struct {
+	Scanner string     `xml:"scanner,attr"`
+	Start   string     `xml:"start,attr"`
+	Hosts   []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	StartTime string `xml:"starttime,attr"`
+	EndTime   string `xml:"endtime,attr"`
+	Status    struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports []nmapPort `xml:"ports>port"`
+}
+
+type nmapPort struct {
+	Protocol string `xml:"protocol,attr"`
+	PortID   string `xml:"portid,attr"`
+	State    struct {
+		State  string `xml:"state,attr"`
+		Reason string `xml:"reason,attr"`
+	} `xml:"state"`
+	Service struct {
+		Name      string `xml:"name,attr"`
+		Product   string `xml:"product,attr"`
+		Version   string `xml:"version,attr"`
+		ExtraInfo string `xml:"extrainfo,attr"`
+		Tunnel    string `xml:"tunnel,attr"`
+		Banner    string `xml:"banner,attr"`
+	} `xml:"service"`
+}
+
+// nucleiResult is one Nuclei finding; only the fields seesv reads are declared
+type nucleiResult struct {
+	TemplateID string `json:"template-id"`
+	Info       struct {
+		Name     string          `json:"name"`
+		Severity string          `json:"severity"`
+		Tags     json.RawMessage `json:"tags"`
+	} `json:"info"`
+	Type             string          `json:"type"`
+	Host             string          `json:"host"`
+	Port             json.RawMessage `json:"port"`
+	IP               string          `json:"ip"`
+	MatchedAt        string          `json:"matched-at"`
+	MatcherName      string          `json:"matcher-name"`
+	ExtractedResults []string        `json:"extracted-results"`
+	Timestamp        string          `json:"timestamp"`
+}
+
+// nmapRecords reads Nmap or Masscan XML as records, header first
+func nmapRecords(text string) ([][]string, error) {
+	var run nmapRun
+	if err := xml.Unmarshal([]byte(text), &run); err != nil {
+		return nil, fmt.Errorf("failed to read Nmap XML: %v", err)
+	}
+
+	records := [][]string{nmapColumns}
+	for _, host := range run.Hosts {
+		ip := ""
+		for _, address := range host.Addresses {
+			if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+				ip = address.Addr
+				break
+			}
+		}
+		hostname := ""
+		if len(host.Hostnames) > 0 {
+			hostname = host.Hostnames[0].Name
+		}
+		scanned := unixTime(host.EndTime, host.StartTime, run.Start)
+		prefix := []string{run.Scanner, scanned, ip, hostname, host.Status.State}
+
+		if len(host.Ports) == 0 {
+			records = append(records, append(prefix, make([]string, len(nmapColumns)-len(prefix))...))
+			continue
+		}
+		for _, port := range host.Ports {
+			service := port.Service
+			row := append(append([]string{}, prefix...),
+				port.PortID, port.Protocol, port.State.State, port.State.Reason,
+				service.Name, service.Product, service.Version, service.ExtraInfo, service.Tunnel, service.Banner)
+			records = append(records, row)
+		}
+	}
+	return records, nil
+}
+
+// unixTime formats the first non-empty Unix timestamp as RFC 3339 in UTC
+func unixTime(candidates ...string) string {
+	for _, candidate := range candidates {
+		if seconds, err := strconv.ParseInt(candidate, 10, 64); err == nil && seconds > 0 {
+			return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// nucleiRecords reads Nuclei JSON Lines, or a JSON array of findings, as records, header first
+func nucleiRecords(text string) ([][]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	if strings.HasPrefix(strings.TrimSpace(text), "[") {
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read Nuclei JSON: %v", err)
+		}
+	}
+
+	records := [][]string{nucleiColumns}
+	for decoder.More() {
+		var result nucleiResult
+		if err := decoder.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to read Nuclei JSON (finding %d): %v", len(records), err)
+		}
+		records = append(records, []string{
+			result.Timestamp, result.TemplateID, result.Info.Name, result.Info.Severity, result.Type,
+			result.Host, jsonText(result.Port), result.IP, result.MatchedAt, result.MatcherName,
+			strings.Join(result.ExtractedResults, "; "), jsonText(result.Info.Tags),
+		})
+	}
+	return records, nil
+}
+
+// jsonText renders a JSON string, number or list of strings (joined with commas) as text
+func jsonText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return strings.Join(list, ",")
+	}
+	if raw == nil || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
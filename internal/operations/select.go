@@ -2,31 +2,76 @@ package operations
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/go-gota/gota/dataframe"
 	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
 )
 
 // AggregateFunction represents supported aggregate functions
 type AggregateFunction struct {
-	Function string // COUNT, SUM, AVG, MIN, MAX
+	Function string // COUNT, COUNT_IF, SUM, AVG, MIN, MAX, STDDEV, VARIANCE, MEDIAN, PERCENTILE
 	Column   string
 	Alias    string
+	Arg      float64 // Arg is the percentile (0-100) for PERCENTILE
 }
 
-// Select performs SELECT operations with optional WHERE, ORDER BY, LIMIT
-func (ops *CSVOperations) Select(selectCols, whereCond, orderBy string, limit int) error {
+// aggregateAlias matches an aggregate followed by AS and the name of its result column
+var aggregateAlias = regexp.MustCompile(`(?i)^(.*\))\s+AS\s+([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// aggregateFunctions lists the supported aggregate function names
+var aggregateFunctions = []string{"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "STDDEV_POP", "VARIANCE", "VAR", "VAR_POP", "MEDIAN", "PERCENTILE",
+	"COUNT_IF", "SUM_IF", "AVG_IF", "MIN_IF", "MAX_IF"}
+
+// Select performs SELECT operations with optional WHERE, GROUP BY, HAVING, ORDER BY, LIMIT
+func (ops *CSVOperations) Select(selectCols, whereCond, groupBy, having, orderBy string, limit int) error {
 	df := ops.DataFrame
 
+	// An empty file yields an empty result rather than a column error
+	if ops.IsEmpty() {
+		if err := ops.PrintDataFrame(df); err != nil {
+			return err
+		}
+		if !ops.RawOutput {
+			fmt.Printf("\n(%d rows)\n", 0)
+		}
+		return nil
+	}
+
 	// Check if this is an aggregation query
 	aggFuncs, isAggregation := ops.ParseAggregations(selectCols)
+
+	// GROUP BY produces one row of aggregates per group
+	if groupBy != "" {
+		groupCols := ops.ParseColumns(groupBy)
+		outputCols, err := ops.groupedSelectColumns(selectCols, groupCols)
+		if err != nil {
+			return err
+		}
+		return ops.HandleGroupedAggregation(aggFuncs, whereCond, groupCols, having, orderBy, limit, outputCols)
+	}
+	if having != "" {
+		return fmt.Errorf("HAVING requires GROUP BY")
+	}
 	
 	if isAggregation {
 		return ops.HandleAggregation(aggFuncs, whereCond)
 	}
 
+	// Expressions such as price * quantity AS total become derived columns
+	if selectCols != "" && !strings.Contains(strings.ToUpper(selectCols), "DISTINCT") {
+		items, computed, err := ops.ParseSelectExpressions(selectCols)
+		if err != nil {
+			return err
+		}
+		if computed {
+			return ops.SelectItems(items, whereCond, nil, orderBy, false, limit)
+		}
+	}
+
 	// Parse columns to select
 	columns := ops.ParseColumns(selectCols)
 	
@@ -61,7 +106,9 @@ func (ops *CSVOperations) Select(selectCols, whereCond, orderBy string, limit in
 	limitedDF := ops.ApplyLimit(orderedDF, limit)
 
 	// Print results
-	ops.PrintDataFrame(limitedDF)
+	if err := ops.PrintDataFrame(limitedDF); err != nil {
+		return err
+	}
 	
 	if !ops.RawOutput {
 		fmt.Printf("\n(%d rows)\n", limitedDF.Nrow())
@@ -76,15 +123,21 @@ func (ops *CSVOperations) ParseAggregations(selectCols string) ([]AggregateFunct
 	}
 
 	var aggFuncs []AggregateFunction
-	cols := strings.Split(selectCols, ",")
+	cols := splitTopLevel(selectCols)
 	hasAggregation := false
 
 	for _, col := range cols {
 		col = strings.TrimSpace(col)
+
+		// SUM(amount) AS total names the result column total
+		name := ""
+		if m := aggregateAlias.FindStringSubmatch(col); m != nil {
+			col, name = m[1], m[2]
+		}
 		
 		// Check for aggregation functions
 		upperCol := strings.ToUpper(col)
-		for _, funcName := range []string{"COUNT", "SUM", "AVG", "MIN", "MAX"} {
+		for _, funcName := range aggregateFunctions {
 			if strings.HasPrefix(upperCol, funcName+"(") && strings.HasSuffix(upperCol, ")") {
 				hasAggregation = true
 				
@@ -92,6 +145,48 @@ func (ops *CSVOperations) ParseAggregations(selectCols string) ([]AggregateFunct
 				start := strings.Index(upperCol, "(") + 1
 				end := strings.LastIndex(upperCol, ")")
 				columnName := strings.TrimSpace(col[start:end])
+				alias := fmt.Sprintf("%s(%s)", funcName, columnName)
+				if name != "" {
+					alias = name
+				}
+
+				// VAR is shorthand for VARIANCE
+				if funcName == "VAR" {
+					funcName = "VARIANCE"
+				}
+
+				// COUNT_IF(cond) and SUM_IF(value, cond) aggregate a derived column that is
+				// NULL (0 for COUNT_IF) on the rows not matching cond
+				if strings.HasSuffix(funcName, "_IF") {
+					column, ok := conditionalColumn(funcName, columnName)
+					if !ok {
+						return nil, false
+					}
+					function := strings.TrimSuffix(funcName, "_IF")
+					if funcName == "COUNT_IF" {
+						function = funcName
+					}
+					aggFuncs = append(aggFuncs, AggregateFunction{Function: function, Column: column, Alias: alias})
+					break
+				}
+
+				// PERCENTILE(col, p) takes the percentile as a second argument
+				arg := 0.0
+				if funcName == "PERCENTILE" {
+					idx := strings.LastIndex(columnName, ",")
+					if idx < 0 {
+						return nil, false
+					}
+					p, err := strconv.ParseFloat(strings.TrimSpace(columnName[idx+1:]), 64)
+					if err != nil || p < 0 || p > 100 {
+						return nil, false
+					}
+					columnName = strings.TrimSpace(columnName[:idx])
+					arg = p
+					if name == "" {
+						alias = fmt.Sprintf("%s(%s, %s)", funcName, columnName, strconv.FormatFloat(p, 'f', -1, 64))
+					}
+				}
 				
 				// Handle COUNT(*) special case
 				if funcName == "COUNT" && columnName == "*" {
@@ -101,7 +196,8 @@ func (ops *CSVOperations) ParseAggregations(selectCols string) ([]AggregateFunct
 				aggFuncs = append(aggFuncs, AggregateFunction{
 					Function: funcName,
 					Column:   columnName,
-					Alias:    fmt.Sprintf("%s(%s)", funcName, columnName),
+					Alias:    alias,
+					Arg:      arg,
 				})
 				break
 			}
@@ -111,6 +207,65 @@ func (ops *CSVOperations) ParseAggregations(selectCols string) ([]AggregateFunct
 	return aggFuncs, hasAggregation
 }
 
+// groupedSelectColumns checks a grouped select list, in which every item must be a group
+// column or an aggregate, and returns the result columns in select-list order. It returns nil
+// when the list names no group column, so the group columns are shown first.
+func (ops *CSVOperations) groupedSelectColumns(selectCols string, groupCols []string) ([]string, error) {
+	var columns []string
+	plain := false
+	for _, part := range splitTopLevel(selectCols) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if indexOf(ops.Headers, part) >= 0 {
+			if indexOf(groupCols, part) < 0 {
+				return nil, fmt.Errorf("column '%s' must appear in GROUP BY or be used in an aggregate function (GROUP BY %s)", part, strings.Join(groupCols, ", "))
+			}
+			columns = append(columns, part)
+			plain = true
+			continue
+		}
+		funcs, ok := ops.ParseAggregations(part)
+		if !ok {
+			return nil, fmt.Errorf("'%s' is neither a GROUP BY column nor an aggregate function (GROUP BY %s)", part, strings.Join(groupCols, ", "))
+		}
+		columns = append(columns, funcs[0].Alias)
+	}
+	if !plain {
+		return nil, nil
+	}
+	return columns, nil
+}
+
+// conditionalColumn builds the derived column expression of a conditional aggregate from its
+// arguments: CASE WHEN cond THEN value END, or CASE WHEN cond THEN 1 ELSE 0 END for COUNT_IF
+func conditionalColumn(funcName, args string) (string, bool) {
+	parts := splitTopLevel(args)
+	want := 2
+	if funcName == "COUNT_IF" {
+		want = 1
+	}
+	if len(parts) != want {
+		return "", false
+	}
+	exprs := make([]sqlparser.Expr, len(parts))
+	for i, part := range parts {
+		expr, err := sqlparser.ParseExpr(strings.TrimSpace(part))
+		if err != nil {
+			return "", false
+		}
+		exprs[i] = expr
+	}
+
+	caseExpr := &sqlparser.CaseExpr{Whens: []sqlparser.WhenClause{{Cond: exprs[len(exprs)-1], Result: exprs[0]}}}
+	if funcName == "COUNT_IF" {
+		caseExpr.Whens[0].Result = &sqlparser.Literal{Kind: sqlparser.NumberLiteral, Value: "1"}
+		caseExpr.Else = &sqlparser.Literal{Kind: sqlparser.NumberLiteral, Value: "0"}
+	}
+	return caseExpr.String(), true
+}
+
 // HandleAggregation processes aggregation functions
 func (ops *CSVOperations) HandleAggregation(aggFuncs []AggregateFunction, whereCond string) error {
 	df := ops.DataFrame
@@ -121,57 +276,117 @@ func (ops *CSVOperations) HandleAggregation(aggFuncs []AggregateFunction, whereC
 		return fmt.Errorf("WHERE condition error: %v", err)
 	}
 
-	// Calculate aggregations
-	results := make(map[string]interface{})
-	
+	filteredDF, err = ops.withDerivedColumns(filteredDF, aggFuncs)
+	if err != nil {
+		return fmt.Errorf("aggregation error: %v", err)
+	}
+
+	// The results form a one-row table with a column per aggregate, in select-list order
+	header := make([]string, 0, len(aggFuncs))
+	row := make([]string, 0, len(aggFuncs))
 	for _, aggFunc := range aggFuncs {
-		if err := ops.ValidateColumns([]string{aggFunc.Column}); err != nil {
-			return err
+		if indexOf(filteredDF.Names(), aggFunc.Column) < 0 {
+			return fmt.Errorf("column '%s' does not exist in CSV", aggFunc.Column)
 		}
 
 		result, err := ops.CalculateAggregation(filteredDF, aggFunc)
 		if err != nil {
 			return fmt.Errorf("aggregation error: %v", err)
 		}
-		
-		results[aggFunc.Alias] = result
+		header = append(header, aggFunc.Alias)
+		row = append(row, aggregateCell(result))
 	}
 
-	// Print aggregation results
-	ops.PrintAggregationResults(results)
-	return nil
+	resultDF, err := RecordsToDataFrame([][]string{header, row})
+	if err != nil {
+		return fmt.Errorf("aggregation error: %v", err)
+	}
+	ops.aggregates = header
+	return ops.PrintDataFrame(resultDF)
+}
+
+// withDerivedColumns adds a column for each aggregate whose argument is an expression, such as
+// SUM(CAST(amount AS FLOAT)), named after the expression so the aggregate can read it
+func (ops *CSVOperations) withDerivedColumns(df dataframe.DataFrame, aggFuncs []AggregateFunction) (dataframe.DataFrame, error) {
+	for _, aggFunc := range aggFuncs {
+		if indexOf(df.Names(), aggFunc.Column) >= 0 {
+			continue
+		}
+		expr, err := sqlparser.ParseExpr(aggFunc.Column)
+		if err != nil {
+			continue
+		}
+		if _, ok := expr.(*sqlparser.ColumnRef); ok {
+			continue
+		}
+		col, err := EvalColumn(ops.evaluator(df), expr, aggFunc.Column)
+		if err != nil {
+			return df, err
+		}
+		df = df.Mutate(col)
+	}
+	return df, nil
 }
 
 // CalculateAggregation performs the actual aggregation calculation
 func (ops *CSVOperations) CalculateAggregation(df dataframe.DataFrame, aggFunc AggregateFunction) (interface{}, error) {
 	col := df.Col(aggFunc.Column)
+
+	// Aggregates over no rows are NULL (COUNT is 0), whatever the column type
+	if df.Nrow() == 0 {
+		if aggFunc.Function == "COUNT" || aggFunc.Function == "COUNT_IF" {
+			return 0, nil
+		}
+		return nil, nil
+	}
 	
 	switch aggFunc.Function {
 	case "COUNT":
 		return df.Nrow(), nil
+
+	case "COUNT_IF":
+		count := 0
+		for _, value := range numericValues(col) {
+			if value != 0 {
+				count++
+			}
+		}
+		return count, nil
 		
 	case "SUM":
 		if col.Type() != series.Float && col.Type() != series.Int {
+			if allMissing(col) {
+				return nil, nil
+			}
 			return nil, fmt.Errorf("SUM requires numeric column, got %s", col.Type())
 		}
 		sum := 0.0
+		count := 0
 		for i := 0; i < col.Len(); i++ {
-			if val := col.Elem(i); val != nil {
+			if val := col.Elem(i); val != nil && !val.IsNA() {
 				if fVal, err := strconv.ParseFloat(fmt.Sprintf("%v", val), 64); err == nil {
 					sum += fVal
+					count++
 				}
 			}
 		}
+		// Only NULLs, e.g. a group where a SUM_IF condition never held, sum to NULL
+		if count == 0 {
+			return nil, nil
+		}
 		return sum, nil
 		
 	case "AVG":
 		if col.Type() != series.Float && col.Type() != series.Int {
+			if allMissing(col) {
+				return nil, nil
+			}
 			return nil, fmt.Errorf("AVG requires numeric column, got %s", col.Type())
 		}
 		sum := 0.0
 		count := 0
 		for i := 0; i < col.Len(); i++ {
-			if val := col.Elem(i); val != nil {
+			if val := col.Elem(i); val != nil && !val.IsNA() {
 				if fVal, err := strconv.ParseFloat(fmt.Sprintf("%v", val), 64); err == nil {
 					sum += fVal
 					count++
@@ -179,17 +394,19 @@ func (ops *CSVOperations) CalculateAggregation(df dataframe.DataFrame, aggFunc A
 			}
 		}
 		if count == 0 {
-			return 0.0, nil
+			return nil, nil
 		}
 		return sum / float64(count), nil
 		
 	case "MIN":
-		if col.Len() == 0 {
-			return nil, nil
-		}
-		min := col.Elem(0)
-		for i := 1; i < col.Len(); i++ {
-			if val := col.Elem(i); val != nil {
+		// NULLs (e.g. values that did not fit a -force-type) are skipped
+		var min series.Element
+		for i := 0; i < col.Len(); i++ {
+			if val := col.Elem(i); val != nil && !val.IsNA() {
+				if min == nil {
+					min = val
+					continue
+				}
 				if col.Type() == series.Float || col.Type() == series.Int {
 					if fVal, err := strconv.ParseFloat(fmt.Sprintf("%v", val), 64); err == nil {
 						if fMin, err := strconv.ParseFloat(fmt.Sprintf("%v", min), 64); err == nil {
@@ -205,15 +422,20 @@ func (ops *CSVOperations) CalculateAggregation(df dataframe.DataFrame, aggFunc A
 				}
 			}
 		}
+		if min == nil {
+			return nil, nil
+		}
 		return min, nil
 		
 	case "MAX":
-		if col.Len() == 0 {
-			return nil, nil
-		}
-		max := col.Elem(0)
-		for i := 1; i < col.Len(); i++ {
-			if val := col.Elem(i); val != nil {
+		// NULLs (e.g. values that did not fit a -force-type) are skipped
+		var max series.Element
+		for i := 0; i < col.Len(); i++ {
+			if val := col.Elem(i); val != nil && !val.IsNA() {
+				if max == nil {
+					max = val
+					continue
+				}
 				if col.Type() == series.Float || col.Type() == series.Int {
 					if fVal, err := strconv.ParseFloat(fmt.Sprintf("%v", val), 64); err == nil {
 						if fMax, err := strconv.ParseFloat(fmt.Sprintf("%v", max), 64); err == nil {
@@ -229,63 +451,22 @@ func (ops *CSVOperations) CalculateAggregation(df dataframe.DataFrame, aggFunc A
 				}
 			}
 		}
+		if max == nil {
+			return nil, nil
+		}
 		return max, nil
 		
+	case "STDDEV", "STDDEV_POP", "VARIANCE", "VAR_POP", "MEDIAN", "PERCENTILE":
+		if col.Type() != series.Float && col.Type() != series.Int {
+			return nil, fmt.Errorf("%s requires numeric column, got %s", aggFunc.Function, col.Type())
+		}
+		return calculateStatistic(aggFunc, numericValues(col)), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported aggregation function: %s", aggFunc.Function)
 	}
 }
 
-// PrintAggregationResults prints aggregation results in a formatted way
-func (ops *CSVOperations) PrintAggregationResults(results map[string]interface{}) {
-	if ops.RawOutput {
-		// Print raw values separated by commas
-		first := true
-		for _, value := range results {
-			if !first {
-				fmt.Print(",")
-			}
-			first = false
-			if value == nil {
-				fmt.Print("NULL")
-			} else {
-				switch v := value.(type) {
-				case float64:
-					if v == float64(int64(v)) {
-						fmt.Printf("%.0f", v)
-					} else {
-						fmt.Printf("%.2f", v)
-					}
-				default:
-					fmt.Printf("%v", value)
-				}
-			}
-		}
-		fmt.Println()
-	} else {
-		fmt.Println("Aggregation Results:")
-		fmt.Println(strings.Repeat("-", 30))
-		
-		for alias, value := range results {
-			if value == nil {
-				fmt.Printf("%-20s: NULL\n", alias)
-			} else {
-				// Format numeric values nicely
-				switch v := value.(type) {
-				case float64:
-					if v == float64(int64(v)) {
-						fmt.Printf("%-20s: %.0f\n", alias, v)
-					} else {
-						fmt.Printf("%-20s: %.2f\n", alias, v)
-					}
-				default:
-					fmt.Printf("%-20s: %v\n", alias, value)
-				}
-			}
-		}
-	}
-}
-
 // ApplyDistinct removes duplicate rows (basic implementation)
 func (ops *CSVOperations) ApplyDistinct(df dataframe.DataFrame) dataframe.DataFrame {
 	// This is a simplified DISTINCT implementation
@@ -300,7 +481,7 @@ func (ops *CSVOperations) ApplyDistinct(df dataframe.DataFrame) dataframe.DataFr
 			if j > 0 {
 				rowKey.WriteString("|")
 			}
-			rowKey.WriteString(fmt.Sprintf("%v", df.Elem(i, j)))
+			rowKey.WriteString(CellText(df.Elem(i, j)))
 		}
 		
 		key := rowKey.String()
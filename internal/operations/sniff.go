@@ -0,0 +1,250 @@
+package operations
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SniffSampleSize is the number of bytes inspected when detecting the dialect
+const SniffSampleSize = 64 * 1024
+
+// candidateDelimiters lists the delimiters the sniffer will consider, in order of preference
+var candidateDelimiters = []rune{',', '\t', ';', '|', ':'}
+
+// Dialect describes how a delimited text file is laid out
+type Dialect struct {
+	Delimiter  rune
+	Quote      rune // Quote wraps values holding delimiters or line breaks; 0 when values are never quoted
+	Escape     rune // Escape makes the next character literal, as in \" ; 0 when quotes are doubled instead
+	LazyQuotes bool // LazyQuotes accepts quotes inside unquoted values and unterminated quoted values
+	HasHeader  bool
+	Encoding   string // utf-8, utf-8-bom, utf-16le, utf-16be, latin1, windows-1252
+	Source     string // Source is the format of a capture or scan read as a table (see detectSource), empty for CSV
+	CRLF       bool   // CRLF ends lines with \r\n rather than \n
+}
+
+// DefaultDialect returns the plain comma separated, UTF-8, headered dialect
+func DefaultDialect() Dialect {
+	return Dialect{
+		Delimiter: ',',
+		Quote:     '"',
+		HasHeader: true,
+		Encoding:  "utf-8",
+	}
+}
+
+// Sniff inspects a sample of the file and guesses its dialect (like Python's csv.Sniffer)
+func Sniff(sample []byte) Dialect {
+	dialect := DefaultDialect()
+	if len(sample) == 0 {
+		return dialect
+	}
+
+	// Detect encoding first so the remaining checks work on decoded text
+	dialect.Encoding = detectEncoding(sample)
+	text, err := decodeBytes(sample, dialect.Encoding)
+	if err != nil {
+		return dialect
+	}
+	if dialect.Source = detectSource(text); dialect.Source != "" {
+		return dialect
+	}
+
+	lines := sampleLines(text, len(sample) >= SniffSampleSize)
+	if len(lines) == 0 {
+		return dialect
+	}
+
+	dialect.Quote = sniffQuote(lines)
+	dialect.Delimiter = sniffDelimiter(lines, dialect.Quote)
+	dialect.HasHeader = sniffHeader(text, dialect)
+	return dialect
+}
+
+// detectEncoding looks at the byte order mark and UTF-8 validity of the sample
+func detectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8-bom"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+
+	// The sample may end in the middle of a multi-byte rune
+	valid := sample
+	for i := 0; i < utf8.UTFMax && len(valid) > 0 && !utf8.Valid(valid); i++ {
+		valid = valid[:len(valid)-1]
+	}
+	if utf8.Valid(valid) {
+		return "utf-8"
+	}
+	return "latin1"
+}
+
+// sampleLines splits the sample into lines, dropping a trailing partial line when the sample was truncated
+func sampleLines(text string, truncated bool) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	if truncated && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+
+	var result []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// sniffQuote picks the quote character that most often wraps whole fields
+func sniffQuote(lines []string) rune {
+	best := '"'
+	bestCount := 0
+	for _, quote := range []rune{'"', '\''} {
+		q := regexp.QuoteMeta(string(quote))
+		pattern := regexp.MustCompile(`(^|[,\t;|:])\s*` + q + `[^` + q + `]*` + q + `\s*([,\t;|:]|$)`)
+		count := 0
+		for _, line := range lines {
+			count += len(pattern.FindAllStringIndex(line, -1))
+		}
+		if count > bestCount {
+			best = quote
+			bestCount = count
+		}
+	}
+	return best
+}
+
+// sniffDelimiter picks the candidate delimiter that appears a consistent number of times per line
+func sniffDelimiter(lines []string, quote rune) rune {
+	best := ','
+	bestScore := 0.0
+	for _, delim := range candidateDelimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			counts[countOutsideQuotes(line, delim, quote)]++
+		}
+
+		// Find the most common per-line count
+		mode, modeLines := 0, 0
+		for count, n := range counts {
+			if count > 0 && (n > modeLines || (n == modeLines && count > mode)) {
+				mode, modeLines = count, n
+			}
+		}
+		if mode == 0 {
+			continue
+		}
+
+		score := float64(modeLines) / float64(len(lines))
+		if score > bestScore {
+			best = delim
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// countOutsideQuotes counts occurrences of delim that are not inside a quoted section
+func countOutsideQuotes(line string, delim, quote rune) int {
+	count := 0
+	inQuotes := false
+	for _, r := range line {
+		switch r {
+		case quote:
+			inQuotes = !inQuotes
+		case delim:
+			if !inQuotes {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// sniffHeader decides whether the first row is a header. The file is assumed to have one
+// unless the evidence is strong: some columns hold numbers in every row below, the first row
+// has numbers there too, and no such column starts with text. Text columns tell nothing,
+// since values the length of their header are common (name,cc / alice,US); -no-header reads
+// such files without one.
+func sniffHeader(text string, dialect Dialect) bool {
+	records, err := parseRecords(text, dialect, 0)
+	if err != nil || len(records) < 2 {
+		return true
+	}
+
+	header := records[0]
+	rows := records[1:]
+	if len(rows) > 20 {
+		rows = rows[:20]
+	}
+
+	dataVotes, headerVotes := 0, 0
+	for col := range header {
+		numeric := true
+		for _, row := range rows {
+			if col >= len(row) {
+				numeric = false
+				break
+			}
+			if _, err := strconv.ParseFloat(strings.TrimSpace(row[col]), 64); err != nil {
+				numeric = false
+				break
+			}
+		}
+		if !numeric {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(header[col]), 64); err != nil {
+			headerVotes++
+		} else {
+			dataVotes++
+		}
+	}
+
+	return headerVotes > 0 || dataVotes == 0
+}
+
+// ParseDelimiter converts a delimiter flag value into a rune
+func ParseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "tab", "\\t", "\t":
+		return '\t', nil
+	case "comma":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	case "pipe":
+		return '|', nil
+	}
+
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("invalid delimiter: %q (expected a single character)", value)
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	if r == '\n' || r == '\r' || r == '"' {
+		return 0, fmt.Errorf("invalid delimiter: %q", value)
+	}
+	return r, nil
+}
+
+// hasCRLF reports whether the first line break of data, read in the given encoding, is \r\n
+func hasCRLF(data []byte, encoding string) bool {
+	if len(data) > SniffSampleSize {
+		data = data[:SniffSampleSize]
+	}
+	text, err := decodeBytes(data, encoding)
+	if err != nil {
+		return false
+	}
+	i := strings.IndexByte(text, '\n')
+	return i > 0 && text[i-1] == '\r'
+}
@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortKey is one column of a multi-column sort
+type SortKey struct {
+	Column string
+	Desc   bool
+	index  int // index is the column position, set by resolveSortKeys
+}
+
+// ParseSortKeys parses a sort specification in format "col1 desc,col2 asc"
+func ParseSortKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid sort key: %s", strings.TrimSpace(part))
+		}
+
+		key := SortKey{Column: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				key.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction: %s (use 'asc' or 'desc')", fields[1])
+			}
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty sort specification")
+	}
+	return keys, nil
+}
+
+// resolveSortKeys looks up the column position of each key in header
+func resolveSortKeys(header []string, keys []SortKey) ([]SortKey, error) {
+	resolved := make([]SortKey, len(keys))
+	for i, key := range keys {
+		key.index = indexOf(header, key.Column)
+		if key.index < 0 {
+			return nil, fmt.Errorf("column '%s' does not exist in CSV", key.Column)
+		}
+		resolved[i] = key
+	}
+	return resolved, nil
+}
+
+// compareRecords orders two rows by the sort keys; numbers compare numerically, everything else as text
+func compareRecords(a, b []string, keys []SortKey) int {
+	for _, key := range keys {
+		cmp := compareCells(cellAt(a, key.index), cellAt(b, key.index))
+		if key.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareCells compares two raw cell values, numerically when both are numbers
+func compareCells(a, b string) int {
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// sortRows stably sorts data rows in place by the sort keys
+func sortRows(rows [][]string, keys []SortKey) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareRecords(rows[i], rows[j], keys) < 0
+	})
+}
+
+func cellAt(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+	return ""
+}
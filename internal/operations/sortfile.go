@@ -0,0 +1,271 @@
+package operations
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SortChunkRows is the number of rows sorted in memory at a time; larger files are
+// sorted in chunks that are spilled to temporary files and merged
+const SortChunkRows = 200000
+
+// SortFile rewrites the file with its data rows sorted by spec ("col1 desc,col2 asc").
+// Plain UTF-8 files with double-quote quoting are streamed through an external merge sort;
+// other encodings and dialects are sorted in memory.
+func (ops *CSVOperations) SortFile(spec string) error {
+	keys, err := ParseSortKeys(spec)
+	if err != nil {
+		return fmt.Errorf("SORT error: %v", err)
+	}
+
+	file, err := os.Open(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	csvReader, dialect, err := ops.openStream(file)
+	if err != nil {
+		return err
+	}
+	if csvReader == nil {
+		return ops.sortInMemory(keys)
+	}
+
+	first, err := csvReader.Read()
+	if err == io.EOF {
+		fmt.Println("File is empty, nothing to sort.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CSV: %v", err)
+	}
+
+	// Headerless files are sorted by their synthetic column names
+	header, pending := first, [][]string(nil)
+	if !dialect.HasHeader {
+		if header, err = ops.headerlessNames(len(first)); err != nil {
+			return err
+		}
+		pending = [][]string{first}
+	}
+	names, err := ops.readHeader(header)
+	if err != nil {
+		return err
+	}
+	keys, err = resolveSortKeys(names, keys)
+	if err != nil {
+		return fmt.Errorf("SORT error: %v", err)
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(ops.FilePath), ".seesv-sort-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Sort chunks of rows in memory and spill each to its own file
+	var runs []string
+	rows, total := pending, len(pending)
+	for {
+		record, err := csvReader.Read()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read CSV: %v", err)
+		}
+		if record != nil {
+			rows = append(rows, record)
+			total++
+			if err := ops.checkScanRows(total); err != nil {
+				return err
+			}
+		}
+		if len(rows) >= SortChunkRows || (err == io.EOF && (len(rows) > 0 && len(runs) > 0)) {
+			sortRows(rows, keys)
+			run, err := writeRun(tmpDir, len(runs), rows, dialect.Delimiter)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			rows = nil
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	// Write to a temporary file next to the original, then replace it
+	out, err := os.CreateTemp(filepath.Dir(ops.FilePath), ".seesv-sorted-")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer os.Remove(out.Name())
+
+	writer := csv.NewWriter(out)
+	writer.Comma = dialect.Delimiter
+	writer.UseCRLF = dialect.CRLF
+	if dialect.Encoding == "utf-8-bom" {
+		out.Write([]byte{0xEF, 0xBB, 0xBF})
+	}
+	if dialect.HasHeader {
+		writer.Write(ops.originalHeaders(names))
+	}
+
+	if len(runs) == 0 {
+		// Everything fit in one chunk
+		sortRows(rows, keys)
+		writer.WriteAll(rows)
+	} else if err := mergeRuns(runs, keys, dialect.Delimiter, writer); err != nil {
+		out.Close()
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	// CreateTemp makes the file private; the sorted file keeps the original's permissions
+	if info, err := file.Stat(); err == nil {
+		if err := os.Chmod(out.Name(), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to set file mode: %v", err)
+		}
+	}
+
+	file.Close()
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if err := os.Rename(out.Name(), ops.FilePath); err != nil {
+		return fmt.Errorf("failed to replace file: %v", err)
+	}
+
+	fmt.Printf("Successfully sorted %d rows\n", total)
+	return nil
+}
+
+// sortInMemory sorts files the streaming path cannot read, keeping their dialect on save
+func (ops *CSVOperations) sortInMemory(keys []SortKey) error {
+	data, err := os.ReadFile(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return err
+	}
+	if len(records) < 2 {
+		fmt.Println("No rows to sort.")
+		return nil
+	}
+
+	keys, err = resolveSortKeys(records[0], keys)
+	if err != nil {
+		return fmt.Errorf("SORT error: %v", err)
+	}
+	sortRows(records[1:], keys)
+	if err := ops.writeRecords(records, dialect); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully sorted %d rows\n", len(records)-1)
+	return nil
+}
+
+// writeRun spills a sorted chunk to a temporary file
+func writeRun(dir string, n int, rows [][]string, delimiter rune) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("run-%d.csv", n))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = delimiter
+	if err := writer.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %v", err)
+	}
+	return path, nil
+}
+
+// mergeRuns k-way merges sorted run files into writer
+func mergeRuns(runs []string, keys []SortKey, delimiter rune, writer *csv.Writer) error {
+	h := &runHeap{keys: keys}
+	for _, path := range runs {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open temporary file: %v", err)
+		}
+		defer file.Close()
+
+		reader := csv.NewReader(bufio.NewReader(file))
+		reader.Comma = delimiter
+		reader.FieldsPerRecord = -1
+		record, err := reader.Read()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %v", err)
+		}
+		h.runs = append(h.runs, &runCursor{reader: reader, record: record, order: len(h.runs)})
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		cursor := h.runs[0]
+		if err := writer.Write(cursor.record); err != nil {
+			return fmt.Errorf("failed to write CSV: %v", err)
+		}
+		record, err := cursor.reader.Read()
+		if err == io.EOF {
+			heap.Pop(h)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %v", err)
+		}
+		cursor.record = record
+		heap.Fix(h, 0)
+	}
+	return nil
+}
+
+// runCursor is the current row of one sorted run
+type runCursor struct {
+	reader *csv.Reader
+	record []string
+	order  int // order keeps the merge stable: earlier runs win ties
+}
+
+// runHeap orders run cursors by their current row
+type runHeap struct {
+	runs []*runCursor
+	keys []SortKey
+}
+
+func (h *runHeap) Len() int { return len(h.runs) }
+
+func (h *runHeap) Less(i, j int) bool {
+	if cmp := compareRecords(h.runs[i].record, h.runs[j].record, h.keys); cmp != 0 {
+		return cmp < 0
+	}
+	return h.runs[i].order < h.runs[j].order
+}
+
+func (h *runHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *runHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*runCursor)) }
+
+func (h *runHeap) Pop() interface{} {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
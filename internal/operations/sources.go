@@ -0,0 +1,70 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats other than CSV that are read as a table, detected from the file's content
+const (
+	SourceHAR    = "har"    // SourceHAR is an HTTP Archive (browser devtools, proxies)
+	SourceBurp   = "burp"   // SourceBurp is a Burp Suite "Save items" XML export
+	SourceNmap   = "nmap"   // SourceNmap is Nmap or Masscan XML output (-oX)
+	SourceNuclei = "nuclei" // SourceNuclei is Nuclei JSON Lines (-jsonl) or JSON export output
+	SourceJSON   = "json"   // SourceJSON is any other JSON Lines or JSON array of objects, such as an API dump
+)
+
+// sourceNames are the display names of the formats
+var sourceNames = map[string]string{
+	SourceHAR:        "HAR",
+	SourceBurp:       "Burp Suite XML",
+	SourceNmap:       "Nmap XML",
+	SourceNuclei:     "Nuclei JSON",
+	SourceJSON:       "JSON",
+	SourceFixedWidth: "fixed-width",
+}
+
+// detectSource recognizes a capture or scanner output from the start of decoded text
+func detectSource(text string) string {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "\ufeff"))
+	firstLine, _, _ := strings.Cut(text, "\n")
+	switch {
+	case strings.HasPrefix(text, "{") && strings.Contains(firstLine, `"template-id"`),
+		strings.HasPrefix(text, "[") && strings.Contains(text, `"template-id"`):
+		return SourceNuclei
+	case strings.HasPrefix(text, "{") && strings.Contains(text, `"log"`):
+		return SourceHAR
+	case strings.HasPrefix(text, "<?xml") && strings.Contains(text, "<nmaprun"):
+		return SourceNmap
+	case strings.HasPrefix(text, "<?xml") && strings.Contains(text, "<items"):
+		return SourceBurp
+	case isJSONObjects(text):
+		return SourceJSON
+	}
+	return ""
+}
+
+// sourceRecords converts a capture or scanner output to records, header first
+func sourceRecords(text, source string) ([][]string, error) {
+	switch source {
+	case SourceHAR:
+		return harRecords(text)
+	case SourceBurp:
+		return burpRecords(text)
+	case SourceNmap:
+		return nmapRecords(text)
+	case SourceNuclei:
+		return nucleiRecords(text)
+	case SourceJSON:
+		return jsonRecords(text)
+	}
+	return nil, fmt.Errorf("unknown input format: %s", source)
+}
+
+// sourceWriteError refuses to rewrite a capture or scanner output, which would replace it with CSV
+func sourceWriteError(path string, dialect Dialect) error {
+	if dialect.Source == "" {
+		return nil
+	}
+	return fmt.Errorf("%s is a %s file and cannot be rewritten; save its rows with -output rows.csv first", path, sourceNames[dialect.Source])
+}
@@ -0,0 +1,96 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SourceFileColumn is the column -add-source-file fills with the file each row of a glob came from
+const SourceFileColumn = "_source_file"
+
+// SpoolInput copies piped or downloaded input to a temporary file, so that it can be sniffed,
+// streamed and read more than once like any other input. The caller removes the file when done.
+func SpoolInput(r io.Reader) (string, error) {
+	file, err := os.CreateTemp("", "seesv-input-*.csv")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a temporary file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// IsGlob reports whether an input is a file pattern such as logs/2024-*.csv rather than a file
+func IsGlob(name string) bool {
+	if _, err := os.Stat(name); err == nil {
+		return false
+	}
+	return strings.ContainsAny(name, "*?[")
+}
+
+// ConcatFiles reads every file matching pattern, in name order, with the dialect options of ops
+// and writes their rows under one header to a temporary CSV file. All files must have the same
+// columns in the same order. With sourceColumn, a _source_file column records the file of each
+// row. The caller removes the file when done.
+func (ops *CSVOperations) ConcatFiles(pattern string, sourceColumn bool) (string, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid file pattern %s: %v", pattern, err)
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no files match %s", pattern)
+	}
+
+	var header []string
+	var headerPath string // headerPath is the first non-empty file, which set the header
+	var rows [][]string
+	for _, path := range paths {
+		data, err := readInput(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open file: %v", err)
+		}
+		records, _, err := ops.ReadRecords(data)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", path, err)
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if header == nil {
+			header, headerPath = records[0], path
+		} else if !slices.Equal(header, records[0]) {
+			return "", fmt.Errorf("%s has columns %s, but %s has %s", path, strings.Join(records[0], ","), headerPath, strings.Join(header, ","))
+		}
+		for _, row := range records[1:] {
+			if sourceColumn {
+				row = append(row, path)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if header == nil {
+		return "", fmt.Errorf("every file matching %s is empty", pattern)
+	}
+	if sourceColumn {
+		if slices.Contains(header, SourceFileColumn) {
+			return "", fmt.Errorf("-add-source-file: column '%s' already exists", SourceFileColumn)
+		}
+		header = append(header, SourceFileColumn)
+	}
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(append([][]string{header}, rows...)); err != nil {
+		return "", fmt.Errorf("failed to combine %s: %v", pattern, err)
+	}
+	return SpoolInput(&buf)
+}
@@ -0,0 +1,55 @@
+package operations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// DefaultSQLiteTable is the table -format sqlite writes when -table names none
+const DefaultSQLiteTable = "results"
+
+// SaveSQLite writes the rows of df into the table of the SQLite database filename, creating
+// the database if needed and replacing a table of the same name. Other tables are kept, so
+// several results can be collected in one database. Column types are inferred as by
+// -workspace (see createSQLiteTable).
+func (ops *CSVOperations) SaveSQLite(df dataframe.DataFrame, filename string) error {
+	table := ops.sqliteTable()
+	if df.Ncol() == 0 {
+		return fmt.Errorf("the result has no columns to store in table %s", table)
+	}
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a SQLite database", filename)
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", filename, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", filename, err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DROP TABLE IF EXISTS " + quoteIdentifier(table)); err != nil {
+		return fmt.Errorf("failed to replace table %s: %v", table, err)
+	}
+	if err := createSQLiteTable(tx, table, dataFrameRecords(df)); err != nil {
+		return fmt.Errorf("failed to write table %s: %v", table, err)
+	}
+	return tx.Commit()
+}
+
+func (ops *CSVOperations) sqliteTable() string {
+	if ops.SQLiteTable == "" {
+		return DefaultSQLiteTable
+	}
+	return ops.SQLiteTable
+}
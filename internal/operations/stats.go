@@ -0,0 +1,119 @@
+package operations
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gota/gota/series"
+)
+
+// numericValues returns the non-missing values of a numeric column
+func numericValues(col series.Series) []float64 {
+	values := make([]float64, 0, col.Len())
+	for i := 0; i < col.Len(); i++ {
+		elem := col.Elem(i)
+		if elem.IsNA() {
+			continue
+		}
+		if f := elem.Float(); !math.IsNaN(f) {
+			values = append(values, f)
+		}
+	}
+	return values
+}
+
+// allMissing reports whether every value of col is NULL, as in the derived column of a
+// conditional aggregate whose condition matched no rows
+func allMissing(col series.Series) bool {
+	for i := 0; i < col.Len(); i++ {
+		if !col.Elem(i).IsNA() {
+			return false
+		}
+	}
+	return true
+}
+
+// calculateStatistic computes STDDEV, VARIANCE, MEDIAN and PERCENTILE over values.
+// STDDEV and VARIANCE are sample statistics (n-1); the _POP variants divide by n.
+// Percentiles interpolate linearly between the closest ranks.
+func calculateStatistic(aggFunc AggregateFunction, values []float64) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	switch aggFunc.Function {
+	case "STDDEV", "STDDEV_POP", "VARIANCE", "VAR_POP":
+		population := strings.HasSuffix(aggFunc.Function, "_POP")
+		if !population && len(values) < 2 {
+			return nil
+		}
+		variance := sumSquaredDeviations(values)
+		if population {
+			variance /= float64(len(values))
+		} else {
+			variance /= float64(len(values) - 1)
+		}
+		if strings.HasPrefix(aggFunc.Function, "STDDEV") {
+			return math.Sqrt(variance)
+		}
+		return variance
+
+	case "MEDIAN":
+		return percentile(values, 50)
+
+	default:
+		return percentile(values, aggFunc.Arg)
+	}
+}
+
+// sumSquaredDeviations returns the sum of squared differences from the mean
+func sumSquaredDeviations(values []float64) float64 {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum
+}
+
+// percentile returns the p-th percentile (0-100) of values, sorting them in place
+func percentile(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+// splitTopLevel splits a comma-separated list, ignoring commas inside parentheses or quotes
+func splitTopLevel(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range list {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case r == ',' && depth == 0:
+			parts = append(parts, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, list[start:])
+}
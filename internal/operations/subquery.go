@@ -0,0 +1,64 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// memberSet is the materialized result of an IN (SELECT ...) subquery
+type memberSet struct {
+	values  map[string]bool // values holds the non-NULL results by membershipKey
+	hasNull bool            // hasNull is set when the subquery returned NULL
+}
+
+// materializeSubqueries runs every IN (SELECT ...) subquery of expr once, before any row is
+// evaluated, and hands the results to the evaluator as membership sets
+func (ops *CSVOperations) materializeSubqueries(evaluator *Evaluator, expr sqlparser.Expr) error {
+	var err error
+	sqlparser.Walk(expr, func(node sqlparser.Expr) {
+		in, ok := node.(*sqlparser.InExpr)
+		if !ok || in.Subquery == nil || err != nil {
+			return
+		}
+		var set *memberSet
+		if set, err = ops.subquerySet(in.Subquery); err == nil {
+			evaluator.subqueries[in] = set
+		}
+	})
+	return err
+}
+
+// subquerySet runs a single-column subquery and collects its values
+func (ops *CSVOperations) subquerySet(stmt sqlparser.Statement) (*memberSet, error) {
+	df, err := ops.statementFrame(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("subquery error: %v", err)
+	}
+	if df.Ncol() != 1 {
+		return nil, fmt.Errorf("subquery error: IN (SELECT ...) must select exactly one column, not %d", df.Ncol())
+	}
+
+	evaluator := ops.evaluator(df)
+	set := &memberSet{values: make(map[string]bool)}
+	for i := 0; i < df.Nrow(); i++ {
+		value := evaluator.cellValue(i, 0)
+		if value == nil {
+			set.hasNull = true
+			continue
+		}
+		set.values[membershipKey(value)] = true
+	}
+	return set, nil
+}
+
+// membershipKey is the text a value is looked up by in a memberSet. Numbers and numeric text
+// share their canonical form, so 1, 1.0 and '1' match as they do in comparisons.
+func membershipKey(value interface{}) string {
+	if _, isBool := value.(bool); !isBool {
+		if n, ok := toNumber(value); ok {
+			return formatValue(n)
+		}
+	}
+	return formatValue(value)
+}
@@ -0,0 +1,280 @@
+package operations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"github.com/mattn/go-runewidth"
+)
+
+// TableStyle describes the lines drawn around and between table cells. Rules are drawn from
+// the Rule character with the given joints; an empty Rule draws no rule at all.
+type TableStyle struct {
+	Vertical string    // Vertical separates cells, and frames rows when Frame is set
+	Rule     string    // Rule is the horizontal line character
+	Frame    bool      // Frame draws a border around the whole table
+	Top      [3]string // Top holds the left, middle and right joints of the top border
+	Middle   [3]string // Middle holds the joints of the rule below the header
+	Bottom   [3]string // Bottom holds the joints of the bottom border
+}
+
+// tableStyles are the presets accepted by -table-style; compact is the default
+var tableStyles = map[string]TableStyle{
+	"compact":    {Vertical: "|", Rule: "-", Middle: [3]string{"", "+", ""}},
+	"borderless": {Vertical: " "},
+	"ascii": {Vertical: "|", Rule: "-", Frame: true,
+		Top: [3]string{"+", "+", "+"}, Middle: [3]string{"+", "+", "+"}, Bottom: [3]string{"+", "+", "+"}},
+	"light": {Vertical: "│", Rule: "─", Frame: true,
+		Top: [3]string{"┌", "┬", "┐"}, Middle: [3]string{"├", "┼", "┤"}, Bottom: [3]string{"└", "┴", "┘"}},
+	"heavy": {Vertical: "┃", Rule: "━", Frame: true,
+		Top: [3]string{"┏", "┳", "┓"}, Middle: [3]string{"┣", "╋", "┫"}, Bottom: [3]string{"┗", "┻", "┛"}},
+	"double": {Vertical: "║", Rule: "═", Frame: true,
+		Top: [3]string{"╔", "╦", "╗"}, Middle: [3]string{"╠", "╬", "╣"}, Bottom: [3]string{"╚", "╩", "╝"}},
+}
+
+// ParseTableStyle validates a -table-style value, defaulting to compact
+func ParseTableStyle(value string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if name == "" {
+		return "compact", nil
+	}
+	if _, ok := tableStyles[name]; !ok {
+		names := make([]string, 0, len(tableStyles))
+		for known := range tableStyles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("invalid table style: %s (use %s)", value, strings.Join(names, ", "))
+	}
+	return name, nil
+}
+
+// tableStyle returns the configured table style, compact when none is set
+func (ops *CSVOperations) tableStyle() TableStyle {
+	if style, ok := tableStyles[ops.TableStyle]; ok {
+		return style
+	}
+	return tableStyles["compact"]
+}
+
+// Width returns the number of terminal cells a table row needs for columns of the given widths
+func (s TableStyle) Width(widths []int) int {
+	if len(widths) > 0 && s.Frame {
+		return TableWidth(widths) + 4
+	}
+	return TableWidth(widths)
+}
+
+// PrintTable prints the dataframe as aligned columns in the configured table style. Columns
+// are as wide as their widest value, up to -max-col-width; longer values are cut short with
+// an ellipsis or, with -wrap, continued on the following lines. -totals adds a footer row.
+func (ops *CSVOperations) PrintTable(df dataframe.DataFrame) {
+	style := ops.tableStyle()
+	headers, rows := ops.tableCells(df)
+	var totals []string
+	measured := rows
+	if ops.Totals && len(rows) > 0 {
+		totals = ops.totalsRow(df)
+		measured = append(rows[:len(rows):len(rows)], totals)
+	}
+	widths := ops.columnWidths(headers, measured)
+
+	if style.Frame {
+		style.printRule(widths, style.Top)
+	}
+	headerColor, _ := ops.rowColors(headers, -1)
+	style.printRow(headers, widths, ops.Wrap, headerColor, nil)
+	style.printRule(widths, style.Middle)
+	for i, cells := range rows {
+		lineColor, cellColors := ops.rowColors(headers, i)
+		style.printRow(cells, widths, ops.Wrap, lineColor, cellColors)
+	}
+	if totals != nil {
+		style.printRule(widths, style.Middle)
+		style.printRow(totals, widths, ops.Wrap, headerColor, nil)
+	}
+	if style.Frame {
+		style.printRule(widths, style.Bottom)
+	}
+}
+
+// tableCells returns the header and the cells of df as table output shows them
+func (ops *CSVOperations) tableCells(df dataframe.DataFrame) ([]string, [][]string) {
+	headers := df.Names()
+	rows := make([][]string, df.Nrow())
+	for i := range rows {
+		cells := make([]string, df.Ncol())
+		for j := range cells {
+			cells[j] = ops.displayText(df, i, j)
+			if unit, ok := ops.Humanize[headers[j]]; ok {
+				cells[j] = HumanizeValue(cells[j], unit)
+			}
+		}
+		rows[i] = cells
+	}
+	return headers, rows
+}
+
+// totalsRow returns the -totals footer of df: the sum of each number column, and the number
+// of non-empty values in the others
+func (ops *CSVOperations) totalsRow(df dataframe.DataFrame) []string {
+	headers := df.Names()
+	cells := make([]string, df.Ncol())
+	for j := range cells {
+		switch df.Elem(0, j).Type() {
+		case series.Int:
+			var sum int64
+			for i := 0; i < df.Nrow(); i++ {
+				if n, err := df.Elem(i, j).Int(); err == nil {
+					sum += int64(n)
+				}
+			}
+			cells[j] = strconv.FormatInt(sum, 10)
+		case series.Float:
+			sum := 0.0
+			for i := 0; i < df.Nrow(); i++ {
+				if elem := df.Elem(i, j); !elem.IsNA() {
+					sum += elem.Float()
+				}
+			}
+			cells[j] = FormatAggregateValue(sum)
+		default:
+			count := 0
+			for i := 0; i < df.Nrow(); i++ {
+				if CellText(df.Elem(i, j)) != "" {
+					count++
+				}
+			}
+			cells[j] = strconv.Itoa(count)
+		}
+		if unit, ok := ops.Humanize[headers[j]]; ok {
+			cells[j] = HumanizeValue(cells[j], unit)
+		}
+	}
+	return cells
+}
+
+// columnWidths returns the width of each table column: that of its widest cell, header
+// included, and at most MaxColWidth when it is set. With -wrap, the lines of a cell are
+// measured separately.
+func (ops *CSVOperations) columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	measure := func(j int, cell string) {
+		lines := []string{cell}
+		if ops.Wrap {
+			lines = strings.Split(cell, "\n")
+		}
+		for _, line := range lines {
+			widths[j] = max(widths[j], DisplayWidth(line))
+		}
+	}
+	for j, header := range headers {
+		widths[j] = 1
+		measure(j, header)
+	}
+	for _, cells := range rows {
+		for j, cell := range cells {
+			measure(j, cell)
+		}
+	}
+	if ops.MaxColWidth > 0 {
+		for j := range widths {
+			widths[j] = min(widths[j], ops.MaxColWidth)
+		}
+	}
+	return widths
+}
+
+// printRow prints cells padded to the column widths and separated by the vertical line. Cells
+// that do not fit are cut short, or wrapped onto extra lines of the row. The ANSI escapes of
+// lineColor and cellColors, when given, style the whole row and single cells.
+func (s TableStyle) printRow(cells []string, widths []int, wrap bool, lineColor string, cellColors []string) {
+	lines := make([][]string, len(cells))
+	height := 1
+	for j, cell := range cells {
+		lines[j] = fitCell(cell, widths[j], wrap)
+		height = max(height, len(lines[j]))
+	}
+
+	for k := 0; k < height; k++ {
+		var line strings.Builder
+		line.WriteString(lineColor)
+		if s.Frame {
+			line.WriteString(s.Vertical + " ")
+		}
+		for j := range cells {
+			if j > 0 {
+				line.WriteString(" " + s.Vertical + " ")
+			}
+			text := ""
+			if k < len(lines[j]) {
+				text = lines[j][k]
+			}
+			text = padRight(text, widths[j])
+			if j < len(cellColors) && cellColors[j] != "" {
+				text = cellColors[j] + text + ansiReset + lineColor
+			}
+			line.WriteString(text)
+		}
+		if s.Frame {
+			line.WriteString(" " + s.Vertical)
+		}
+		if lineColor != "" {
+			line.WriteString(ansiReset)
+		}
+		fmt.Println(line.String())
+	}
+}
+
+// fitCell returns the lines a cell takes in a column of width terminal cells: the text cut
+// short with an ellipsis, or with wrap, broken at its newlines and at the last space that fits
+// (anywhere when a word is too long)
+func fitCell(text string, width int, wrap bool) []string {
+	if !wrap {
+		if DisplayWidth(text) <= width {
+			return []string{text}
+		}
+		return []string{runewidth.Truncate(text, width, "…")}
+	}
+
+	var lines []string
+	for _, rest := range strings.Split(text, "\n") {
+		for DisplayWidth(rest) > width {
+			cut := runewidth.Truncate(rest, width, "")
+			if i := strings.LastIndex(cut, " "); i > 0 {
+				cut = cut[:i+1]
+			}
+			if cut == "" {
+				// A character wider than the column gets a line of its own
+				_, size := utf8.DecodeRuneInString(rest)
+				cut = rest[:size]
+			}
+			lines = append(lines, strings.TrimRight(cut, " "))
+			rest = rest[len(cut):]
+		}
+		lines = append(lines, rest)
+	}
+	return lines
+}
+
+// printRule prints a horizontal rule for columns of the given widths with the given left,
+// middle and right joints
+func (s TableStyle) printRule(widths []int, joints [3]string) {
+	if s.Rule == "" {
+		return
+	}
+	segments := make([]string, len(widths))
+	for i, width := range widths {
+		segments[i] = strings.Repeat(s.Rule, width)
+	}
+	line := strings.Join(segments, s.Rule+joints[1]+s.Rule)
+	if s.Frame {
+		line = joints[0] + s.Rule + line + s.Rule + joints[2]
+	}
+	fmt.Println(line)
+}
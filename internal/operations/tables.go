@@ -0,0 +1,281 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// ParseTableFiles reads -table name=path entries into a map from table name to file. A bare
+// name, without a path, is the table -format sqlite writes and is returned on its own.
+func ParseTableFiles(specs []string) (map[string]string, string, error) {
+	tables := make(map[string]string)
+	output := ""
+	for _, spec := range specs {
+		name, path, found := strings.Cut(spec, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if name == "" || (found && path == "") {
+			return nil, "", fmt.Errorf("invalid -table: %s (expected name=path.csv, or a name for -format sqlite)", spec)
+		}
+		if !workspaceTableName.MatchString(name) {
+			return nil, "", fmt.Errorf("invalid table name: %s (use letters, digits and underscores)", name)
+		}
+		if !found {
+			if output != "" {
+				return nil, "", fmt.Errorf("-table names the -format sqlite table twice: %s and %s", output, name)
+			}
+			output = name
+			continue
+		}
+		if _, ok := tables[name]; ok {
+			return nil, "", fmt.Errorf("table %s is given twice by -table", name)
+		}
+		tables[name] = path
+	}
+	return tables, output, nil
+}
+
+// ResolveTable maps a FROM or JOIN table name to a CSV file path: a -table name first, then
+// a workspace table or file (see Workspace.ResolveTablePath)
+func ResolveTable(tables map[string]string, ws *Workspace, name string) (string, error) {
+	if path, ok := tables[name]; ok {
+		return path, nil
+	}
+	return ws.ResolveTablePath(name)
+}
+
+func (ops *CSVOperations) resolveTable(name string) (string, error) {
+	return ResolveTable(ops.Tables, ops.Workspace, name)
+}
+
+// joinTables replaces the loaded table, which is the FROM table, with its join to the JOIN
+// tables of stmt, and rewrites the column references of stmt to the joined columns. A column
+// keeps its name when no other table has one of that name and is called table.column
+// otherwise, so s.id and f.id both survive. ON conditions are equalities of columns joined by
+// AND; cells are compared as text and an empty cell matches nothing, as NULL does.
+func (ops *CSVOperations) joinTables(stmt *sqlparser.SelectStatement) error {
+	if stmt.From.Name == "" {
+		return fmt.Errorf("JOIN requires a FROM table")
+	}
+	tables := []sqlparser.TableRef{stmt.From}
+	for _, join := range stmt.Joins {
+		tables = append(tables, join.Table)
+	}
+
+	// Each table is known by its alias, or by its name when it has none
+	qualifiers := make([]string, len(tables))
+	headers := make([][]string, len(tables))
+	rows := make([][][]string, len(tables))
+	for t, table := range tables {
+		qualifiers[t] = table.Alias
+		if qualifiers[t] == "" {
+			qualifiers[t] = table.Name
+		}
+		if indexOf(qualifiers[:t], qualifiers[t]) >= 0 {
+			return fmt.Errorf("table %s appears twice in the join; give each one an alias", qualifiers[t])
+		}
+
+		df := ops.DataFrame
+		if t > 0 {
+			path, err := ops.resolveTable(table.Name)
+			if err != nil {
+				return err
+			}
+			if df, err = ops.LoadFile(path); err != nil {
+				return fmt.Errorf("failed to load %s: %v", table.Name, err)
+			}
+		}
+		headers[t] = df.Names()
+		rows[t] = make([][]string, df.Nrow())
+		for i := range rows[t] {
+			rows[t][i] = RowValues(df, i)
+		}
+	}
+
+	// resolve finds the table and column index that a reference names among the first n tables
+	resolve := func(ref *sqlparser.ColumnRef, n int) (int, int, error) {
+		if ref.Table != "" {
+			t := indexOf(qualifiers[:n], ref.Table)
+			if t < 0 {
+				return -1, -1, fmt.Errorf("unknown table %s in %s", ref.Table, ref.String())
+			}
+			j := indexOf(headers[t], ref.Name)
+			if j < 0 {
+				return -1, -1, fmt.Errorf("column '%s' does not exist in %s", ref.Name, ref.Table)
+			}
+			return t, j, nil
+		}
+		found, foundCol := -1, -1
+		for t := 0; t < n; t++ {
+			if j := indexOf(headers[t], ref.Name); j >= 0 {
+				if found >= 0 {
+					return -1, -1, fmt.Errorf("column '%s' is ambiguous; qualify it as %s.%s or %s.%s", ref.Name, qualifiers[found], ref.Name, qualifiers[t], ref.Name)
+				}
+				found, foundCol = t, j
+			}
+		}
+		if found < 0 {
+			return -1, -1, fmt.Errorf("column '%s' does not exist in any joined table", ref.Name)
+		}
+		return found, foundCol, nil
+	}
+
+	// offsets[t] is the position of the first column of table t in a joined row
+	offsets := make([]int, len(tables))
+	joined := rows[0]
+	width := len(headers[0])
+	for t, join := range stmt.Joins {
+		t++
+		offsets[t] = width
+
+		var leftKeys, rightKeys []int
+		for _, cond := range splitConjunction(join.On) {
+			eq, ok := cond.(*sqlparser.BinaryExpr)
+			left, leftOK := columnRefOf(eq, true)
+			right, rightOK := columnRefOf(eq, false)
+			if !ok || eq.Op != "=" || !leftOK || !rightOK {
+				return fmt.Errorf("JOIN %s ON supports equal columns joined by AND (a.id = b.id AND ...), not %s", join.Table.Name, cond.String())
+			}
+			lt, lj, err := resolve(left, t+1)
+			if err != nil {
+				return err
+			}
+			rt, rj, err := resolve(right, t+1)
+			if err != nil {
+				return err
+			}
+			if lt == t {
+				lt, lj, rt, rj = rt, rj, lt, lj
+			}
+			if rt != t || lt == t {
+				return fmt.Errorf("JOIN %s ON must compare a column of %s with a column of an earlier table: %s", join.Table.Name, qualifiers[t], cond.String())
+			}
+			leftKeys = append(leftKeys, offsets[lt]+lj)
+			rightKeys = append(rightKeys, rj)
+		}
+
+		index := make(map[string][]int)
+		for i, row := range rows[t] {
+			if key, ok := joinKey(row, rightKeys); ok {
+				index[key] = append(index[key], i)
+			}
+		}
+		var next [][]string
+		for _, row := range joined {
+			var matches []int
+			if key, ok := joinKey(row, leftKeys); ok {
+				matches = index[key]
+			}
+			for _, i := range matches {
+				next = append(next, append(append([]string(nil), row...), rows[t][i]...))
+			}
+			if len(matches) == 0 && join.Type == "LEFT" {
+				next = append(next, append(append([]string(nil), row...), make([]string, len(headers[t]))...))
+			}
+		}
+		joined = next
+		width += len(headers[t])
+	}
+
+	// Column names shared by several tables are qualified with the table
+	count := make(map[string]int)
+	for _, header := range headers {
+		for _, name := range header {
+			count[name]++
+		}
+	}
+	joinedName := func(t, j int) string {
+		if count[headers[t][j]] > 1 {
+			return qualifiers[t] + "." + headers[t][j]
+		}
+		return headers[t][j]
+	}
+	var names []string
+	for t := range tables {
+		for j := range headers[t] {
+			names = append(names, joinedName(t, j))
+		}
+	}
+
+	// Point the references of the statement at the joined columns; unqualified names that no
+	// table has are left alone, since they may be select aliases or bare text
+	var refErr error
+	rewrite := func(expr sqlparser.Expr) {
+		sqlparser.Walk(expr, func(e sqlparser.Expr) {
+			ref, ok := e.(*sqlparser.ColumnRef)
+			if !ok || refErr != nil || (ref.Table == "" && count[ref.Name] == 0) {
+				return
+			}
+			t, j, err := resolve(ref, len(tables))
+			if err != nil {
+				refErr = err
+				return
+			}
+			ref.Table, ref.Name = "", joinedName(t, j)
+			ref.Quoted = ref.Quoted || strings.Contains(ref.Name, ".")
+		})
+	}
+	for _, item := range stmt.Columns {
+		rewrite(item.Expr)
+	}
+	rewrite(stmt.Where)
+	for _, expr := range stmt.GroupBy {
+		rewrite(expr)
+	}
+	rewrite(stmt.Having)
+	rewrite(stmt.Qualify)
+	for _, item := range stmt.OrderBy {
+		rewrite(item.Expr)
+	}
+	if refErr != nil {
+		return refErr
+	}
+
+	if len(joined) == 0 {
+		ops.DataFrame = NewStringDataFrame(names, nil)
+	} else {
+		df, err := RecordsToDataFrame(append([][]string{names}, joined...))
+		if err != nil {
+			return err
+		}
+		ops.DataFrame = df
+	}
+	ops.Headers = names
+	stmt.Joins = nil
+	return nil
+}
+
+// splitConjunction splits a condition into the terms joined by AND
+func splitConjunction(expr sqlparser.Expr) []sqlparser.Expr {
+	if and, ok := expr.(*sqlparser.BinaryExpr); ok && and.Op == "AND" {
+		return append(splitConjunction(and.Left), splitConjunction(and.Right)...)
+	}
+	return []sqlparser.Expr{expr}
+}
+
+// columnRefOf returns the left or right side of a comparison when it is a column
+func columnRefOf(expr *sqlparser.BinaryExpr, left bool) (*sqlparser.ColumnRef, bool) {
+	if expr == nil {
+		return nil, false
+	}
+	side := expr.Right
+	if left {
+		side = expr.Left
+	}
+	ref, ok := side.(*sqlparser.ColumnRef)
+	return ref, ok
+}
+
+// joinKey builds the lookup key of row from the cells at columns; rows with an empty key cell
+// match nothing
+func joinKey(row []string, columns []int) (string, bool) {
+	parts := make([]string, len(columns))
+	for i, j := range columns {
+		if row[j] == "" {
+			return "", false
+		}
+		parts[i] = row[j]
+	}
+	return strings.Join(parts, "\x00"), true
+}
@@ -0,0 +1,77 @@
+package operations
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// ParseTemplate reads a -template for -format template: Go text/template source, or the path
+// of a file holding it. Each row is executed with its columns as fields, so {{.name}} is the
+// value of column name and {{index . "first name"}} reaches names that are not identifiers.
+// Columns the result lacks are an error rather than "<no value>".
+func ParseTemplate(text string) (*template.Template, error) {
+	name := "-template"
+	if info, err := os.Stat(text); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %v", err)
+		}
+		name, text = text, string(data)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate renders each row of df through the -template, ending each with a newline
+// unless the template already does. Values are written as in -raw output; exact keeps
+// aggregates unrounded, as saved files do.
+func (ops *CSVOperations) writeTemplate(w io.Writer, df dataframe.DataFrame, exact bool) error {
+	if ops.Template == nil {
+		return fmt.Errorf("-format template requires -template")
+	}
+	names := df.Names()
+	var out bytes.Buffer
+	for i := 0; i < df.Nrow(); i++ {
+		row := make(map[string]string, len(names))
+		for j, name := range names {
+			if exact {
+				row[name] = ops.outputText(df.Elem(i, j))
+			} else {
+				row[name] = ops.displayText(df, i, j)
+			}
+		}
+		start := out.Len()
+		if err := ops.Template.Execute(&out, row); err != nil {
+			return fmt.Errorf("failed to render row %d: %v", i+1, err)
+		}
+		if rendered := out.Bytes()[start:]; len(rendered) == 0 || rendered[len(rendered)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// saveTemplate writes the rendered rows of df to filename
+func (ops *CSVOperations) saveTemplate(df dataframe.DataFrame, filename string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	file, err := ops.createOutput(filename)
+	if err != nil {
+		return err
+	}
+	if err := ops.writeTemplate(file, df, true); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,107 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReverseFile rewrites the file with its data rows in reverse order
+func (ops *CSVOperations) ReverseFile() error {
+	return ops.transformRows(func(rows [][]string) [][]string {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+		return rows
+	}, "reversed")
+}
+
+// RotateFile rewrites the file with its first n data rows moved to the end.
+// A negative n moves the last rows to the front instead.
+func (ops *CSVOperations) RotateFile(n int) error {
+	return ops.transformRows(func(rows [][]string) [][]string {
+		if len(rows) == 0 {
+			return rows
+		}
+		shift := ((n % len(rows)) + len(rows)) % len(rows)
+		return append(rows[shift:], rows[:shift]...)
+	}, "rotated")
+}
+
+// ReorderFile rewrites the file with the given columns first, in that order, followed by the
+// others in their current order, e.g. to line up exports from different tools
+func (ops *CSVOperations) ReorderFile(columns []string) error {
+	records, dialect, err := ops.readRawRecords()
+	if err != nil {
+		return err
+	}
+	header := records[0]
+	for i, column := range columns {
+		if indexOf(header, column) < 0 {
+			return fmt.Errorf("column '%s' does not exist in CSV", column)
+		}
+		if indexOf(columns[:i], column) >= 0 {
+			return fmt.Errorf("-reorder lists column '%s' twice", column)
+		}
+	}
+
+	order := reorderNames(header, columns)
+	positions := make([]int, len(order))
+	for i, column := range order {
+		positions[i] = indexOf(header, column)
+	}
+	for i, record := range records {
+		reordered := make([]string, len(positions))
+		for j, pos := range positions {
+			if pos < len(record) {
+				reordered[j] = record[pos]
+			}
+		}
+		records[i] = reordered
+	}
+	if err := ops.writeRecords(records, dialect); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully reordered the columns of %s: %s\n", ops.FilePath, strings.Join(ops.originalHeaders(order), ","))
+	return nil
+}
+
+// transformRows loads the raw rows, reorders them with fn and writes the file back in its own dialect
+func (ops *CSVOperations) transformRows(fn func([][]string) [][]string, verb string) error {
+	data, err := os.ReadFile(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return err
+	}
+	if len(records) < 2 {
+		fmt.Println("No rows to transform.")
+		return nil
+	}
+
+	rows := fn(append([][]string{}, records[1:]...))
+	if err := ops.writeRecords(append([][]string{records[0]}, rows...), dialect); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully %s %d rows\n", verb, len(rows))
+	return nil
+}
+
+// writeRecords writes raw records (header first) back to the input file in the given dialect,
+// dropping the synthetic header of headerless files
+func (ops *CSVOperations) writeRecords(records [][]string, dialect Dialect) error {
+	if err := sourceWriteError(ops.FilePath, dialect); err != nil {
+		return err
+	}
+	if !dialect.HasHeader && len(records) > 0 {
+		records = records[1:]
+	} else if len(records) > 0 {
+		records = append([][]string{ops.originalHeaders(records[0])}, records[1:]...)
+	}
+
+	return writeEncoded(ops.FilePath, records, dialect)
+}
@@ -2,12 +2,18 @@ package operations
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-gota/gota/dataframe"
 	"github.com/go-gota/gota/series"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
 )
 
+// functionCallPattern matches update values written as a function call, such as UPPER(name)
+var functionCallPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*\(.*\)$`)
+
 // Update modifies existing rows based on WHERE condition
 func (ops *CSVOperations) Update(updateVals, whereCond string) error {
 	if updateVals == "" {
@@ -23,6 +29,16 @@ func (ops *CSVOperations) Update(updateVals, whereCond string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse UPDATE values: %v", err)
 	}
+	return ops.UpdateWhere(updates, []string{whereCond})
+}
+
+// UpdateWhere sets the updated columns on the rows matching every condition, which may use the
+// full WHERE syntax of SELECT: AND, OR, NOT, parentheses, IN, LIKE, BETWEEN and functions
+func (ops *CSVOperations) UpdateWhere(updates map[string]string, whereConditions []string) error {
+	whereCond := joinConditions(whereConditions)
+	if whereCond == "" {
+		return fmt.Errorf("UPDATE requires WHERE condition to prevent accidental mass updates")
+	}
 
 	// Validate update columns
 	updateColumns := make([]string, 0, len(updates))
@@ -33,20 +49,25 @@ func (ops *CSVOperations) Update(updateVals, whereCond string) error {
 		return fmt.Errorf("UPDATE validation failed: %v", err)
 	}
 
-	// Apply WHERE condition to find rows to update
+	if ops.DataFrame.Nrow() == 0 {
+		ops.reportMutation("UPDATE", 0, 0, "No rows match the WHERE condition. No updates performed.")
+		return nil
+	}
+
+	// Find the rows to update; a condition that cannot be evaluated changes nothing
 	df := ops.DataFrame
-	filteredDF, err := ops.ApplyWhereCondition(df, whereCond)
+	matching, err := ops.MatchingRows(df, whereCond)
 	if err != nil {
 		return fmt.Errorf("WHERE condition error: %v", err)
 	}
 
-	if filteredDF.Nrow() == 0 {
-		fmt.Println("No rows match the WHERE condition. No updates performed.")
+	if len(matching) == 0 {
+		ops.reportMutation("UPDATE", 0, 0, "No rows match the WHERE condition. No updates performed.")
 		return nil
 	}
 
 	// Perform the update
-	updatedDF, rowsAffected, err := ops.PerformUpdate(df, filteredDF, updates, whereCond)
+	updatedDF, rowsAffected, err := ops.PerformUpdate(df, updates, whereCond)
 	if err != nil {
 		return fmt.Errorf("failed to perform update: %v", err)
 	}
@@ -55,17 +76,45 @@ func (ops *CSVOperations) Update(updateVals, whereCond string) error {
 	if err := ops.SaveDataFrameToCSV(updatedDF, ops.FilePath); err != nil {
 		return fmt.Errorf("failed to save updated CSV: %v", err)
 	}
+	if err := ops.returnRows(updatedDF.Subset(matching)); err != nil {
+		return err
+	}
 
-	fmt.Printf("Successfully updated %d rows in %s\n", rowsAffected, ops.FilePath)
+	// Matched rows that already held the new values are not counted as modified
+	modified := 0
+	for _, row := range matching {
+		if !slices.Equal(RowValues(df, row), RowValues(updatedDF, row)) {
+			modified++
+		}
+	}
+	ops.reportMutation("UPDATE", len(matching), modified, fmt.Sprintf("Successfully updated %d rows in %s", rowsAffected, ops.Workspace.Describe(ops.FilePath)))
 	return nil
 }
 
+// joinConditions combines WHERE conditions with AND, parenthesizing each so OR inside one
+// condition cannot bind to its neighbours
+func joinConditions(conditions []string) string {
+	var parts []string
+	for _, cond := range conditions {
+		if cond = strings.TrimSpace(cond); cond != "" {
+			parts = append(parts, cond)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, part := range parts {
+		parts[i] = "(" + part + ")"
+	}
+	return strings.Join(parts, " AND ")
+}
+
 // ParseUpdateValues parses UPDATE values in format "col1=val1,col2=val2"
 func (ops *CSVOperations) ParseUpdateValues(updateVals string) (map[string]string, error) {
 	updates := make(map[string]string)
 	
-	// Split by comma to get individual column assignments
-	assignments := strings.Split(updateVals, ",")
+	// Split by comma to get individual column assignments (commas inside function calls or quotes are kept)
+	assignments := splitTopLevel(updateVals)
 	
 	for _, assignment := range assignments {
 		assignment = strings.TrimSpace(assignment)
@@ -89,14 +138,24 @@ func (ops *CSVOperations) ParseUpdateValues(updateVals string) (map[string]strin
 }
 
 // PerformUpdate executes the actual update operation
-func (ops *CSVOperations) PerformUpdate(originalDF, filteredDF dataframe.DataFrame, updates map[string]string, whereCond string) (dataframe.DataFrame, int, error) {
+func (ops *CSVOperations) PerformUpdate(originalDF dataframe.DataFrame, updates map[string]string, whereCond string) (dataframe.DataFrame, int, error) {
 	rowsAffected := 0
 	
 	// Create a copy of the original dataframe for modification
 	updatedDF := originalDF.Copy()
+
+	// Values such as LOWER(email) are evaluated per row against the original values
+	exprs, err := parseUpdateExprs(updates)
+	if err != nil {
+		return originalDF, 0, err
+	}
+	evaluator := ops.evaluator(originalDF)
 	
 	// Get indices of rows that match the WHERE condition
-	matchingIndices := ops.GetMatchingRowIndices(originalDF, whereCond)
+	matchingIndices, err := ops.MatchingRows(originalDF, whereCond)
+	if err != nil {
+		return originalDF, 0, err
+	}
 	
 	// Update each matching row
 	for _, rowIndex := range matchingIndices {
@@ -112,6 +171,17 @@ func (ops *CSVOperations) PerformUpdate(originalDF, filteredDF dataframe.DataFra
 			}
 			
 			if columnIndex >= 0 {
+				if expr, ok := exprs[column]; ok {
+					value, err := evaluator.Eval(expr, rowIndex)
+					if err != nil {
+						return originalDF, 0, err
+					}
+					newValue = formatValue(value)
+				}
+				if err := ops.checkAllowed(column, newValue); err != nil {
+					return originalDF, 0, err
+				}
+
 				// Update the value in the dataframe
 				updatedDF = ops.UpdateCellValue(updatedDF, rowIndex, columnIndex, newValue)
 				rowsAffected++
@@ -125,54 +195,54 @@ func (ops *CSVOperations) PerformUpdate(originalDF, filteredDF dataframe.DataFra
 	return updatedDF, rowsAffected, nil
 }
 
-// GetMatchingRowIndices returns indices of rows that match the WHERE condition
-func (ops *CSVOperations) GetMatchingRowIndices(df dataframe.DataFrame, whereCond string) []int {
-	filteredDF, err := ops.ApplyWhereCondition(df, whereCond)
-	if err != nil {
-		return []int{}
-	}
-	
-	var indices []int
-	
-	// This is a simplified approach - in a production system you'd want 
-	// more efficient indexing
-	for i := 0; i < df.Nrow(); i++ {
-		// Check if this row exists in the filtered dataframe
-		if ops.RowExistsInFiltered(df, filteredDF, i) {
-			indices = append(indices, i)
+// parseUpdateExprs parses the update values written as function calls, e.g. email=LOWER(email)
+func parseUpdateExprs(updates map[string]string) (map[string]sqlparser.Expr, error) {
+	exprs := make(map[string]sqlparser.Expr)
+	for column, value := range updates {
+		if !functionCallPattern.MatchString(value) {
+			continue
+		}
+		expr, err := sqlparser.ParseExpr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for %s: %v", column, err)
 		}
+		exprs[column] = expr
 	}
-	
-	return indices
+	return exprs, nil
 }
 
-// RowExistsInFiltered checks if a row from original DF exists in filtered DF
-func (ops *CSVOperations) RowExistsInFiltered(originalDF, filteredDF dataframe.DataFrame, rowIndex int) bool {
-	if rowIndex >= originalDF.Nrow() {
-		return false
+// rowIndexColumn is a temporary column holding each row's position while a WHERE condition
+// filters the rows
+const rowIndexColumn = "\x00row"
+
+// MatchingRows returns the indices of the rows of df that satisfy the WHERE condition. Rows
+// are tracked by position rather than by their values, so a condition changes exactly the
+// rows it selects even when other rows hold the same values.
+func (ops *CSVOperations) MatchingRows(df dataframe.DataFrame, whereCond string) ([]int, error) {
+	if df.Nrow() == 0 {
+		return nil, nil
 	}
-	
-	// Create signature of the row to match
-	originalRow := make([]string, originalDF.Ncol())
-	for j := 0; j < originalDF.Ncol(); j++ {
-		originalRow[j] = fmt.Sprintf("%v", originalDF.Elem(rowIndex, j))
+	positions := make([]int, df.Nrow())
+	for i := range positions {
+		positions[i] = i
 	}
-	
-	// Check if this row signature exists in filtered dataframe
-	for i := 0; i < filteredDF.Nrow(); i++ {
-		match := true
-		for j := 0; j < filteredDF.Ncol(); j++ {
-			if fmt.Sprintf("%v", filteredDF.Elem(i, j)) != originalRow[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return true
-		}
+	filteredDF, err := ops.ApplyWhereCondition(df.Mutate(series.New(positions, series.Int, rowIndexColumn)), whereCond)
+	if err != nil {
+		return nil, err
 	}
-	
-	return false
+	if filteredDF.Nrow() == 0 {
+		return nil, nil
+	}
+	return filteredDF.Col(rowIndexColumn).Int()
+}
+
+// GetMatchingRowIndices returns indices of rows that match the WHERE condition
+func (ops *CSVOperations) GetMatchingRowIndices(df dataframe.DataFrame, whereCond string) []int {
+	indices, err := ops.MatchingRows(df, whereCond)
+	if err != nil {
+		return []int{}
+	}
+	return indices
 }
 
 // UpdateCellValue updates a specific cell in the dataframe
@@ -188,7 +258,7 @@ func (ops *CSVOperations) UpdateCellValue(df dataframe.DataFrame, rowIndex, colI
 			if i == rowIndex && j == colIndex {
 				columnData[i] = newValue
 			} else {
-				columnData[i] = fmt.Sprintf("%v", df.Elem(i, j))
+				columnData[i] = CellText(df.Elem(i, j))
 			}
 		}
 		allData[j] = columnData
@@ -203,13 +273,6 @@ func (ops *CSVOperations) UpdateCellValue(df dataframe.DataFrame, rowIndex, colI
 	return dataframe.New(seriesList...)
 }
 
-// UpdateWhere performs UPDATE with complex WHERE conditions (future enhancement)
-func (ops *CSVOperations) UpdateWhere(updates map[string]string, whereConditions []string) error {
-	// This could support multiple WHERE conditions with AND/OR logic
-	// For now, we use the simpler single-condition approach
-	return fmt.Errorf("complex WHERE conditions not yet implemented")
-}
-
 // BulkUpdate performs multiple updates in a single operation (future enhancement)
 func (ops *CSVOperations) BulkUpdate(bulkUpdates []struct {
 	Updates   map[string]string
@@ -219,7 +282,7 @@ func (ops *CSVOperations) BulkUpdate(bulkUpdates []struct {
 	df := ops.DataFrame
 	
 	for i, update := range bulkUpdates {
-		updatedDF, rowsAffected, err := ops.PerformUpdate(df, dataframe.DataFrame{}, update.Updates, update.Condition)
+		updatedDF, rowsAffected, err := ops.PerformUpdate(df, update.Updates, update.Condition)
 		if err != nil {
 			return fmt.Errorf("bulk update %d failed: %v", i+1, err)
 		}
@@ -232,6 +295,6 @@ func (ops *CSVOperations) BulkUpdate(bulkUpdates []struct {
 		return fmt.Errorf("failed to save bulk updated CSV: %v", err)
 	}
 	
-	fmt.Printf("Successfully performed bulk update affecting %d total rows in %s\n", totalRowsAffected, ops.FilePath)
+	fmt.Printf("Successfully performed bulk update affecting %d total rows in %s\n", totalRowsAffected, ops.Workspace.Describe(ops.FilePath))
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package operations
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// DisplayWidth returns the number of terminal cells text occupies. Wide characters such as
+// CJK and emoji take two cells and combining marks none; an emoji with a skin tone modifier or
+// a ZWJ sequence such as a family is one two-cell character.
+func DisplayWidth(text string) int {
+	return runewidth.StringWidth(text)
+}
+
+// padRight pads text with spaces to width terminal cells; longer text is returned unchanged
+func padRight(text string, width int) string {
+	if gap := width - DisplayWidth(text); gap > 0 {
+		return text + strings.Repeat(" ", gap)
+	}
+	return text
+}
@@ -0,0 +1,225 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// tailBlockSize is how many bytes are read per step when scanning backwards from the end of a file
+const tailBlockSize = 64 * 1024
+
+// InitializeHead loads only the header and the first n data rows of the file
+func (ops *CSVOperations) InitializeHead(n int) error {
+	file, _, err := openInput(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	reader, dialect, err := ops.openStream(file)
+	if err != nil {
+		return err
+	}
+	if reader == nil {
+		return ops.initializeWindow(func(rows [][]string) [][]string { return rows[:min(n, len(rows))] })
+	}
+
+	var records [][]string
+	for len(records) < n+1 {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %v", err)
+		}
+		records = append(records, record)
+	}
+	if !dialect.HasHeader && len(records) > 0 {
+		names, err := ops.headerlessNames(len(records[0]))
+		if err != nil {
+			return err
+		}
+		records = append([][]string{names}, records[:min(n, len(records))]...)
+	}
+	return ops.loadWindow(records, dialect)
+}
+
+// InitializeTail loads only the header and the last n data rows of the file. The file is read
+// backwards from the end in blocks; a newline ends a record when an even number of quote
+// characters follows it, so quoted multi-line values are kept intact.
+func (ops *CSVOperations) InitializeTail(n int) error {
+	file, err := os.Open(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	reader, dialect, err := ops.openStream(file)
+	if err != nil {
+		return err
+	}
+	// Compressed files cannot be read backwards
+	if reader == nil || IsCompressed(ops.FilePath) {
+		return ops.initializeWindow(func(rows [][]string) [][]string { return rows[max(len(rows)-n, 0):] })
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return ops.loadWindow(nil, dialect)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CSV: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+
+	start, ok, err := tailOffset(file, info.Size(), n, byte(dialect.Quote))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// The file has at most n rows, so read it from the start
+		return ops.initializeWindow(func(rows [][]string) [][]string { return rows[max(len(rows)-n, 0):] })
+	}
+
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read CSV: %v", err)
+	}
+	tailReader := csv.NewReader(bufio.NewReader(file))
+	tailReader.Comma = dialect.Delimiter
+	tailReader.LazyQuotes = dialect.LazyQuotes
+	tailReader.FieldsPerRecord = len(header)
+	rows, err := tailReader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV: %v", err)
+	}
+
+	if !dialect.HasHeader {
+		if header, err = ops.headerlessNames(len(header)); err != nil {
+			return err
+		}
+	}
+	return ops.loadWindow(append([][]string{header}, rows...), dialect)
+}
+
+// tailOffset finds the byte offset where the last n records start. It reports false when the
+// start of the file is reached first, i.e. the file has fewer than n records after the first line.
+func tailOffset(file *os.File, size int64, n int, quote byte) (int64, bool, error) {
+	// A trailing newline terminates the last record rather than starting a new one
+	end := size
+	last := make([]byte, 1)
+	if size > 0 {
+		if _, err := file.ReadAt(last, size-1); err != nil {
+			return 0, false, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		if last[0] == '\n' {
+			end--
+		}
+	}
+
+	quotes, found := 0, 0
+	block := make([]byte, tailBlockSize)
+	for pos := end; pos > 0; {
+		size := int64(tailBlockSize)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+		if _, err := file.ReadAt(block[:size], pos); err != nil && err != io.EOF {
+			return 0, false, fmt.Errorf("failed to read CSV: %v", err)
+		}
+
+		for i := size - 1; i >= 0; i-- {
+			switch block[i] {
+			case quote:
+				quotes++
+			case '\n':
+				if quotes%2 == 0 {
+					found++
+					if found == n {
+						return pos + i + 1, true, nil
+					}
+				}
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+// openStream sniffs the dialect and returns a CSV reader positioned at the start of the file.
+// It returns a nil reader when the file cannot be streamed (non UTF-8 encodings, custom quotes,
+// locale conversion, skipped lines or HAR/Burp captures) or the no-stream hint is set, in which
+// case callers load it whole.
+func (ops *CSVOperations) openStream(file io.Reader) (*csv.Reader, Dialect, error) {
+	buffered := bufio.NewReaderSize(file, SniffSampleSize)
+	sample, _ := buffered.Peek(SniffSampleSize)
+	dialect, err := ops.DetectDialect(sample)
+	if err != nil {
+		return nil, dialect, err
+	}
+
+	streamable := (dialect.Encoding == "utf-8" || dialect.Encoding == "utf-8-bom") && dialect.Quote == '"' && dialect.Escape == 0 && !ops.toleratesBadRows() && ops.Locale == "" && !ops.skipsLines() && dialect.Source == ""
+	if !streamable && ops.Hints[HintStream] {
+		return nil, dialect, fmt.Errorf("stream hint: file cannot be streamed (requires UTF-8 CSV, double-quote quoting without an escape character and no -locale, -skip-rows, -comment or -on-bad-rows)")
+	}
+	if !streamable || ops.Hints[HintNoStream] {
+		return nil, dialect, nil
+	}
+	if bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = dialect.Delimiter
+	reader.LazyQuotes = dialect.LazyQuotes
+	return reader, dialect, nil
+}
+
+// initializeWindow loads the whole file and keeps the data rows selected by fn
+func (ops *CSVOperations) initializeWindow(fn func([][]string) [][]string) error {
+	data, err := readInput(ops.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	records, dialect, err := ops.ReadRecords(data)
+	if err != nil {
+		return err
+	}
+	if len(records) > 0 {
+		records = append([][]string{records[0]}, fn(records[1:])...)
+	}
+	return ops.loadWindow(records, dialect)
+}
+
+// loadWindow installs a partial set of records as the operation's dataframe
+func (ops *CSVOperations) loadWindow(records [][]string, dialect Dialect) error {
+	ops.Dialect = dialect
+	if len(records) == 0 {
+		return nil
+	}
+	header, err := ops.readHeader(records[0])
+	if err != nil {
+		return err
+	}
+	records[0] = header
+	records = ops.projectRecords(records)
+	options, err := ops.loadOptions(records[0])
+	if err != nil {
+		return err
+	}
+	df, err := RecordsToDataFrame(records, options...)
+	if err != nil {
+		return err
+	}
+	ops.DataFrame = df
+	ops.Headers = df.Names()
+	return ops.checkRenames()
+}
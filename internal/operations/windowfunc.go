@@ -0,0 +1,281 @@
+package operations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/saeed0xf/seesv/internal/sqlparser"
+)
+
+// windowFunctions are the functions accepted before OVER (...)
+var windowFunctions = []string{"ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD"}
+
+// computeWindows evaluates every window function of expr over all rows of the evaluator's
+// dataframe, before any row is evaluated, so that Eval can look up each row's value
+func (e *Evaluator) computeWindows(expr sqlparser.Expr) error {
+	var err error
+	sqlparser.Walk(expr, func(node sqlparser.Expr) {
+		call, ok := node.(*sqlparser.FuncCall)
+		if !ok || call.Over == nil || err != nil {
+			return
+		}
+		if _, done := e.windows[call]; done {
+			return
+		}
+		var values []interface{}
+		if values, err = e.windowValues(call); err == nil {
+			e.windows[call] = values
+		}
+	})
+	return err
+}
+
+// windowValues computes a window function for every row. Rows are split into partitions by
+// PARTITION BY and ordered within each by ORDER BY; without ORDER BY they keep file order.
+func (e *Evaluator) windowValues(call *sqlparser.FuncCall) ([]interface{}, error) {
+	if indexOf(windowFunctions, call.Name) < 0 {
+		return nil, fmt.Errorf("unsupported window function: %s (use %s)", call.Name, strings.Join(windowFunctions, ", "))
+	}
+	for _, arg := range call.Args {
+		nested := false
+		sqlparser.Walk(arg, func(node sqlparser.Expr) {
+			if inner, ok := node.(*sqlparser.FuncCall); ok && inner.Over != nil {
+				nested = true
+			}
+		})
+		if nested {
+			return nil, fmt.Errorf("window functions cannot be nested")
+		}
+	}
+
+	offset := 1
+	switch call.Name {
+	case "LAG", "LEAD":
+		if len(call.Args) < 1 || len(call.Args) > 3 {
+			return nil, fmt.Errorf("%s expects a value, an optional offset and an optional default", call.Name)
+		}
+		if len(call.Args) > 1 {
+			lit, ok := call.Args[1].(*sqlparser.Literal)
+			if !ok || lit.Kind != sqlparser.NumberLiteral {
+				return nil, fmt.Errorf("%s offset must be a non-negative integer", call.Name)
+			}
+			n, err := strconv.Atoi(lit.Value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s offset must be a non-negative integer", call.Name)
+			}
+			offset = n
+		}
+	default:
+		if len(call.Args) != 0 {
+			return nil, fmt.Errorf("%s() takes no arguments", call.Name)
+		}
+	}
+
+	partitions, orderKeys, err := e.windowPartitions(call.Over)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, e.df.Nrow())
+	for _, rows := range partitions {
+		for pos, row := range rows {
+			switch call.Name {
+			case "ROW_NUMBER":
+				values[row] = float64(pos + 1)
+
+			case "RANK", "DENSE_RANK":
+				// Rows tied on every ORDER BY key share a rank; RANK leaves gaps after ties
+				switch {
+				case pos == 0:
+					values[row] = float64(1)
+				case compareOrderKeys(orderKeys[rows[pos-1]], orderKeys[row], call.Over.OrderBy) == 0:
+					values[row] = values[rows[pos-1]]
+				case call.Name == "RANK":
+					values[row] = float64(pos + 1)
+				default:
+					values[row] = values[rows[pos-1]].(float64) + 1
+				}
+
+			case "LAG", "LEAD":
+				other := pos - offset
+				if call.Name == "LEAD" {
+					other = pos + offset
+				}
+				var value interface{}
+				var err error
+				if other >= 0 && other < len(rows) {
+					value, err = e.Eval(call.Args[0], rows[other])
+				} else if len(call.Args) == 3 {
+					value, err = e.Eval(call.Args[2], row)
+				}
+				if err != nil {
+					return nil, err
+				}
+				values[row] = value
+			}
+		}
+	}
+	return values, nil
+}
+
+// windowPartitions groups the row indices by their PARTITION BY values and sorts each group
+// by the ORDER BY keys, which are returned by row
+func (e *Evaluator) windowPartitions(spec *sqlparser.WindowSpec) ([][]int, [][]interface{}, error) {
+	n := e.df.Nrow()
+	index := make(map[string]int)
+	var partitions [][]int
+	for row := 0; row < n; row++ {
+		parts := make([]string, len(spec.PartitionBy))
+		for i, expr := range spec.PartitionBy {
+			value, err := e.Eval(expr, row)
+			if err != nil {
+				return nil, nil, err
+			}
+			// NULL gets its own partition, apart from empty text
+			parts[i] = "\x00"
+			if value != nil {
+				parts[i] = membershipKey(value)
+			}
+		}
+		key := strings.Join(parts, "\x1f")
+		pos, ok := index[key]
+		if !ok {
+			pos = len(partitions)
+			index[key] = pos
+			partitions = append(partitions, nil)
+		}
+		partitions[pos] = append(partitions[pos], row)
+	}
+
+	orderKeys := make([][]interface{}, n)
+	if len(spec.OrderBy) == 0 {
+		return partitions, orderKeys, nil
+	}
+	for row := 0; row < n; row++ {
+		orderKeys[row] = make([]interface{}, len(spec.OrderBy))
+		for i, item := range spec.OrderBy {
+			value, err := e.Eval(item.Expr, row)
+			if err != nil {
+				return nil, nil, err
+			}
+			orderKeys[row][i] = value
+		}
+	}
+	for _, rows := range partitions {
+		sort.SliceStable(rows, func(a, b int) bool {
+			return compareOrderKeys(orderKeys[rows[a]], orderKeys[rows[b]], spec.OrderBy) < 0
+		})
+	}
+	return partitions, orderKeys, nil
+}
+
+// compareOrderKeys compares two rows' ORDER BY values key by key. NULLs sort after every
+// other value in either direction.
+func compareOrderKeys(a, b []interface{}, items []sqlparser.OrderItem) int {
+	for i, item := range items {
+		var cmp int
+		switch {
+		case a[i] == nil && b[i] == nil:
+			continue
+		case a[i] == nil:
+			return 1
+		case b[i] == nil:
+			return -1
+		default:
+			cmp = compareValues(a[i], b[i])
+		}
+		if item.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// applyQualify keeps the rows of result, the projection of df by items, for which the QUALIFY
+// condition holds on df. The condition may use window functions and the aliases of the select
+// list, as in QUALIFY rn <= 3; windows see every row of df, before any row is dropped.
+func (ops *CSVOperations) applyQualify(df, result dataframe.DataFrame, qualify sqlparser.Expr, items []sqlparser.SelectItem) (dataframe.DataFrame, error) {
+	evaluator := ops.evaluator(df)
+	for _, item := range items {
+		if item.Alias != "" && indexOf(df.Names(), item.Alias) < 0 {
+			evaluator.aliases[item.Alias] = item.Expr
+			if err := evaluator.computeWindows(item.Expr); err != nil {
+				return result, fmt.Errorf("QUALIFY error: %v", err)
+			}
+		}
+	}
+	if err := evaluator.computeWindows(qualify); err != nil {
+		return result, fmt.Errorf("QUALIFY error: %v", err)
+	}
+
+	var rows []int
+	for i := 0; i < df.Nrow(); i++ {
+		value, err := evaluator.Eval(qualify, i)
+		if err != nil {
+			return result, fmt.Errorf("QUALIFY error: %v", err)
+		}
+		if value != nil && truthy(value) {
+			rows = append(rows, i)
+		}
+	}
+	if len(rows) == 0 {
+		return NewStringDataFrame(result.Names(), nil), nil
+	}
+	return result.Subset(rows), nil
+}
+
+// SelectTopPerGroup selects the first n rows of each group in orderBy order after whereCond,
+// such as the three highest-revenue rows per category. Groups keep the order in which they
+// first appear; without groupBy the whole file is one group.
+func (ops *CSVOperations) SelectTopPerGroup(selectCols, whereCond, groupBy, orderBy string, n, limit int) error {
+	if orderBy == "" {
+		return fmt.Errorf("-top-per-group requires -order to rank the rows of each group")
+	}
+	spec := &sqlparser.WindowSpec{}
+	var groupCols []string
+	if groupBy != "" {
+		groupCols = ops.ParseColumns(groupBy)
+	}
+	for _, column := range groupCols {
+		spec.PartitionBy = append(spec.PartitionBy, &sqlparser.ColumnRef{Name: column})
+	}
+	keys, err := orderByKeys(orderBy)
+	if err != nil {
+		return err
+	}
+	columns := groupCols
+	for _, key := range keys {
+		spec.OrderBy = append(spec.OrderBy, sqlparser.OrderItem{Expr: &sqlparser.ColumnRef{Name: key.Column}, Desc: key.Desc})
+		columns = append(columns, key.Column)
+	}
+	if err := ops.ValidateColumns(columns); err != nil {
+		return err
+	}
+
+	filteredDF, err := ops.ApplyWhereCondition(ops.DataFrame, whereCond)
+	if err != nil {
+		return fmt.Errorf("WHERE condition error: %v", err)
+	}
+	partitions, _, err := ops.evaluator(filteredDF).windowPartitions(spec)
+	if err != nil {
+		return err
+	}
+	var indices []int
+	for _, rows := range partitions {
+		indices = append(indices, rows[:min(n, len(rows))]...)
+	}
+
+	// The chosen rows are then selected like any other input, already in group order
+	if len(indices) == 0 {
+		ops.DataFrame = NewStringDataFrame(filteredDF.Names(), nil)
+	} else {
+		ops.DataFrame = filteredDF.Subset(indices)
+	}
+	return ops.Select(selectCols, "", "", "", "", limit)
+}
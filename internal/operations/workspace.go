@@ -0,0 +1,343 @@
+package operations
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// workspaceTableName is the form of -save-as names, which must read back as a FROM table
+var workspaceTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Workspace is a SQLite database that keeps tables across runs. A command works on a table
+// through a scratch CSV copy, so every operation that reads or rewrites files works on tables
+// too; Close stores the copies that changed back into the database.
+type Workspace struct {
+	Path      string
+	db        *sql.DB
+	dir       string                       // dir holds the scratch copies of tables
+	mu        sync.Mutex                   // mu guards checkouts, since Close may run while a timed-out command still reads
+	checkouts map[string][sha256.Size]byte // checkouts are the tables copied to dir, with the hash of each copy
+}
+
+// OpenWorkspace opens the SQLite database at path as the workspace, creating it if needed
+func OpenWorkspace(path string) (*Workspace, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("workspace %s is a directory, not a SQLite database", path)
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workspace: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open workspace: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "seesv-workspace-")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open workspace: %v", err)
+	}
+	return &Workspace{Path: path, db: db, dir: dir, checkouts: make(map[string][sha256.Size]byte)}, nil
+}
+
+// Close stores the tables whose scratch copies changed, such as by -update or -save-as, then
+// closes the database
+func (ws *Workspace) Close() error {
+	if ws == nil {
+		return nil
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	defer ws.db.Close()
+	defer os.RemoveAll(ws.dir)
+
+	for name, checksum := range ws.checkouts {
+		data, err := os.ReadFile(ws.copyPath(name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store table %s: %v", name, err)
+		}
+		if sha256.Sum256(data) == checksum {
+			continue
+		}
+		if err := ws.store(name, data); err != nil {
+			return fmt.Errorf("failed to store table %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// TablePath returns the scratch file that -save-as writes the table name to
+func (ws *Workspace) TablePath(name string) (string, error) {
+	if ws == nil {
+		return "", fmt.Errorf("no workspace is open (use -workspace)")
+	}
+	if !workspaceTableName.MatchString(name) {
+		return "", fmt.Errorf("invalid table name: %s (use letters, digits and underscores)", name)
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if _, ok := ws.checkouts[name]; !ok {
+		ws.checkouts[name] = [sha256.Size]byte{}
+	}
+	return ws.copyPath(name), nil
+}
+
+// ResolveTablePath maps a FROM table name to a CSV file path: a copy of the workspace table
+// (ws may be nil when no workspace is open), then name, then name.csv
+func (ws *Workspace) ResolveTablePath(name string) (string, error) {
+	if path, ok, err := ws.checkout(name); ok || err != nil {
+		return path, err
+	}
+	for _, candidate := range []string{name, name + ".csv"} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("table '%s' not found (looked for %s and %s.csv)", name, name, name)
+}
+
+// checkout copies the workspace table name to a scratch CSV file, reporting false when the
+// workspace has no such table
+func (ws *Workspace) checkout(name string) (string, bool, error) {
+	if ws == nil || !workspaceTableName.MatchString(name) {
+		return "", false, nil
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	path := ws.copyPath(name)
+	if _, ok := ws.checkouts[name]; ok {
+		return path, true, nil
+	}
+	if exists, err := ws.hasTable(name); err != nil || !exists {
+		return "", false, err
+	}
+
+	records, err := ws.tableRecords(name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read table %s: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return "", false, fmt.Errorf("failed to read table %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", false, fmt.Errorf("failed to read table %s: %v", name, err)
+	}
+	ws.checkouts[name] = sha256.Sum256(buf.Bytes())
+	return path, true, nil
+}
+
+func (ws *Workspace) copyPath(name string) string {
+	return filepath.Join(ws.dir, name+".csv")
+}
+
+// tableNames returns the tables of the workspace in name order
+func (ws *Workspace) tableNames() ([]string, error) {
+	rows, err := ws.db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (ws *Workspace) hasTable(name string) (bool, error) {
+	var count int
+	err := ws.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to read workspace: %v", err)
+	}
+	return count > 0, nil
+}
+
+// tableRecords reads a table as records, header first; NULL becomes an empty cell
+func (ws *Workspace) tableRecords(name string) ([][]string, error) {
+	rows, err := ws.db.Query("SELECT * FROM " + quoteIdentifier(name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	records := [][]string{header}
+	values := make([]sql.NullString, len(header))
+	targets := make([]interface{}, len(header))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = value.String
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// store replaces the table name with the CSV data of its scratch copy. Copies are written by
+// seesv as comma separated CSV with a header, so they are read as such rather than sniffed.
+func (ws *Workspace) store(name string, data []byte) error {
+	records, err := parseRecords(strings.TrimPrefix(string(data), "\ufeff"), DefaultDialect(), 0)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("the table has no columns")
+	}
+
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DROP TABLE IF EXISTS " + quoteIdentifier(name)); err != nil {
+		return err
+	}
+	if err := createSQLiteTable(tx, name, records); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createSQLiteTable creates the table name and inserts the records, header first. Columns are
+// INTEGER or REAL when every value is a number that reads back exactly as written, and TEXT
+// otherwise, so leading zeros and trailing decimals survive. Empty cells are NULL.
+func createSQLiteTable(tx *sql.Tx, name string, records [][]string) error {
+	header := records[0]
+	columns := make([]string, len(header))
+	for j, column := range header {
+		columns[j] = quoteIdentifier(column) + " " + sqliteColumnType(records[1:], j)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(name), strings.Join(columns, ", "))); err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(header)), ", ")
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdentifier(name), placeholders))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]interface{}, len(header))
+	for _, record := range records[1:] {
+		for j := range values {
+			values[j] = nil
+			if j < len(record) && record[j] != "" {
+				values[j] = record[j]
+			}
+		}
+		if _, err := insert.Exec(values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteColumnType picks the declared type of column j of rows (see createSQLiteTable)
+func sqliteColumnType(rows [][]string, j int) string {
+	columnType := ""
+	for _, row := range rows {
+		if j >= len(row) || row[j] == "" {
+			continue
+		}
+		value := row[j]
+		valueType := "TEXT"
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(n, 10) == value {
+			valueType = "INTEGER"
+		} else if f, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(f, 'f', -1, 64) == value {
+			valueType = "REAL"
+		}
+		switch {
+		case columnType == "" || columnType == valueType:
+			columnType = valueType
+		case columnType != "TEXT" && valueType != "TEXT":
+			columnType = "REAL"
+		default:
+			return "TEXT"
+		}
+	}
+	if columnType == "" {
+		return "TEXT"
+	}
+	return columnType
+}
+
+// quoteIdentifier quotes a table or column name for SQLite
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ListWorkspace prints the tables of the workspace with their size
+func (ops *CSVOperations) ListWorkspace() error {
+	ws := ops.Workspace
+	if ws == nil {
+		return fmt.Errorf("tables requires -workspace with the workspace database")
+	}
+	names, err := ws.tableNames()
+	if err != nil {
+		return fmt.Errorf("failed to list workspace: %v", err)
+	}
+
+	var rows [][]string
+	for _, name := range names {
+		var count int
+		if err := ws.db.QueryRow("SELECT COUNT(*) FROM " + quoteIdentifier(name)).Scan(&count); err != nil {
+			return fmt.Errorf("table %s: %v", name, err)
+		}
+		columns, err := ws.db.Query("SELECT * FROM " + quoteIdentifier(name) + " LIMIT 0")
+		if err != nil {
+			return fmt.Errorf("table %s: %v", name, err)
+		}
+		header, err := columns.Columns()
+		columns.Close()
+		if err != nil {
+			return fmt.Errorf("table %s: %v", name, err)
+		}
+		rows = append(rows, []string{name, strconv.Itoa(count), strconv.Itoa(len(header))})
+	}
+
+	if len(rows) == 0 && !ops.RawOutput {
+		fmt.Printf("Workspace %s has no tables yet (store one with -save-as)\n", ws.Path)
+		return nil
+	}
+	return ops.PrintDataFrame(NewStringDataFrame([]string{"table", "rows", "columns"}, rows))
+}
+
+// Describe names path for messages: the table it holds when it is a scratch copy, else path
+func (ws *Workspace) Describe(path string) string {
+	if ws == nil || filepath.Dir(path) != ws.dir {
+		return path
+	}
+	return fmt.Sprintf("table %s of %s", strings.TrimSuffix(filepath.Base(path), ".csv"), ws.Path)
+}
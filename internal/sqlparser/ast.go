@@ -0,0 +1,475 @@
+// Package sqlparser parses the SQL subset understood by seesv into an AST
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statement is any parsed SQL statement
+type Statement interface {
+	statementNode()
+	String() string
+}
+
+// Expr is any SQL expression
+type Expr interface {
+	exprNode()
+	String() string
+}
+
+// SelectStatement represents SELECT ... FROM ... WHERE ... ORDER BY ... LIMIT ...
+type SelectStatement struct {
+	Distinct bool
+	Columns  []SelectItem
+	From     TableRef
+	Joins    []JoinClause
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	Qualify  Expr // Qualify filters rows on window functions, e.g. ROW_NUMBER() OVER (...) <= 3
+	OrderBy  []OrderItem
+	Limit    int // Limit is 0 when no LIMIT clause is given
+}
+
+// JoinClause joins Table to the tables before it. Type is INNER or LEFT; On is the join
+// condition, e.g. s.id = f.scope_id
+type JoinClause struct {
+	Type  string
+	Table TableRef
+	On    Expr
+}
+
+// CompoundStatement combines the results of several SELECTs. Ops[i] joins the result so far
+// with Selects[i+1] and is UNION, INTERSECT or EXCEPT, each optionally followed by ALL to keep
+// duplicate rows. OrderBy and Limit apply to the combined result.
+type CompoundStatement struct {
+	Selects []*SelectStatement
+	Ops     []string
+	OrderBy []OrderItem
+	Limit   int
+}
+
+// SelectItem is one entry of the select list
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+}
+
+// TableRef names the table (CSV file) a query reads from
+type TableRef struct {
+	Name  string
+	Alias string
+}
+
+// OrderItem is one ORDER BY key
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// ColumnRef references a column, optionally qualified by a table name or alias
+type ColumnRef struct {
+	Table  string
+	Name   string
+	Quoted bool // Quoted is set when the name was written as "name" or `name`
+}
+
+// StarExpr is the * in SELECT * or COUNT(*)
+type StarExpr struct{}
+
+// LiteralKind identifies the type of a literal value
+type LiteralKind int
+
+const (
+	StringLiteral LiteralKind = iota
+	NumberLiteral
+	BoolLiteral
+	NullLiteral
+)
+
+// Literal is a constant value
+type Literal struct {
+	Kind  LiteralKind
+	Value string
+}
+
+// BinaryExpr is a binary operation such as a comparison, arithmetic or AND/OR
+type BinaryExpr struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// UnaryExpr is a prefix operation such as NOT or unary minus
+type UnaryExpr struct {
+	Op   string
+	Expr Expr
+}
+
+// InExpr is expr [NOT] IN (value, ...) or expr [NOT] IN (SELECT ...)
+type InExpr struct {
+	Expr     Expr
+	List     []Expr
+	Subquery Statement // Subquery is set instead of List for IN (SELECT ...)
+	Not      bool
+}
+
+// BetweenExpr is expr [NOT] BETWEEN low AND high, an inclusive range test
+type BetweenExpr struct {
+	Expr Expr
+	Low  Expr
+	High Expr
+	Not  bool
+}
+
+// LikeExpr is expr [NOT] LIKE pattern, where % matches any run of characters and _ a single one
+type LikeExpr struct {
+	Expr    Expr
+	Pattern Expr
+	Not     bool
+}
+
+// CaseExpr is CASE [operand] WHEN ... THEN ... [ELSE ...] END. With an operand each
+// WHEN value is compared to it; without one each WHEN is a condition.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []WhenClause
+	Else    Expr
+}
+
+// WhenClause is a single WHEN ... THEN ... branch of a CASE expression
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// CastExpr is CAST(expr AS type), with Type one of INT, FLOAT, STRING, DATE or BOOL
+type CastExpr struct {
+	Expr Expr
+	Type string
+}
+
+// FuncCall is a function call such as COUNT(*) or UPPER(name). Over is set for window
+// functions such as ROW_NUMBER() OVER (PARTITION BY country ORDER BY revenue DESC).
+type FuncCall struct {
+	Name     string
+	Args     []Expr
+	Distinct bool
+	Over     *WindowSpec
+}
+
+// WindowSpec is the OVER clause of a window function
+type WindowSpec struct {
+	PartitionBy []Expr      // PartitionBy splits the rows into independent partitions
+	OrderBy     []OrderItem // OrderBy orders the rows within each partition
+}
+
+func (*SelectStatement) statementNode()   {}
+func (*CompoundStatement) statementNode() {}
+
+func (*ColumnRef) exprNode()   {}
+func (*StarExpr) exprNode()    {}
+func (*Literal) exprNode()     {}
+func (*BinaryExpr) exprNode()  {}
+func (*UnaryExpr) exprNode()   {}
+func (*InExpr) exprNode()      {}
+func (*BetweenExpr) exprNode() {}
+func (*LikeExpr) exprNode()    {}
+func (*CaseExpr) exprNode()    {}
+func (*CastExpr) exprNode()    {}
+func (*FuncCall) exprNode()    {}
+
+// String renders the statement back as SQL
+func (s *SelectStatement) String() string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	if s.Distinct {
+		b.WriteString("DISTINCT ")
+	}
+	for i, item := range s.Columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(item.String())
+	}
+	if s.From.Name != "" {
+		b.WriteString(" FROM ")
+		b.WriteString(tableName(s.From.Name))
+		if s.From.Alias != "" {
+			b.WriteString(" " + s.From.Alias)
+		}
+	}
+	for _, join := range s.Joins {
+		b.WriteString(" " + join.Type + " JOIN ")
+		b.WriteString(tableName(join.Table.Name))
+		if join.Table.Alias != "" {
+			b.WriteString(" " + join.Table.Alias)
+		}
+		b.WriteString(" ON ")
+		b.WriteString(join.On.String())
+	}
+	if s.Where != nil {
+		b.WriteString(" WHERE ")
+		b.WriteString(s.Where.String())
+	}
+	if len(s.GroupBy) > 0 {
+		b.WriteString(" GROUP BY ")
+		for i, expr := range s.GroupBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(expr.String())
+		}
+	}
+	if s.Having != nil {
+		b.WriteString(" HAVING ")
+		b.WriteString(s.Having.String())
+	}
+	if s.Qualify != nil {
+		b.WriteString(" QUALIFY ")
+		b.WriteString(s.Qualify.String())
+	}
+	writeOrderLimit(&b, s.OrderBy, s.Limit)
+	return b.String()
+}
+
+// String renders the compound statement back as SQL
+func (c *CompoundStatement) String() string {
+	var b strings.Builder
+	for i, sel := range c.Selects {
+		if i > 0 {
+			b.WriteString(" " + c.Ops[i-1] + " ")
+		}
+		b.WriteString(sel.String())
+	}
+	writeOrderLimit(&b, c.OrderBy, c.Limit)
+	return b.String()
+}
+
+// writeOrderLimit renders the ORDER BY and LIMIT clauses
+func writeOrderLimit(b *strings.Builder, orderBy []OrderItem, limit int) {
+	if len(orderBy) > 0 {
+		b.WriteString(" ORDER BY ")
+		for i, item := range orderBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(item.Expr.String())
+			if item.Desc {
+				b.WriteString(" DESC")
+			}
+		}
+	}
+	if limit > 0 {
+		fmt.Fprintf(b, " LIMIT %d", limit)
+	}
+}
+
+// String renders the select item, including its alias
+func (s SelectItem) String() string {
+	if s.Alias != "" {
+		return s.Expr.String() + " AS " + s.Alias
+	}
+	return s.Expr.String()
+}
+
+// Name returns the output column name of the select item
+func (s SelectItem) Name() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	if col, ok := s.Expr.(*ColumnRef); ok {
+		return col.Name
+	}
+	return s.Expr.String()
+}
+
+func (c *ColumnRef) String() string {
+	name := c.Name
+	if c.Quoted || !isPlainIdentifier(name) {
+		name = `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	if c.Table != "" {
+		return c.Table + "." + name
+	}
+	return name
+}
+
+func (*StarExpr) String() string { return "*" }
+
+func (l *Literal) String() string {
+	switch l.Kind {
+	case StringLiteral:
+		return "'" + strings.ReplaceAll(l.Value, "'", "''") + "'"
+	case NullLiteral:
+		return "NULL"
+	default:
+		return l.Value
+	}
+}
+
+func (b *BinaryExpr) String() string {
+	return "(" + b.Left.String() + " " + b.Op + " " + b.Right.String() + ")"
+}
+
+func (u *UnaryExpr) String() string {
+	if u.Op == "NOT" {
+		return "NOT " + u.Expr.String()
+	}
+	return u.Op + u.Expr.String()
+}
+
+func (in *InExpr) String() string {
+	items := make([]string, len(in.List))
+	for i, item := range in.List {
+		items[i] = item.String()
+	}
+	op := " IN "
+	if in.Not {
+		op = " NOT IN "
+	}
+	if in.Subquery != nil {
+		return "(" + in.Expr.String() + op + "(" + in.Subquery.String() + "))"
+	}
+	return "(" + in.Expr.String() + op + "(" + strings.Join(items, ", ") + "))"
+}
+
+func (b *BetweenExpr) String() string {
+	op := " BETWEEN "
+	if b.Not {
+		op = " NOT BETWEEN "
+	}
+	return "(" + b.Expr.String() + op + b.Low.String() + " AND " + b.High.String() + ")"
+}
+
+func (l *LikeExpr) String() string {
+	op := " LIKE "
+	if l.Not {
+		op = " NOT LIKE "
+	}
+	return "(" + l.Expr.String() + op + l.Pattern.String() + ")"
+}
+
+func (c *CaseExpr) String() string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	if c.Operand != nil {
+		b.WriteString(" " + c.Operand.String())
+	}
+	for _, when := range c.Whens {
+		b.WriteString(" WHEN " + when.Cond.String() + " THEN " + when.Result.String())
+	}
+	if c.Else != nil {
+		b.WriteString(" ELSE " + c.Else.String())
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+func (c *CastExpr) String() string {
+	return "CAST(" + c.Expr.String() + " AS " + c.Type + ")"
+}
+
+func (f *FuncCall) String() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+	}
+	prefix := ""
+	if f.Distinct {
+		prefix = "DISTINCT "
+	}
+	call := f.Name + "(" + prefix + strings.Join(args, ", ") + ")"
+	if f.Over != nil {
+		call += " OVER (" + f.Over.String() + ")"
+	}
+	return call
+}
+
+// String renders the window specification inside the parentheses of OVER
+func (w *WindowSpec) String() string {
+	var b strings.Builder
+	if len(w.PartitionBy) > 0 {
+		b.WriteString("PARTITION BY ")
+		for i, expr := range w.PartitionBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(expr.String())
+		}
+	}
+	writeOrderLimit(&b, w.OrderBy, 0)
+	return strings.TrimPrefix(b.String(), " ")
+}
+
+// Walk calls fn for expr and every expression nested inside it
+func Walk(expr Expr, fn func(Expr)) {
+	if expr == nil {
+		return
+	}
+	fn(expr)
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *UnaryExpr:
+		Walk(e.Expr, fn)
+	case *InExpr:
+		Walk(e.Expr, fn)
+		for _, item := range e.List {
+			Walk(item, fn)
+		}
+	case *BetweenExpr:
+		Walk(e.Expr, fn)
+		Walk(e.Low, fn)
+		Walk(e.High, fn)
+	case *LikeExpr:
+		Walk(e.Expr, fn)
+		Walk(e.Pattern, fn)
+	case *CaseExpr:
+		Walk(e.Operand, fn)
+		for _, when := range e.Whens {
+			Walk(when.Cond, fn)
+			Walk(when.Result, fn)
+		}
+		Walk(e.Else, fn)
+	case *CastExpr:
+		Walk(e.Expr, fn)
+	case *FuncCall:
+		for _, arg := range e.Args {
+			Walk(arg, fn)
+		}
+		if e.Over != nil {
+			for _, expr := range e.Over.PartitionBy {
+				Walk(expr, fn)
+			}
+			for _, item := range e.Over.OrderBy {
+				Walk(item.Expr, fn)
+			}
+		}
+	}
+}
+
+// tableName renders a FROM name, quoting file paths that do not read back as dotted identifiers
+func tableName(name string) string {
+	for _, part := range strings.Split(name, ".") {
+		if !isPlainIdentifier(part) {
+			return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+		}
+	}
+	return name
+}
+
+// isPlainIdentifier reports whether name can be written without quotes
+func isPlainIdentifier(name string) bool {
+	if name == "" || IsKeyword(name) {
+		return false
+	}
+	for i, r := range name {
+		if !isIdentPart(r) || (i == 0 && !isIdentStart(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TokenType classifies lexer tokens
+type TokenType int
+
+const (
+	TokenEOF TokenType = iota
+	TokenIdent
+	TokenQuotedIdent
+	TokenNumber
+	TokenString
+	TokenSymbol
+)
+
+// Token is a single lexical unit of a query
+type Token struct {
+	Type  TokenType
+	Value string
+	Pos   int
+}
+
+// keywords are reserved words that cannot be used as bare identifiers
+var keywords = map[string]bool{
+	"SELECT": true, "DISTINCT": true, "FROM": true, "WHERE": true,
+	"GROUP": true, "HAVING": true, "QUALIFY": true, "ORDER": true, "BY": true, "ASC": true, "DESC": true, "LIMIT": true,
+	"AS": true, "AND": true, "OR": true, "NOT": true, "IN": true, "BETWEEN": true, "LIKE": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"NULL": true, "TRUE": true, "FALSE": true, "UNION": true, "INTERSECT": true, "EXCEPT": true, "ALL": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "OUTER": true, "ON": true,
+}
+
+// IsKeyword reports whether word is a reserved SQL keyword
+func IsKeyword(word string) bool {
+	return keywords[strings.ToUpper(word)]
+}
+
+// Tokenize splits a query into tokens
+func Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'':
+			// Single quoted string, '' escapes a quote
+			value, next, err := readQuoted(runes, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Type: TokenString, Value: value, Pos: i})
+			i = next
+
+		case r == '"' || r == '`':
+			value, next, err := readQuoted(runes, i, r)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Type: TokenQuotedIdent, Value: value, Pos: i})
+			i = next
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Exponent part, e.g. 1e6 or 2.5E-3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					i = j
+					for i < len(runes) && unicode.IsDigit(runes[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, Token{Type: TokenNumber, Value: string(runes[start:i]), Pos: start})
+
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{Type: TokenIdent, Value: string(runes[start:i]), Pos: start})
+
+		default:
+			// Two character operators first
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "<=", ">=", "!=", "<>", "==", "||":
+					tokens = append(tokens, Token{Type: TokenSymbol, Value: two, Pos: i})
+					i += 2
+					continue
+				}
+			}
+			if strings.ContainsRune("=<>+-*/%(),.;", r) {
+				tokens = append(tokens, Token{Type: TokenSymbol, Value: string(r), Pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, Token{Type: TokenEOF, Pos: len(runes)})
+	return tokens, nil
+}
+
+// readQuoted reads a quoted section starting at runes[start], where a doubled quote is an escaped quote
+func readQuoted(runes []rune, start int, quote rune) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				b.WriteRune(quote)
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		b.WriteRune(runes[i])
+	}
+	return "", 0, fmt.Errorf("unterminated quoted text starting at position %d", start)
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,797 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parser is a recursive descent parser over a token stream
+type Parser struct {
+	tokens []Token
+	pos    int
+}
+
+// Parse parses a single SQL statement
+func Parse(query string) (Statement, error) {
+	tokens, err := Tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &Parser{tokens: tokens}
+
+	var stmt Statement
+	switch {
+	case p.isKeyword("SELECT"):
+		stmt, err = p.parseCompound()
+	default:
+		return nil, p.errorf("expected SELECT")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Allow a trailing semicolon
+	p.acceptSymbol(";")
+	if p.peek().Type != TokenEOF {
+		return nil, p.errorf("unexpected %q after end of statement", p.peek().Value)
+	}
+	return stmt, nil
+}
+
+// ParseExpr parses a standalone expression such as a WHERE condition
+func ParseExpr(input string) (Expr, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &Parser{tokens: tokens}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Type != TokenEOF {
+		return nil, p.errorf("unexpected %q after end of expression", p.peek().Value)
+	}
+	return expr, nil
+}
+
+// parseCompound parses a SELECT, or several joined by UNION, INTERSECT or EXCEPT [ALL].
+// ORDER BY and LIMIT after the last SELECT apply to the combined result.
+func (p *Parser) parseCompound() (Statement, error) {
+	first, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isSetOperator() {
+		return first, nil
+	}
+
+	compound := &CompoundStatement{Selects: []*SelectStatement{first}}
+	for p.isSetOperator() {
+		tok := p.next()
+		last := compound.Selects[len(compound.Selects)-1]
+		if len(last.OrderBy) > 0 || last.Limit > 0 {
+			return nil, p.errorAt(tok, "ORDER BY and LIMIT must follow the last SELECT")
+		}
+		op := strings.ToUpper(tok.Value)
+		if p.acceptKeyword("ALL") {
+			op += " ALL"
+		}
+		if !p.isKeyword("SELECT") {
+			return nil, p.errorf("expected SELECT after %s", op)
+		}
+		next, err := p.parseSelect()
+		if err != nil {
+			return nil, err
+		}
+		compound.Ops = append(compound.Ops, op)
+		compound.Selects = append(compound.Selects, next)
+	}
+
+	last := compound.Selects[len(compound.Selects)-1]
+	compound.OrderBy, compound.Limit = last.OrderBy, last.Limit
+	last.OrderBy, last.Limit = nil, 0
+	return compound, nil
+}
+
+// parseSelect parses SELECT [DISTINCT] items [FROM table [JOIN table ON expr ...]] [WHERE expr] [GROUP BY ...] [HAVING expr] [QUALIFY expr] [ORDER BY ...] [LIMIT n]
+func (p *Parser) parseSelect() (*SelectStatement, error) {
+	p.next() // SELECT
+	stmt := &SelectStatement{}
+
+	if p.acceptKeyword("DISTINCT") {
+		stmt.Distinct = true
+	}
+
+	items, err := p.parseSelectItems()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Columns = items
+
+	if p.acceptKeyword("FROM") {
+		table, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+		stmt.From = table
+
+		joins, err := p.parseJoins()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Joins = joins
+	}
+
+	if p.acceptKeyword("WHERE") {
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = where
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if !p.acceptKeyword("BY") {
+			return nil, p.errorf("expected BY after GROUP")
+		}
+		for {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, expr)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("HAVING") {
+		having, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Having = having
+	}
+
+	if p.acceptKeyword("QUALIFY") {
+		qualify, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Qualify = qualify
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if !p.acceptKeyword("BY") {
+			return nil, p.errorf("expected BY after ORDER")
+		}
+		orderBy, err := p.parseOrderItems()
+		if err != nil {
+			return nil, err
+		}
+		stmt.OrderBy = orderBy
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		tok := p.next()
+		limit, err := strconv.Atoi(tok.Value)
+		if tok.Type != TokenNumber || err != nil || limit < 0 {
+			return nil, p.errorAt(tok, "LIMIT requires a non-negative integer")
+		}
+		stmt.Limit = limit
+	}
+
+	return stmt, nil
+}
+
+// parseJoins parses the [INNER | LEFT [OUTER]] JOIN table ON expr clauses after FROM
+func (p *Parser) parseJoins() ([]JoinClause, error) {
+	var joins []JoinClause
+	for {
+		join := JoinClause{Type: "INNER"}
+		switch {
+		case p.acceptKeyword("INNER"):
+			if !p.isKeyword("JOIN") {
+				return nil, p.errorf("expected JOIN after INNER")
+			}
+		case p.acceptKeyword("LEFT"):
+			join.Type = "LEFT"
+			p.acceptKeyword("OUTER")
+			if !p.isKeyword("JOIN") {
+				return nil, p.errorf("expected JOIN after LEFT")
+			}
+		case !p.isKeyword("JOIN"):
+			return joins, nil
+		}
+		p.next() // JOIN
+
+		table, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+		join.Table = table
+		if !p.acceptKeyword("ON") {
+			return nil, p.errorf("expected ON after JOIN %s", table.Name)
+		}
+		on, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		join.On = on
+		joins = append(joins, join)
+	}
+}
+
+// parseOrderItems parses the comma separated expr [ASC | DESC] list after ORDER BY
+func (p *Parser) parseOrderItems() ([]OrderItem, error) {
+	var items []OrderItem
+	for {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := OrderItem{Expr: expr}
+		if p.acceptKeyword("DESC") {
+			item.Desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		items = append(items, item)
+		if !p.acceptSymbol(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseSelectItems parses the comma separated select list
+func (p *Parser) parseSelectItems() ([]SelectItem, error) {
+	var items []SelectItem
+	for {
+		var item SelectItem
+		if p.acceptSymbol("*") {
+			item.Expr = &StarExpr{}
+		} else {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item.Expr = expr
+
+			// Optional alias, with or without AS
+			if p.acceptKeyword("AS") {
+				alias, err := p.parseIdentifier()
+				if err != nil {
+					return nil, err
+				}
+				item.Alias = alias
+			} else if tok := p.peek(); tok.Type == TokenQuotedIdent || (tok.Type == TokenIdent && !IsKeyword(tok.Value)) {
+				item.Alias = p.next().Value
+			}
+		}
+		items = append(items, item)
+
+		if !p.acceptSymbol(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseTableRef parses a table name (identifier or quoted file path) with an optional alias
+func (p *Parser) parseTableRef() (TableRef, error) {
+	var table TableRef
+	tok := p.next()
+	switch tok.Type {
+	case TokenIdent, TokenQuotedIdent, TokenString:
+		table.Name = tok.Value
+	default:
+		return table, p.errorAt(tok, "expected table name")
+	}
+
+	// Unquoted names may be file names such as data.csv
+	if tok.Type == TokenIdent {
+		for p.peek().Value == "." && p.peekAt(1).Type == TokenIdent {
+			p.next()
+			table.Name += "." + p.next().Value
+		}
+	}
+
+	if p.acceptKeyword("AS") {
+		alias, err := p.parseIdentifier()
+		if err != nil {
+			return table, err
+		}
+		table.Alias = alias
+	} else if tok := p.peek(); tok.Type == TokenIdent && !IsKeyword(tok.Value) {
+		table.Alias = p.next().Value
+	}
+	return table, nil
+}
+
+// parseIdentifier parses a bare or quoted identifier
+func (p *Parser) parseIdentifier() (string, error) {
+	tok := p.next()
+	if tok.Type == TokenQuotedIdent || (tok.Type == TokenIdent && !IsKeyword(tok.Value)) {
+		return tok.Value, nil
+	}
+	return "", p.errorAt(tok, "expected identifier")
+}
+
+// Expression grammar, lowest precedence first:
+//   OR, AND, NOT, comparison (including IN, BETWEEN and LIKE), + - ||, * / %, unary -, primary
+
+func (p *Parser) parseExpr() (Expr, error) {
+	return p.parseOr()
+}
+
+func (p *Parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseNot() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", Expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *Parser) parseComparison() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	// expr [NOT] IN (list), expr [NOT] BETWEEN low AND high and expr [NOT] LIKE pattern
+	if p.isKeyword("IN") || p.isNotFollowedBy("IN") {
+		return p.parseIn(left)
+	}
+	if p.isKeyword("BETWEEN") || p.isNotFollowedBy("BETWEEN") {
+		return p.parseBetween(left)
+	}
+	if p.isKeyword("LIKE") || p.isNotFollowedBy("LIKE") {
+		like := &LikeExpr{Expr: left, Not: p.acceptKeyword("NOT")}
+		p.next() // LIKE
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		like.Pattern = pattern
+		return like, nil
+	}
+
+	tok := p.peek()
+	if tok.Type == TokenSymbol {
+		switch tok.Value {
+		case "=", "==", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			op := tok.Value
+			switch op {
+			case "==":
+				op = "="
+			case "<>":
+				op = "!="
+			}
+			return &BinaryExpr{Op: op, Left: left, Right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parseIn parses the [NOT] IN (value, ...) or [NOT] IN (SELECT ...) suffix of a comparison
+func (p *Parser) parseIn(left Expr) (Expr, error) {
+	in := &InExpr{Expr: left, Not: p.acceptKeyword("NOT")}
+	p.next() // IN
+
+	if !p.acceptSymbol("(") {
+		return nil, p.errorf("expected ( after IN")
+	}
+	if p.isKeyword("SELECT") {
+		subquery, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		in.Subquery = subquery
+		if !p.acceptSymbol(")") {
+			return nil, p.errorf("expected ) to close subquery")
+		}
+		return in, nil
+	}
+	for {
+		item, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		in.List = append(in.List, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if !p.acceptSymbol(")") {
+		return nil, p.errorf("expected ) to close IN list")
+	}
+	return in, nil
+}
+
+// parseBetween parses the [NOT] BETWEEN low AND high suffix of a comparison
+func (p *Parser) parseBetween(left Expr) (Expr, error) {
+	between := &BetweenExpr{Expr: left, Not: p.acceptKeyword("NOT")}
+	p.next() // BETWEEN
+
+	low, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptKeyword("AND") {
+		return nil, p.errorf("expected AND in BETWEEN")
+	}
+	high, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	between.Low, between.High = low, high
+	return between, nil
+}
+
+func (p *Parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.Type != TokenSymbol || (tok.Value != "+" && tok.Value != "-" && tok.Value != "||") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: tok.Value, Left: left, Right: right}
+	}
+}
+
+func (p *Parser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.Type != TokenSymbol || (tok.Value != "*" && tok.Value != "/" && tok.Value != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: tok.Value, Left: left, Right: right}
+	}
+}
+
+func (p *Parser) parseUnary() (Expr, error) {
+	if p.acceptSymbol("-") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// Fold negative number literals
+		if lit, ok := expr.(*Literal); ok && lit.Kind == NumberLiteral {
+			return &Literal{Kind: NumberLiteral, Value: "-" + lit.Value}, nil
+		}
+		return &UnaryExpr{Op: "-", Expr: expr}, nil
+	}
+	if p.acceptSymbol("+") {
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *Parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.Type {
+	case TokenNumber:
+		return &Literal{Kind: NumberLiteral, Value: tok.Value}, nil
+
+	case TokenString:
+		return &Literal{Kind: StringLiteral, Value: tok.Value}, nil
+
+	case TokenQuotedIdent:
+		return p.parseColumnRef(tok.Value, true)
+
+	case TokenIdent:
+		upper := strings.ToUpper(tok.Value)
+		switch upper {
+		case "NULL":
+			return &Literal{Kind: NullLiteral, Value: "NULL"}, nil
+		case "TRUE", "FALSE":
+			return &Literal{Kind: BoolLiteral, Value: strings.ToLower(upper)}, nil
+		case "CASE":
+			return p.parseCase()
+		}
+		if IsKeyword(tok.Value) {
+			return nil, p.errorAt(tok, "unexpected keyword "+upper)
+		}
+		if p.peek().Value == "(" && p.peek().Type == TokenSymbol {
+			if upper == "CAST" {
+				return p.parseCast()
+			}
+			return p.parseFuncCall(upper)
+		}
+		return p.parseColumnRef(tok.Value, false)
+
+	case TokenSymbol:
+		if tok.Value == "(" {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.acceptSymbol(")") {
+				return nil, p.errorf("expected )")
+			}
+			return expr, nil
+		}
+	}
+
+	if tok.Type == TokenEOF {
+		return nil, p.errorAt(tok, "unexpected end of input")
+	}
+	return nil, p.errorAt(tok, fmt.Sprintf("unexpected %q", tok.Value))
+}
+
+// parseCase parses the rest of CASE [operand] WHEN cond THEN result ... [ELSE result] END
+func (p *Parser) parseCase() (Expr, error) {
+	caseExpr := &CaseExpr{}
+	if !p.isKeyword("WHEN") {
+		operand, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		caseExpr.Operand = operand
+	}
+
+	for p.acceptKeyword("WHEN") {
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("THEN") {
+			return nil, p.errorf("expected THEN")
+		}
+		result, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		caseExpr.Whens = append(caseExpr.Whens, WhenClause{Cond: cond, Result: result})
+	}
+	if len(caseExpr.Whens) == 0 {
+		return nil, p.errorf("expected WHEN after CASE")
+	}
+
+	if p.acceptKeyword("ELSE") {
+		elseExpr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		caseExpr.Else = elseExpr
+	}
+	if !p.acceptKeyword("END") {
+		return nil, p.errorf("expected END to close CASE")
+	}
+	return caseExpr, nil
+}
+
+// castTypes maps the type names accepted by CAST to their canonical form
+var castTypes = map[string]string{
+	"INT": "INT", "INTEGER": "INT", "BIGINT": "INT",
+	"FLOAT": "FLOAT", "REAL": "FLOAT", "DOUBLE": "FLOAT", "NUMERIC": "FLOAT", "DECIMAL": "FLOAT",
+	"STRING": "STRING", "TEXT": "STRING", "VARCHAR": "STRING",
+	"DATE": "DATE",
+	"BOOL": "BOOL", "BOOLEAN": "BOOL",
+}
+
+// parseCast parses CAST(expr AS type)
+func (p *Parser) parseCast() (Expr, error) {
+	p.next() // (
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptKeyword("AS") {
+		return nil, p.errorf("expected AS in CAST")
+	}
+	tok := p.next()
+	castType, ok := castTypes[strings.ToUpper(tok.Value)]
+	if tok.Type != TokenIdent || !ok {
+		return nil, p.errorAt(tok, "unsupported CAST type "+tok.Value+" (use INT, FLOAT, STRING, DATE or BOOL)")
+	}
+	if !p.acceptSymbol(")") {
+		return nil, p.errorf("expected ) to close CAST(")
+	}
+	return &CastExpr{Expr: expr, Type: castType}, nil
+}
+
+// parseColumnRef parses name or table.name
+func (p *Parser) parseColumnRef(name string, quoted bool) (Expr, error) {
+	if p.peek().Type == TokenSymbol && p.peek().Value == "." {
+		p.next()
+		tok := p.next()
+		if tok.Type != TokenIdent && tok.Type != TokenQuotedIdent {
+			return nil, p.errorAt(tok, "expected column name after .")
+		}
+		return &ColumnRef{Table: name, Name: tok.Value, Quoted: tok.Type == TokenQuotedIdent}, nil
+	}
+	return &ColumnRef{Name: name, Quoted: quoted}, nil
+}
+
+// parseFuncCall parses NAME( [DISTINCT] [* | expr, ...] ) [OVER (...)]
+func (p *Parser) parseFuncCall(name string) (Expr, error) {
+	p.next() // (
+	call := &FuncCall{Name: name}
+
+	if !p.acceptSymbol(")") {
+		if p.acceptKeyword("DISTINCT") {
+			call.Distinct = true
+		}
+		for {
+			if p.acceptSymbol("*") {
+				call.Args = append(call.Args, &StarExpr{})
+			} else {
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				call.Args = append(call.Args, arg)
+			}
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if !p.acceptSymbol(")") {
+			return nil, p.errorf("expected ) to close %s(", name)
+		}
+	}
+	if p.acceptKeyword("OVER") {
+		over, err := p.parseWindowSpec()
+		if err != nil {
+			return nil, err
+		}
+		call.Over = over
+	}
+	return call, nil
+}
+
+// parseWindowSpec parses the ( [PARTITION BY expr, ...] [ORDER BY expr [DESC], ...] ) after OVER
+func (p *Parser) parseWindowSpec() (*WindowSpec, error) {
+	if !p.acceptSymbol("(") {
+		return nil, p.errorf("expected ( after OVER")
+	}
+	spec := &WindowSpec{}
+	if p.acceptKeyword("PARTITION") {
+		if !p.acceptKeyword("BY") {
+			return nil, p.errorf("expected BY after PARTITION")
+		}
+		for {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			spec.PartitionBy = append(spec.PartitionBy, expr)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if !p.acceptKeyword("BY") {
+			return nil, p.errorf("expected BY after ORDER")
+		}
+		orderBy, err := p.parseOrderItems()
+		if err != nil {
+			return nil, err
+		}
+		spec.OrderBy = orderBy
+	}
+	if !p.acceptSymbol(")") {
+		return nil, p.errorf("expected ) to close OVER (")
+	}
+	return spec, nil
+}
+
+func (p *Parser) peek() Token {
+	return p.peekAt(0)
+}
+
+func (p *Parser) peekAt(offset int) Token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *Parser) next() Token {
+	tok := p.peek()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *Parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.Type == TokenIdent && strings.EqualFold(tok.Value, word)
+}
+
+// isSetOperator reports whether the next token is UNION, INTERSECT or EXCEPT
+func (p *Parser) isSetOperator() bool {
+	return p.isKeyword("UNION") || p.isKeyword("INTERSECT") || p.isKeyword("EXCEPT")
+}
+
+// isNotFollowedBy reports whether the next tokens are NOT followed by the keyword word
+func (p *Parser) isNotFollowedBy(word string) bool {
+	next := p.peekAt(1)
+	return p.isKeyword("NOT") && next.Type == TokenIdent && strings.EqualFold(next.Value, word)
+}
+
+func (p *Parser) acceptKeyword(word string) bool {
+	if p.isKeyword(word) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *Parser) acceptSymbol(symbol string) bool {
+	tok := p.peek()
+	if tok.Type == TokenSymbol && tok.Value == symbol {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *Parser) errorf(msg string, args ...interface{}) error {
+	return p.errorAt(p.peek(), fmt.Sprintf(msg, args...))
+}
+
+func (p *Parser) errorAt(tok Token, msg string) error {
+	return fmt.Errorf("syntax error at position %d: %s", tok.Pos, msg)
+}